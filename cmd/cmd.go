@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,7 +13,10 @@ import (
 	"github.com/carlmjohnson/versioninfo"
 	"github.com/charmbracelet/colorprofile"
 	"github.com/robinovitch61/kl/internal"
+	"github.com/robinovitch61/kl/internal/batching"
 	"github.com/robinovitch61/kl/internal/constants"
+	"github.com/robinovitch61/kl/internal/k8s/entity"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
 	"github.com/robinovitch61/kl/internal/model"
 	"github.com/robinovitch61/viewport/filterableviewport"
 	"github.com/spf13/cobra"
@@ -30,7 +34,7 @@ var (
 
 type arg struct {
 	cliShort, cfgFileEnvVar, description, defaultString string
-	isBool, isInt, defaultIfBool                        bool
+	isBool, isInt, isStringArray, defaultIfBool         bool
 	defaultIfInt                                        int
 }
 
@@ -43,6 +47,38 @@ var (
 			description:   `If present, view all namespaces. Overrides other specified namespaces`,
 			isBool:        true,
 		},
+		"app-timestamp-field": {
+			cfgFileEnvVar: "app-timestamp-field",
+			description:   `Order logs by the timestamp in this field of JSON logs, e.g. ts or meta.time, instead of when Kubernetes captured them. Default none`,
+		},
+		"app-timestamp-regex": {
+			cfgFileEnvVar: "app-timestamp-regex",
+			description:   `Order logs by the timestamp matched by this regex pattern's first capture group, instead of when Kubernetes captured them. Default none`,
+		},
+		"batch-interval-max": {
+			cfgFileEnvVar: "batch-interval-max",
+			description:   fmt.Sprintf(`Maximum interval between updates of the logs view. The interval grows toward this as updates get expensive under high log volume. Default %s`, constants.DefaultMaxBatchInterval),
+			defaultString: constants.DefaultMaxBatchInterval.String(),
+		},
+		"batch-interval-min": {
+			cfgFileEnvVar: "batch-interval-min",
+			description:   fmt.Sprintf(`Minimum interval between updates of the logs view. The interval shrinks toward this under low log volume. Set equal to --batch-interval-max for a fixed interval. Default %s`, constants.DefaultMinBatchInterval),
+			defaultString: constants.DefaultMinBatchInterval.String(),
+		},
+		"burst": {
+			cfgFileEnvVar: "burst",
+			description:   `Maximum burst of requests to the Kubernetes API. Default client-go default (10)`,
+			isInt:         true,
+		},
+		"columns": {
+			cfgFileEnvVar: "columns",
+			description:   `Show these comma-separated fields of JSON and logfmt logs as aligned columns, e.g. level,msg,http.status. Default none`,
+		},
+		"columns-tsv": {
+			cfgFileEnvVar: "columns-tsv",
+			description:   `If present, save logs shown as columns to file as tab-separated values. Default false`,
+			isBool:        true,
+		},
 		"context": {
 			cfgFileEnvVar: "context",
 			description:   `Context(s). Can be a comma-separated list. Defaults to current context`,
@@ -56,6 +92,15 @@ var (
 		"help": {
 			description: `Print usage`,
 		},
+		"disk-store": {
+			cfgFileEnvVar: "disk-store",
+			description:   `Store logs in append-only files in a session directory created in this directory, keeping only the most recent in memory and loading older logs back as you scroll or jump to them. Saving includes the logs on disk. Removed on exit. Default disabled`,
+		},
+		"highlight": {
+			cfgFileEnvVar: "highlight",
+			description:   `Highlight this string or /regex/ pattern in logs. Repeat to highlight several, each in its own color, e.g. --highlight timeout --highlight '/trace_id=\w+/'. Default none`,
+			isStringArray: true,
+		},
 		"ic": {
 			cfgFileEnvVar: "ignore-container",
 			description:   `Ignore containers matching this regex pattern`,
@@ -86,15 +131,24 @@ var (
 		},
 		"limit": {
 			cfgFileEnvVar: "limit",
-			description:   `Limit the number of selected containers. Default unlimited`,
+			description:   `Limit the number of selected containers. Further selections are queued until a slot frees up. Default unlimited`,
 			isInt:         true,
 			defaultIfInt:  -1,
 		},
+		"limit-priority": {
+			cfgFileEnvVar: "limit-priority",
+			description:   `Order in which queued selections start when --limit is reached. Defaults to selection tree order. Other options: 'recent' (running before terminated, most recently started first)`,
+		},
 		"logs-view": {
 			cfgFileEnvVar: "logs-view",
 			description:   `If present, start with logs view. Default false (selection page)`,
 			isBool:        true,
 		},
+		"log-exclude": {
+			cfgFileEnvVar: "log-exclude",
+			description:   `Hide logs matching this string or /regex/ pattern on startup. Repeat to hide several, e.g. --log-exclude kube-probe --log-exclude '/GET /(healthz|readyz)/'. Default none`,
+			isStringArray: true,
+		},
 		"log-filter": {
 			cliShort:      "f",
 			cfgFileEnvVar: "log-filter",
@@ -113,6 +167,21 @@ var (
 			cfgFileEnvVar: "match-cluster",
 			description:   `Auto-select clusters matching this regex pattern`,
 		},
+		"memory-lines": {
+			cfgFileEnvVar: "memory-lines",
+			description:   fmt.Sprintf(`With --disk-store, the number of most recent logs kept in memory. Default %d`, constants.DefaultMemoryLines),
+			isInt:         true,
+			defaultIfInt:  constants.DefaultMemoryLines,
+		},
+		"multiline": {
+			cfgFileEnvVar: "multiline",
+			description:   `If present, join multi-line records like Java, Python and Go stack traces into single logs. Default false`,
+			isBool:        true,
+		},
+		"multiline-start": {
+			cfgFileEnvVar: "multiline-start",
+			description:   `Join lines into multi-line records, starting a new record at each line matching this regex pattern. Default none`,
+		},
 		"mns": {
 			cfgFileEnvVar: "match-namespace",
 			description:   `Auto-select namespaces matching this regex pattern`,
@@ -130,6 +199,44 @@ var (
 			cfgFileEnvVar: "namespace",
 			description:   `Namespace(s). Can be comma-separated list. Defaults to current namespace`,
 		},
+		"qps": {
+			cfgFileEnvVar: "qps",
+			description:   `Maximum queries per second to the Kubernetes API, e.g. 0.5 or 50. Default client-go default (5)`,
+		},
+		"rate-limit": {
+			cfgFileEnvVar: "rate-limit",
+			description:   `Maximum log lines per second kept from each container, marking where lines were suppressed. Default unlimited`,
+			isInt:         true,
+		},
+		"retain-age": {
+			cfgFileEnvVar: "retain-age",
+			description:   `Drop logs older than this duration. E.g. 30m, 2h. Default unlimited`,
+		},
+		"retain-bytes": {
+			cfgFileEnvVar: "retain-bytes",
+			description:   `Maximum total bytes of log content kept, dropping the oldest logs. Default unlimited`,
+			isInt:         true,
+		},
+		"retain-container-bytes": {
+			cfgFileEnvVar: "retain-container-bytes",
+			description:   `Maximum bytes of log content kept per container, dropping the oldest logs. Default unlimited`,
+			isInt:         true,
+		},
+		"retain-container-lines": {
+			cfgFileEnvVar: "retain-container-lines",
+			description:   `Maximum number of log lines kept per container, dropping the oldest logs. Default unlimited`,
+			isInt:         true,
+		},
+		"retain-lines": {
+			cfgFileEnvVar: "retain-lines",
+			description:   `Maximum number of log lines kept, dropping the oldest logs. Default unlimited`,
+			isInt:         true,
+		},
+		"sample": {
+			cfgFileEnvVar: "sample",
+			description:   `Keep only 1 in every N log lines from each container, marking where lines were suppressed. Default 1 (keep all)`,
+			isInt:         true,
+		},
 		"selector": {
 			cliShort:      "l",
 			cfgFileEnvVar: "selector",
@@ -139,6 +246,12 @@ var (
 			cfgFileEnvVar: "since",
 			description:   `Show logs since startup time minus this duration. E.g. 5s, 2m, 1.5h, 2h45m. Default 1m`,
 		},
+		"start-concurrency": {
+			cfgFileEnvVar: "start-concurrency",
+			description:   fmt.Sprintf(`Maximum number of log streams starting at once, 0 for unlimited. Default %d`, constants.DefaultStartConcurrency),
+			isInt:         true,
+			defaultIfInt:  constants.DefaultStartConcurrency,
+		},
 		"theme": {
 			cfgFileEnvVar: "theme",
 			description:   `Color theme. Defaults to accessible ansi colors. Other options: 'classic', 'none'`,
@@ -179,8 +292,17 @@ func init() {
 
 	for _, cliLong = range []string{
 		"all-namespaces",
+		"app-timestamp-field",
+		"app-timestamp-regex",
+		"batch-interval-max",
+		"batch-interval-min",
+		"burst",
+		"columns",
+		"columns-tsv",
 		"context",
 		"desc",
+		"disk-store",
+		"highlight",
 		"ic",
 		"iclust",
 		"ignore-owner-types",
@@ -189,33 +311,54 @@ func init() {
 		"ipod",
 		"kubeconfig",
 		"limit",
+		"limit-priority",
 		"logs-view",
+		"log-exclude",
 		"log-filter",
 		"log-regex",
 		"mc",
 		"mclust",
+		"memory-lines",
 		"mns",
 		"mown",
 		"mpod",
+		"multiline",
+		"multiline-start",
 		"namespace",
+		"qps",
+		"rate-limit",
+		"retain-age",
+		"retain-bytes",
+		"retain-container-bytes",
+		"retain-container-lines",
+		"retain-lines",
+		"sample",
 		"selector",
 		"since",
+		"start-concurrency",
 		"theme",
 	} {
 		c := rootNameToArg[cliLong]
-		if c.isBool {
-			rootCmd.PersistentFlags().BoolP(cliLong, c.cliShort, c.defaultIfBool, c.description)
-		} else if c.isInt {
-			rootCmd.PersistentFlags().IntP(cliLong, c.cliShort, c.defaultIfInt, c.description)
-		} else {
-			rootCmd.PersistentFlags().StringP(cliLong, c.cliShort, c.defaultString, c.description)
-		}
+		addFlag(rootCmd.PersistentFlags(), cliLong, c)
 		_ = viper.BindPFlag(cliLong, rootCmd.PersistentFlags().Lookup(c.cfgFileEnvVar))
 	}
 	rootCmd.SetVersionTemplate(`{{printf "kl %s\n" .Version}}`)
 	rootCmd.Flags().BoolP("version", "v", false, "Show kl version")
 }
 
+// addFlag adds the flag for an arg of the given type to flags
+func addFlag(flags *pflag.FlagSet, cliLong string, c arg) {
+	if c.isBool {
+		flags.BoolP(cliLong, c.cliShort, c.defaultIfBool, c.description)
+	} else if c.isInt {
+		flags.IntP(cliLong, c.cliShort, c.defaultIfInt, c.description)
+	} else if c.isStringArray {
+		flags.StringArrayP(cliLong, c.cliShort, nil, c.description)
+	} else {
+		flags.StringP(cliLong, c.cliShort, c.defaultString, c.description)
+	}
+}
+
 func initConfig(cmd *cobra.Command, nameToArg map[string]arg) error {
 	// bind viper to env vars
 	viper.AutomaticEnv()
@@ -234,16 +377,35 @@ func bindFlags(cmd *cobra.Command, nameToArg map[string]arg) {
 		// Apply the viper config value to the flag when the flag is not manually specified
 		// and viper has a value from the config file or env var
 		if !f.Changed && v.IsSet(viperName) {
-			val := v.Get(viperName)
-			err := cmd.Flags().Set(cliLong, fmt.Sprintf("%v", val))
-			if err != nil {
-				fmt.Printf("error setting flag %s: %v\n", cliLong, err)
-				os.Exit(1)
+			for _, val := range configValues(v.Get(viperName), nameToArg[cliLong].isStringArray) {
+				err := cmd.Flags().Set(cliLong, fmt.Sprintf("%v", val))
+				if err != nil {
+					fmt.Printf("error setting flag %s: %v\n", cliLong, err)
+					os.Exit(1)
+				}
 			}
 		}
 	})
 }
 
+// configValues returns the values to set a flag to from a config file or env var value. Each item of a list sets a
+// string array flag once, as a repeated flag would
+func configValues(val interface{}, isStringArray bool) []interface{} {
+	if isStringArray {
+		switch list := val.(type) {
+		case []interface{}:
+			return list
+		case []string:
+			values := make([]interface{}, len(list))
+			for i, s := range list {
+				values[i] = s
+			}
+			return values
+		}
+	}
+	return []interface{}{val}
+}
+
 func mainEntrypoint(cmd *cobra.Command, _ []string) {
 	initialModel := setup(cmd)
 
@@ -281,6 +443,49 @@ func getAllNamespaces(cmd *cobra.Command) bool {
 	return cmd.Flags().Lookup("all-namespaces").Value.String() == "true"
 }
 
+func getAppTimestamp(cmd *cobra.Command) k8s_log.TimestampRule {
+	field := cmd.Flags().Lookup("app-timestamp-field").Value.String()
+	regex := cmd.Flags().Lookup("app-timestamp-regex").Value.String()
+	if field != "" && regex != "" {
+		fmt.Println("error: cannot specify both app-timestamp-field and app-timestamp-regex")
+		os.Exit(1)
+	}
+	rule := k8s_log.TimestampRule{JSONField: field}
+	if regex != "" {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			fmt.Printf("error compiling app-timestamp-regex: %v\n", err)
+			os.Exit(1)
+		}
+		rule.Pattern = re
+	}
+	return rule
+}
+
+func getBatching(cmd *cobra.Command) batching.Config {
+	config := batching.Config{
+		Min: getDuration(cmd, "batch-interval-min"),
+		Max: getDuration(cmd, "batch-interval-max"),
+	}
+	if err := config.Validate(); err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+	return config
+}
+
+func getBurst(cmd *cobra.Command) int {
+	return getNonNegativeInt(cmd, "burst")
+}
+
+func getColumns(cmd *cobra.Command) []string {
+	return model.ParseColumns(cmd.Flags().Lookup("columns").Value.String())
+}
+
+func getColumnsTSV(cmd *cobra.Command) bool {
+	return cmd.Flags().Lookup("columns-tsv").Value.String() == "true"
+}
+
 func getContainerLimit(cmd *cobra.Command) int {
 	// -1 indicates no limit
 	if !cmd.Flags().Lookup("limit").Changed {
@@ -298,6 +503,15 @@ func getContainerLimit(cmd *cobra.Command) int {
 	return limit
 }
 
+func getLimitPriority(cmd *cobra.Command) entity.QueuePriority {
+	priority, err := entity.ParseQueuePriority(cmd.Flags().Lookup("limit-priority").Value.String())
+	if err != nil {
+		fmt.Printf("error parsing limit-priority: %v\n", err)
+		os.Exit(1)
+	}
+	return priority
+}
+
 func getKubeConfigPath(cmd *cobra.Command) string {
 	kubeconfig := cmd.Flags().Lookup("kubeconfig").Value.String()
 	if kubeconfig != "" {
@@ -320,6 +534,20 @@ func getDescending(cmd *cobra.Command) bool {
 	return cmd.Flags().Lookup("desc").Value.String() == "true"
 }
 
+func getDiskStoreDir(cmd *cobra.Command) string {
+	return cmd.Flags().Lookup("disk-store").Value.String()
+}
+
+func getHighlights(cmd *cobra.Command) []model.HighlightRule {
+	texts, _ := cmd.Flags().GetStringArray("highlight")
+	rules, err := model.ParseHighlightRules(texts)
+	if err != nil {
+		fmt.Printf("error parsing highlight: %v\n", err)
+		os.Exit(1)
+	}
+	return rules
+}
+
 func getIgnoreOwnerTypes(cmd *cobra.Command) []string {
 	types := strings.Split(cmd.Flags().Lookup("ignore-owner-types").Value.String(), ",")
 	if len(types) == 0 || (len(types) == 1 && types[0] == "") {
@@ -356,8 +584,9 @@ func getLogFilter(cmd *cobra.Command) model.LogFilter {
 		fmt.Println("error: cannot specify both log-filter and log-regex")
 		os.Exit(1)
 	}
+	var logFilter model.LogFilter
 	if filter != "" {
-		return model.LogFilter{
+		logFilter = model.LogFilter{
 			Value: filter,
 			Mode:  filterableviewport.FilterExact,
 		}
@@ -368,12 +597,47 @@ func getLogFilter(cmd *cobra.Command) model.LogFilter {
 			fmt.Printf("error compiling log regex: %v\n", err)
 			os.Exit(1)
 		}
-		return model.LogFilter{
+		logFilter = model.LogFilter{
 			Value: regex,
 			Mode:  filterableviewport.FilterRegex,
 		}
 	}
-	return model.LogFilter{}
+	logFilter.Excludes = getLogExcludes(cmd)
+	return logFilter
+}
+
+func getLogExcludes(cmd *cobra.Command) []model.LogFilter {
+	var excludes []model.LogFilter
+	texts, _ := cmd.Flags().GetStringArray("log-exclude")
+	for _, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		exclude, err := model.ParseLogExclusion(text)
+		if err != nil {
+			fmt.Printf("error parsing log exclusion: %v\n", err)
+			os.Exit(1)
+		}
+		excludes = append(excludes, exclude)
+	}
+	return excludes
+}
+
+func getMemoryLines(cmd *cobra.Command) int {
+	return getNonNegativeInt(cmd, "memory-lines")
+}
+
+func getMultiline(cmd *cobra.Command) k8s_log.MultilineRules {
+	rules := k8s_log.MultilineRules{Enabled: cmd.Flags().Lookup("multiline").Value.String() == "true"}
+	if start := cmd.Flags().Lookup("multiline-start").Value.String(); start != "" {
+		re, err := regexp.Compile(start)
+		if err != nil {
+			fmt.Printf("error compiling multiline-start regex: %v\n", err)
+			os.Exit(1)
+		}
+		rules.StartPattern = re
+	}
+	return rules
 }
 
 func getNamespaces(cmd *cobra.Command) []string {
@@ -386,6 +650,45 @@ func getNamespaces(cmd *cobra.Command) []string {
 	return namespaces
 }
 
+func getDuration(cmd *cobra.Command, name string) time.Duration {
+	d, err := time.ParseDuration(cmd.Flags().Lookup(name).Value.String())
+	if err != nil {
+		fmt.Printf("error parsing %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	return d
+}
+
+func getNonNegativeInt(cmd *cobra.Command, name string) int {
+	v, err := cmd.Flags().GetInt(name)
+	if err != nil {
+		fmt.Printf("error parsing %s: %v\n", name, err)
+		os.Exit(1)
+	}
+	if v < 0 {
+		fmt.Printf("error: %s must be non-negative\n", name)
+		os.Exit(1)
+	}
+	return v
+}
+
+func getQPS(cmd *cobra.Command) float32 {
+	s := cmd.Flags().Lookup("qps").Value.String()
+	if s == "" {
+		return 0
+	}
+	qps, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		fmt.Printf("error parsing qps: %v\n", err)
+		os.Exit(1)
+	}
+	if qps < 0 {
+		fmt.Println("error: qps must be non-negative")
+		os.Exit(1)
+	}
+	return float32(qps)
+}
+
 func getSelector(cmd *cobra.Command) labels.Selector {
 	selector, err := labels.Parse(cmd.Flags().Lookup("selector").Value.String())
 	if err != nil {
@@ -395,6 +698,42 @@ func getSelector(cmd *cobra.Command) labels.Selector {
 	return selector
 }
 
+func getRetention(cmd *cobra.Command) model.RetentionPolicy {
+	var maxAge time.Duration
+	if age := cmd.Flags().Lookup("retain-age").Value.String(); age != "" {
+		d, err := time.ParseDuration(age)
+		if err != nil {
+			fmt.Printf("error parsing retain-age: %v\n", err)
+			os.Exit(1)
+		}
+		if d < 0 {
+			fmt.Println("error: retain-age must be non-negative")
+			os.Exit(1)
+		}
+		maxAge = d
+	}
+	retention := model.RetentionPolicy{
+		MaxLines:             getNonNegativeInt(cmd, "retain-lines"),
+		MaxBytes:             getNonNegativeInt(cmd, "retain-bytes"),
+		MaxAge:               maxAge,
+		MaxLinesPerContainer: getNonNegativeInt(cmd, "retain-container-lines"),
+		MaxBytesPerContainer: getNonNegativeInt(cmd, "retain-container-bytes"),
+	}
+	// logs on disk are dropped in the order they were added, so per-container limits can't be kept
+	if getDiskStoreDir(cmd) != "" && (retention.MaxLinesPerContainer > 0 || retention.MaxBytesPerContainer > 0) {
+		fmt.Println("error: cannot specify retain-container-lines or retain-container-bytes with disk-store")
+		os.Exit(1)
+	}
+	return retention
+}
+
+func getSampling(cmd *cobra.Command) model.SamplingPolicy {
+	return model.SamplingPolicy{
+		MaxLinesPerSecond: getNonNegativeInt(cmd, "rate-limit"),
+		KeepOneIn:         getNonNegativeInt(cmd, "sample"),
+	}
+}
+
 func getSince(cmd *cobra.Command) model.SinceTime {
 	duration := cmd.Flags().Lookup("since").Value.String()
 	if duration == "" {
@@ -417,6 +756,10 @@ func getSince(cmd *cobra.Command) model.SinceTime {
 	return model.NewSinceTime(t, int(d.Minutes()))
 }
 
+func getStartConcurrency(cmd *cobra.Command) int {
+	return getNonNegativeInt(cmd, "start-concurrency")
+}
+
 func getThemeName(cmd *cobra.Command) string {
 	theme := cmd.Flags().Lookup("theme").Value.String()
 	if theme != "" && theme != "classic" && theme != "none" {
@@ -446,22 +789,36 @@ func getAutoSelectMatchers(cmd *cobra.Command) model.Matcher {
 func getConfig(cmd *cobra.Command) internal.Config {
 	return internal.Config{
 		AllNamespaces:    getAllNamespaces(cmd),
+		AppTimestamp:     getAppTimestamp(cmd),
+		Batching:         getBatching(cmd),
+		Burst:            getBurst(cmd),
+		Columns:          getColumns(cmd),
+		ColumnsTSV:       getColumnsTSV(cmd),
 		ContainerLimit:   getContainerLimit(cmd),
 		Contexts:         getKubeContexts(cmd),
 		Descending:       getDescending(cmd),
+		DiskStoreDir:     getDiskStoreDir(cmd),
+		Highlights:       getHighlights(cmd),
 		IgnoreOwnerTypes: getIgnoreOwnerTypes(cmd),
 		KubeConfigPath:   getKubeConfigPath(cmd),
+		LimitPriority:    getLimitPriority(cmd),
 		LogsView:         getLogsView(cmd),
 		LogFilter:        getLogFilter(cmd),
 		Matchers: model.Matchers{
 			AutoSelectMatcher: getAutoSelectMatchers(cmd),
 			IgnoreMatcher:     getIgnoreMatchers(cmd),
 		},
-		Namespaces: getNamespaces(cmd),
-		Selector:   getSelector(cmd),
-		SinceTime:  getSince(cmd),
-		ThemeName:  getThemeName(cmd),
-		Version:    getVersion(),
+		MemoryLines:      getMemoryLines(cmd),
+		Multiline:        getMultiline(cmd),
+		Namespaces:       getNamespaces(cmd),
+		QPS:              getQPS(cmd),
+		Retention:        getRetention(cmd),
+		Sampling:         getSampling(cmd),
+		Selector:         getSelector(cmd),
+		SinceTime:        getSince(cmd),
+		StartConcurrency: getStartConcurrency(cmd),
+		ThemeName:        getThemeName(cmd),
+		Version:          getVersion(),
 	}
 }
 
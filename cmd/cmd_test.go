@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/robinovitch61/viewport/filterableviewport"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newLogExcludeCommand() *cobra.Command {
+	cmd := &cobra.Command{}
+	addFlag(cmd.Flags(), "log-exclude", rootNameToArg["log-exclude"])
+	return cmd
+}
+
+func TestGetLogExcludes_RepeatedFlag(t *testing.T) {
+	cmd := newLogExcludeCommand()
+	if err := cmd.ParseFlags([]string{"--log-exclude", "/a{1,3}/", "--log-exclude", "kube-probe, v1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	excludes := getLogExcludes(cmd)
+	if len(excludes) != 2 {
+		t.Fatalf("expected 2 exclusions, got %v", excludes)
+	}
+	if excludes[0].Value != "a{1,3}" || excludes[0].Mode != filterableviewport.FilterRegex {
+		t.Errorf("expected regex with a comma to be kept whole, got %v", excludes[0])
+	}
+	if excludes[1].Value != "kube-probe, v1" || excludes[1].Mode != filterableviewport.FilterExact {
+		t.Errorf("expected text with a comma to be kept whole, got %v", excludes[1])
+	}
+}
+
+func TestGetLogExcludes_ConfigList(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("log-exclude", []interface{}{"/a{1,3}/", "kube-probe"})
+	cmd := newLogExcludeCommand()
+	bindFlags(cmd, rootNameToArg)
+	excludes := getLogExcludes(cmd)
+	if len(excludes) != 2 || excludes[0].Value != "a{1,3}" || excludes[1].Value != "kube-probe" {
+		t.Errorf("expected an exclusion per config list item, got %v", excludes)
+	}
+}
+
+func TestGetHighlights_RepeatedFlag(t *testing.T) {
+	cmd := &cobra.Command{}
+	addFlag(cmd.Flags(), "highlight", rootNameToArg["highlight"])
+	if err := cmd.ParseFlags([]string{"--highlight", "/a{1,3}/", "--highlight", "timeout"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rules := getHighlights(cmd)
+	if len(rules) != 2 || rules[0].Text != "/a{1,3}/" || rules[1].Text != "timeout" {
+		t.Errorf("expected regex with a comma to be kept whole, got %v", rules)
+	}
+}
+
+func TestGetQPS_Fractional(t *testing.T) {
+	cmd := &cobra.Command{}
+	addFlag(cmd.Flags(), "qps", rootNameToArg["qps"])
+	if got := getQPS(cmd); got != 0 {
+		t.Errorf("expected unset qps to be 0, got %v", got)
+	}
+	if err := cmd.ParseFlags([]string{"--qps", "0.5"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := getQPS(cmd); got != 0.5 {
+		t.Errorf("expected qps 0.5, got %v", got)
+	}
+}
@@ -17,19 +17,23 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/muesli/reflow/wrap"
+	"github.com/robinovitch61/kl/internal/batching"
 	"github.com/robinovitch61/kl/internal/command"
 	"github.com/robinovitch61/kl/internal/constants"
 	"github.com/robinovitch61/kl/internal/dev"
 	"github.com/robinovitch61/kl/internal/fileio"
 	"github.com/robinovitch61/kl/internal/k8s/client"
 	"github.com/robinovitch61/kl/internal/keymap"
+	"github.com/robinovitch61/kl/internal/logstore"
 	"github.com/robinovitch61/kl/internal/message"
 	"github.com/robinovitch61/kl/internal/model"
 	"github.com/robinovitch61/kl/internal/page"
 	"github.com/robinovitch61/kl/internal/prompt"
+	"github.com/robinovitch61/kl/internal/stats"
 	"github.com/robinovitch61/kl/internal/style"
 	"github.com/robinovitch61/kl/internal/toast"
 	"github.com/robinovitch61/kl/internal/util"
+	"github.com/robinovitch61/viewport/filterableviewport"
 )
 
 type data struct {
@@ -80,13 +84,55 @@ type Model struct {
 	// containerListeners contains a container update listener for each cluster and namespace combo
 	containerListeners []client.ContainerListener
 
+	// numScannersStarting is the number of dispatched log scanner starts that haven't reported back yet
+	numScannersStarting int
+	// queuedScannerStarts are log scanner starts waiting for a free slot under config.StartConcurrency
+	queuedScannerStarts []queuedScannerStart
+	// pendingScannerStarts are the queued or in-flight log scanner starts by container ID
+	pendingScannerStarts map[string]pendingScannerStart
+	// nextScannerStartID is the ID given to the next log scanner start
+	nextScannerStartID int
+
+	// diskStore stores the logs, only the most recent being kept in memory, if configured, nil otherwise
+	diskStore *logstore.DiskStore
+
+	// stats collects throughput statistics for the stats overlay
+	stats *stats.Stats
+
+	// batchIntervals adapt how often logs and container changes are batched to the cost of applying them
+	batchIntervals *batching.Intervals
+
+	// sampler limits the logs kept from each container before they are buffered
+	sampler *model.LogSampler
+	// replays skips the logs restarted log scanners replay
+	replays *model.ReplayFilter
+
+	cancel context.CancelFunc
+}
+
+// queuedScannerStart is a log scanner start that is waiting for other starts to complete
+type queuedScannerStart struct {
+	startID   int
+	container container.Container
+	sinceTime time.Time
+}
+
+// pendingScannerStart is a log scanner start that can be cancelled until its result is handled
+type pendingScannerStart struct {
+	id     int
+	ctx    context.Context
 	cancel context.CancelFunc
 }
 
 func InitialModel(c Config) Model {
 	return Model{
-		config: c,
-		keyMap: keymap.DefaultKeyMap(),
+		config:               c,
+		keyMap:               keymap.DefaultKeyMap(),
+		pendingScannerStarts: make(map[string]pendingScannerStart),
+		stats:                stats.New(),
+		batchIntervals:       batching.New(c.Batching),
+		sampler:              model.NewLogSampler(c.Sampling),
+		replays:              model.NewReplayFilter(),
 	}
 }
 
@@ -98,7 +144,7 @@ func (m Model) setErr(err error) Model {
 
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
-		tea.Tick(constants.BatchUpdateLogsInterval, func(t time.Time) tea.Msg { return message.BatchUpdateLogsMsg{} }),
+		tea.Tick(m.batchIntervals.BatchUpdateLogs(), func(t time.Time) tea.Msg { return message.BatchUpdateLogsMsg{} }),
 	)
 }
 
@@ -148,11 +194,6 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		return m.handleKeyMsg(msg)
 
-	case message.AttemptUpdateSinceTimeMsg:
-		m, cmd = m.attemptUpdateSinceTime()
-		cmds = append(cmds, cmd)
-		return m, tea.Batch(cmds...)
-
 	case command.GetContainerListenerMsg:
 		m, cmd = m.handleContainerListenerMsg(msg)
 		cmds = append(cmds, cmd)
@@ -184,11 +225,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 
 	case message.BatchUpdateLogsMsg:
+		m.stats.RecordBufferSize(len(m.pageLogBuffer))
+		var cost time.Duration
 		if len(m.pageLogBuffer) > 0 && !m.state.pauseState {
+			start := time.Now()
 			m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithAppendedLogs(m.pageLogBuffer)
+			cost = time.Since(start)
+			m.stats.RecordBatch(len(m.pageLogBuffer), cost)
 			m.pageLogBuffer = nil
+		} else if !m.state.pauseState {
+			m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithExpiredLogsDropped(time.Now())
 		}
-		return m, tea.Tick(constants.BatchUpdateLogsInterval, func(t time.Time) tea.Msg { return message.BatchUpdateLogsMsg{} })
+		m.batchIntervals.Observe(cost)
+		m.stats.RecordBatchInterval(m.batchIntervals.BatchUpdateLogs())
+		return m, tea.Tick(m.batchIntervals.BatchUpdateLogs(), func(t time.Time) tea.Msg { return message.BatchUpdateLogsMsg{} })
 
 	case command.StoppedLogScannersMsg:
 		m, cmd = m.handleStoppedLogScannersMsg(msg)
@@ -230,6 +280,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.components.toast, cmd = m.components.toast.Update(msg)
 		cmds = append(cmds, cmd)
 		return m, tea.Batch(cmds...)
+
+	case stats.SampleMsg:
+		return m, m.stats.Update(msg, time.Now())
 	}
 
 	if m.pages[m.state.focusedPageType] == nil {
@@ -241,6 +294,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() tea.View {
+	start := time.Now()
+	defer func() { m.stats.RecordRender(time.Since(start)) }()
+
 	var content string
 
 	if m.state.err != nil {
@@ -281,6 +337,17 @@ func (m Model) View() tea.View {
 		}
 
 		viewLines = append(viewLines, strings.Split(pageView, "\n")...)
+		if statsView := m.stats.View(m.state.width, m.data.theme.TopBar); statsView != "" {
+			// overlay the stats at the bottom of the pages, above any toast
+			statsLines := strings.Split(statsView, "\n")
+			bottom := len(viewLines)
+			if m.components.toast.Visible {
+				bottom -= m.components.toast.ViewHeight()
+			}
+			if top := max(1, bottom-len(statsLines)); top < bottom {
+				copy(viewLines[top:bottom], statsLines[:bottom-top])
+			}
+		}
 		if toastHeight := m.components.toast.ViewHeight(); m.components.toast.Visible && toastHeight > 0 {
 			viewLines = viewLines[:len(viewLines)-toastHeight]
 			viewLines = append(viewLines, strings.Split(m.components.toast.View(), "\n")...)
@@ -360,6 +427,7 @@ func (m Model) syncDimensions() Model {
 	m.pages[page.EntitiesPageType] = m.pages[page.EntitiesPageType].WithDimensions(leftWidth, contentHeight)
 	m.pages[page.LogsPageType] = m.pages[page.LogsPageType].WithDimensions(rightWidth, contentHeight)
 	m.pages[page.SingleLogPageType] = m.pages[page.SingleLogPageType].WithDimensions(rightWidth, contentHeight)
+	m.pages[page.PatternsPageType] = m.pages[page.PatternsPageType].WithDimensions(rightWidth, contentHeight)
 	return m
 }
 
@@ -373,15 +441,15 @@ func (m Model) changeFocusedPage(newPage page.Type) Model {
 		// re-enable stickiness on logs page
 		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithStickyness()
 		m.state.focusedPageType = page.LogsPageType
-	case page.SingleLogPageType:
-		// cancel stickiness on logs page when moving to single log page
+	case page.SingleLogPageType, page.PatternsPageType:
+		// cancel stickiness on logs page when moving to single log or patterns page
 		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithNoStickyness()
 
 		if m.state.focusedPageType != page.LogsPageType {
 			m.pages[m.state.focusedPageType] = m.pages[m.state.focusedPageType].WithBlur()
 		}
 
-		m.state.focusedPageType = page.SingleLogPageType
+		m.state.focusedPageType = newPage
 	default:
 		m = m.setErr(fmt.Errorf("unknown page type %d", newPage))
 	}
@@ -409,6 +477,12 @@ func (m Model) cleanupCmd() tea.Cmd {
 		if m.cancel != nil {
 			m.cancel()
 		}
+
+		if m.diskStore != nil {
+			if err := m.diskStore.Close(); err != nil {
+				dev.Debug(fmt.Sprintf("error removing log store: %v", err))
+			}
+		}
 		return message.CleanupCompleteMsg{}
 	}
 }
@@ -501,6 +575,11 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// show or hide throughput stats
+	if key.Matches(msg, m.keyMap.Stats) {
+		return m, m.stats.Toggle(time.Now())
+	}
+
 	// change timestamp format
 	if key.Matches(msg, m.keyMap.Timestamps) {
 		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithNewTimestampFormat()
@@ -513,6 +592,12 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m, nil
 	}
 
+	// change minimum log level
+	if key.Matches(msg, m.keyMap.LevelThreshold) {
+		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithNextLevelThreshold()
+		return m, nil
+	}
+
 	// change log order
 	if key.Matches(msg, m.keyMap.ReverseOrder) {
 		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithReversedLogOrder()
@@ -561,6 +646,13 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 		return m, tea.Batch(cmds...)
 	}
+
+	// patterns page specific actions
+	if m.state.focusedPageType == page.PatternsPageType {
+		m, cmd = m.handlePatternsPageKeyMsg(msg, hasAppliedFilter)
+		cmds = append(cmds, cmd)
+		return m, tea.Batch(cmds...)
+	}
 	return m, tea.Batch(cmds...)
 }
 
@@ -598,6 +690,15 @@ func (m Model) handleEntitiesPageKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m.changeSinceTime(msg)
 	}
 
+	// lift or restore the rate limit for the selected container
+	if key.Matches(msg, m.keyMap.RateLimit) {
+		selected := m.pages[page.EntitiesPageType].(page.EntityPage).GetSelectedEntity()
+		if selected != nil && selected.IsContainer() {
+			return m.toggleRateLimit(selected.Container)
+		}
+		return m, nil
+	}
+
 	// toggle pause state
 	if key.Matches(msg, m.keyMap.TogglePause) {
 		m.state.pauseState = !m.state.pauseState
@@ -629,6 +730,9 @@ func (m Model) doSelectionActions(selectionActions map[entity.Entity]bool) (Mode
 		}
 	}
 
+	m, cmd = m.startQueuedSelections()
+	cmds = append(cmds, cmd)
+
 	m.pages[page.EntitiesPageType] = m.pages[page.EntitiesPageType].(page.EntityPage).WithEntityTree(m.entityTree)
 	return m, tea.Batch(cmds...)
 }
@@ -641,36 +745,137 @@ func (m Model) getStartLogScannerCmd(client client.K8sClient, ent entity.Entity,
 		return m, nil
 	}
 
-	// ensure the entity does not already have an active log scanner
+	// ensure the entity does not already have an active or pending log scanner
 	if ent.LogScanner != nil {
 		return m, nil
 	}
+	if _, ok := m.pendingScannerStarts[ent.Container.ID()]; ok {
+		return m, nil
+	}
 
-	// check the limit of active log scanners isn't reached
-	numPendingOrActive := 0
+	// if the limit of active log scanners is reached, queue the entity until a slot frees up
+	if m.config.ContainerLimit >= 0 && m.numUsedSelectionSlots(ent) >= m.config.ContainerLimit {
+		newEntity, newTree, _ := ent.Queue(m.entityTree)
+		m.entityTree = newTree
+		dev.Debug(fmt.Sprintf("limit of %d selections reached, queued %s", m.config.ContainerLimit, newEntity.Container.HumanReadable()))
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := pendingScannerStart{id: m.nextScannerStartID, ctx: ctx, cancel: cancel}
+	m.nextScannerStartID++
+	m.pendingScannerStarts[ent.Container.ID()] = start
+
+	// bound the number of scanners starting at once so large selections don't get throttled by the API server.
+	// Queued entities stay in the ScannerStarting state, so they are shown as pending in the top bar
+	if m.config.StartConcurrency > 0 && m.numScannersStarting >= m.config.StartConcurrency {
+		m.queuedScannerStarts = append(m.queuedScannerStarts, queuedScannerStart{startID: start.id, container: ent.Container, sinceTime: sinceTime})
+		return m, nil
+	}
+	m.numScannersStarting++
+	return m, m.startLogScannerCmd(client, start, ent.Container, sinceTime)
+}
+
+// cancelScannerStart aborts the queued or in-flight log scanner start for a container, if any.
+// The result of an aborted in-flight start is ignored when it arrives
+func (m Model) cancelScannerStart(ct container.Container) Model {
+	start, ok := m.pendingScannerStarts[ct.ID()]
+	if !ok {
+		return m
+	}
+	start.cancel()
+	delete(m.pendingScannerStarts, ct.ID())
+
+	var remaining []queuedScannerStart
+	for _, queued := range m.queuedScannerStarts {
+		if queued.startID != start.id {
+			remaining = append(remaining, queued)
+		}
+	}
+	m.queuedScannerStarts = remaining
+	return m
+}
+
+// numUsedSelectionSlots returns the number of container entities, excluding the given one, that count towards
+// config.ContainerLimit. Selected entities waiting for their container to run use a slot, but those queued for one don't
+func (m Model) numUsedSelectionSlots(exclude entity.Entity) int {
+	numUsed := 0
 	for _, ce := range m.entityTree.GetContainerEntities() {
+		if ce.Container.Equals(exclude.Container) || ce.IsQueued() {
+			continue
+		}
 		switch ce.State {
 		case entity.WantScanning, entity.ScannerStarting, entity.Scanning, entity.ScannerStopping, entity.Deleted:
-			numPendingOrActive++
+			numUsed++
 		default:
 		}
 	}
-	if m.config.ContainerLimit >= 0 && numPendingOrActive >= m.config.ContainerLimit {
-		newToast := toast.New(fmt.Sprintf("limit of %d selections reached: run kl with --limit flag to increase", m.config.ContainerLimit))
-		m.components.toast = newToast
-		return m, tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return toast.TimeoutMsg{ID: newToast.ID} })
+	return numUsed
+}
+
+// startQueuedSelections starts queued entities in priority order while there are free selection slots
+// under config.ContainerLimit, and updates the queue positions of the entities that remain queued
+func (m Model) startQueuedSelections() (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	var cmds []tea.Cmd
+
+	var queued []entity.Entity
+	for _, ce := range m.entityTree.GetContainerEntities() {
+		if ce.IsQueued() {
+			queued = append(queued, ce)
+		} else if ce.QueuePosition != 0 {
+			ce.QueuePosition = 0
+			m.entityTree.AddOrReplace(ce)
+		}
 	}
+	if len(queued) == 0 {
+		return m, nil
+	}
+	entity.SortByQueuePriority(queued, m.config.LimitPriority)
 
-	colorize := func(s string) string {
-		return util.ColorizeJSON(s, util.JSONColorStyles{
-			Key:    m.data.theme.JSONKey,
-			String: m.data.theme.JSONString,
-			Number: m.data.theme.JSONNumber,
-			Bool:   m.data.theme.JSONBool,
-			Null:   m.data.theme.JSONNull,
-		})
+	position := 0
+	for _, ent := range queued {
+		if m.config.ContainerLimit < 0 || m.numUsedSelectionSlots(ent) < m.config.ContainerLimit {
+			newEntity, newTree, actions := ent.StartQueued(m.entityTree)
+			m.entityTree = newTree
+			m, cmd = m.doActions(newEntity, actions)
+			cmds = append(cmds, cmd)
+			continue
+		}
+		position++
+		if ent.QueuePosition != position {
+			ent.QueuePosition = position
+			m.entityTree.AddOrReplace(ent)
+		}
 	}
-	return m, command.StartLogScannerCmd(client, ent.Container, sinceTime, colorize)
+
+	m.pages[page.EntitiesPageType] = m.pages[page.EntitiesPageType].(page.EntityPage).WithEntityTree(m.entityTree)
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) startLogScannerCmd(client client.K8sClient, start pendingScannerStart, ct container.Container, sinceTime time.Time) tea.Cmd {
+	return command.StartLogScannerCmd(start.ctx, start.cancel, start.id, client, ct, sinceTime, m.config.Multiline, m.config.AppTimestamp)
+}
+
+// startQueuedScanners starts queued log scanners while there is room under config.StartConcurrency,
+// dropping queued starts that have been cancelled
+func (m Model) startQueuedScanners() (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	for len(m.queuedScannerStarts) > 0 {
+		if m.config.StartConcurrency > 0 && m.numScannersStarting >= m.config.StartConcurrency {
+			break
+		}
+		next := m.queuedScannerStarts[0]
+		m.queuedScannerStarts = m.queuedScannerStarts[1:]
+
+		start, ok := m.pendingScannerStarts[next.container.ID()]
+		if !ok || start.id != next.startID {
+			continue
+		}
+		m.numScannersStarting++
+		cmds = append(cmds, m.startLogScannerCmd(m.k8sClient, start, next.container, next.sinceTime))
+	}
+	return m, tea.Batch(cmds...)
 }
 
 func (m Model) handleLogsPageKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
@@ -691,6 +896,30 @@ func (m Model) handleLogsPageKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m.changeSinceTime(msg)
 	}
 
+	// collapse repeated lines. Only handled here, as the single log page scrolls by half a page with shift+d
+	if key.Matches(msg, m.keyMap.CollapseRepeats) {
+		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithNextCollapseMode()
+		return m, nil
+	}
+
+	// group the logs into patterns
+	if key.Matches(msg, m.keyMap.Patterns) {
+		logsPage := m.pages[page.LogsPageType].(page.LogsPage)
+		m.pages[page.PatternsPageType] = m.pages[page.PatternsPageType].(page.PatternsPage).WithPatterns(logsPage.Patterns())
+		m = m.changeFocusedPage(page.PatternsPageType)
+		m.state.rightPageType = page.PatternsPageType
+		return m, nil
+	}
+
+	// lift or restore the rate limit for the selected log's container
+	if key.Matches(msg, m.keyMap.RateLimit) {
+		selectedLog := m.pages[page.LogsPageType].(page.LogsPage).GetSelectedLog()
+		if selectedLog != nil {
+			return m.toggleRateLimit(selectedLog.Log.Container)
+		}
+		return m, nil
+	}
+
 	// toggle pause state
 	if key.Matches(msg, m.keyMap.TogglePause) {
 		m.state.pauseState = !m.state.pauseState
@@ -698,6 +927,21 @@ func (m Model) handleLogsPageKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	return m, nil
 }
 
+// toggleRateLimit lifts or restores the sampling policy for a container, showing a toast with the result
+func (m Model) toggleRateLimit(ct container.Container) (Model, tea.Cmd) {
+	toastMsg := "No rate limit or sampling configured"
+	if m.sampler.Enabled() {
+		if m.sampler.ToggleLimit(ct) {
+			toastMsg = fmt.Sprintf("Rate limit lifted for %s", ct.HumanReadable())
+		} else {
+			toastMsg = fmt.Sprintf("Rate limit restored for %s", ct.HumanReadable())
+		}
+	}
+	newToast := toast.New(toastMsg)
+	m.components.toast = newToast
+	return m, tea.Tick(time.Second*5, func(t time.Time) tea.Msg { return toast.TimeoutMsg{ID: newToast.ID} })
+}
+
 func (m Model) handleSingleLogPageKeyMsg(msg tea.KeyMsg, hasAppliedFilter bool) (Model, tea.Cmd) {
 	// handle clear
 	var cmds []tea.Cmd
@@ -732,6 +976,30 @@ func (m Model) handleSingleLogPageKeyMsg(msg tea.KeyMsg, hasAppliedFilter bool)
 	return m, nil
 }
 
+func (m Model) handlePatternsPageKeyMsg(msg tea.KeyMsg, hasAppliedFilter bool) (Model, tea.Cmd) {
+	// handle clear
+	isClear := key.Matches(msg, m.keyMap.Clear)
+	notHighjackingInput := !m.pages[m.state.focusedPageType].HighjackingInput()
+	if isClear && notHighjackingInput && !hasAppliedFilter {
+		m = m.changeFocusedPage(page.LogsPageType)
+		m.state.rightPageType = page.LogsPageType
+		return m, nil
+	}
+
+	// filter the logs page by the selected pattern
+	if key.Matches(msg, m.keyMap.Enter) && notHighjackingInput {
+		selected := m.pages[page.PatternsPageType].(page.PatternsPage).GetSelectedPattern()
+		if selected != nil {
+			logFilter := model.LogFilter{Value: selected.Regex(), Mode: filterableviewport.FilterRegex}
+			m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithLogFilter(logFilter)
+			m = m.changeFocusedPage(page.LogsPageType)
+			m.state.rightPageType = page.LogsPageType
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
 func (m Model) handlePromptKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 
@@ -772,7 +1040,7 @@ func (m Model) changeSinceTime(msg tea.KeyMsg) (Model, tea.Cmd) {
 	// 0 always available to "reset from now", otherwise can't change to the same since time
 	if newLookbackMins == 0 || newSinceTime != m.state.sinceTime {
 		m.state.pendingSinceTime = &newSinceTime
-		return m.attemptUpdateSinceTime()
+		return m.doUpdateSinceTime()
 	}
 
 	return m, nil
@@ -800,7 +1068,7 @@ func (m Model) handleContainerListenerMsg(msg command.GetContainerListenerMsg) (
 
 	// add the container listener and start collecting container deltas in batches for performance
 	m.containerListeners = append(m.containerListeners, msg.Listener)
-	cmd = command.GetNextContainerDeltasCmd(msg.Listener, constants.GetNextContainerDeltasDuration)
+	cmd = command.GetNextContainerDeltasCmd(msg.Listener, m.batchIntervals.ContainerDeltas())
 	cmds = append(cmds, cmd)
 	return m, tea.Batch(cmds...)
 }
@@ -852,18 +1120,38 @@ func (m Model) handleContainerDeltasMsg(msg command.GetContainerDeltasMsg) (Mode
 		}
 	}
 
+	// containers removed from the tree may have freed up selection slots
+	m, cmd = m.startQueuedSelections()
+	cmds = append(cmds, cmd)
+
 	m.pages[page.EntitiesPageType] = m.pages[page.EntitiesPageType].(page.EntityPage).WithEntityTree(m.entityTree)
-	cmds = append(cmds, command.GetNextContainerDeltasCmd(msg.Listener, constants.GetNextContainerDeltasDuration))
+	cmds = append(cmds, command.GetNextContainerDeltasCmd(msg.Listener, m.batchIntervals.ContainerDeltas()))
 	return m, tea.Batch(cmds...)
 }
 
 func (m Model) handleStartedLogScannerMsg(msg command.StartedLogScannerMsg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
+
+	// this start has finished one way or another, so free its slot for the next queued start
+	if m.numScannersStarting > 0 {
+		m.numScannersStarting--
+	}
+	m, cmd = m.startQueuedScanners()
+	cmds = append(cmds, cmd)
+
+	// ignore the result of a start that was cancelled, e.g. by deselection or a since time change
+	start, ok := m.pendingScannerStarts[msg.LogScanner.Container.ID()]
+	if !ok || start.id != msg.StartID {
+		msg.LogScanner.Cancel()
+		return m, tea.Batch(cmds...)
+	}
+	delete(m.pendingScannerStarts, msg.LogScanner.Container.ID())
+
 	startedContainerEntity := m.entityTree.GetEntity(msg.LogScanner.Container)
 	if startedContainerEntity == nil {
 		msg.LogScanner.Cancel()
-		return m, nil
+		return m, tea.Batch(cmds...)
 	}
 
 	ent, newTree, actions := startedContainerEntity.ScannerStarted(m.entityTree, msg.Err, msg.LogScanner)
@@ -871,8 +1159,12 @@ func (m Model) handleStartedLogScannerMsg(msg command.StartedLogScannerMsg) (Mod
 	m, cmd = m.doActions(ent, actions)
 	cmds = append(cmds, cmd)
 
+	// a failed start frees its selection slot
+	m, cmd = m.startQueuedSelections()
+	cmds = append(cmds, cmd)
+
 	m.pages[page.EntitiesPageType] = m.pages[page.EntitiesPageType].(page.EntityPage).WithEntityTree(m.entityTree)
-	cmds = append(cmds, command.GetNextLogsCmd(msg.LogScanner, constants.SingleContainerLogCollectionDuration))
+	cmds = append(cmds, command.GetNextLogsCmd(msg.LogScanner, m.batchIntervals.LogCollection()))
 	return m.withUpdatedContainerShortNames(), tea.Batch(cmds...)
 }
 
@@ -910,6 +1202,9 @@ func (m Model) handleStoppedLogScannersMsg(msg command.StoppedLogScannersMsg) (M
 		}
 	}
 
+	m, cmd = m.startQueuedSelections()
+	cmds = append(cmds, cmd)
+
 	m.pages[page.EntitiesPageType] = m.pages[page.EntitiesPageType].(page.EntityPage).WithEntityTree(m.entityTree)
 	return m.withUpdatedContainerShortNames(), tea.Batch(cmds...)
 }
@@ -957,34 +1252,40 @@ func (m Model) handleNewLogsMsg(msg command.GetNewLogsMsg) (Model, tea.Cmd) {
 	if !ent.LogScanner.Equals(msg.LogScanner) {
 		return m, nil
 	}
+	m.stats.RecordLogs(ent.Container.HumanReadable(), len(msg.NewLogs), msg.ChanDepth)
 
+	terminated := ent.Container.Status.State == container.ContainerTerminated
 	var newLogs []model.PageLog
-	var err error
+	var numSuppressed int
+	var lastSuppressed *k8s_log.Log
 	for i := range msg.NewLogs {
-		shortName := k8s_model.ContainerNameAndPrefix{}
-		if m.containerToShortName != nil {
-			shortName, err = m.containerToShortName(msg.NewLogs[i].Container)
-			if err != nil {
-				m = m.setErr(err)
-				return m, nil
-			}
+		if m.replays.IsReplay(&msg.NewLogs[i]) {
+			continue
 		}
-		fullName := k8s_model.ContainerNameAndPrefix{
-			Prefix:        msg.NewLogs[i].Container.IDWithoutContainerName(),
-			ContainerName: msg.NewLogs[i].Container.Name,
+		if !m.sampler.Keep(&msg.NewLogs[i]) {
+			numSuppressed++
+			lastSuppressed = &msg.NewLogs[i]
+			continue
 		}
-		newLog := model.PageLog{
-			Log: &msg.NewLogs[i],
-			ContainerNames: &model.PageLogContainerNames{
-				Short: shortName,
-				Full:  fullName,
-			},
-			Terminated: ent.Container.Status.State == container.ContainerTerminated,
-			Theme:      &m.data.theme,
+		newLog, err := m.newPageLog(&msg.NewLogs[i], terminated)
+		if err != nil {
+			m = m.setErr(err)
+			return m, nil
 		}
 		newLogs = append(newLogs, newLog)
 	}
 
+	// mark where logs were dropped so users know data is missing
+	if lastSuppressed != nil {
+		suppressed := model.NewSuppressedLog(*lastSuppressed, numSuppressed)
+		marker, err := m.newPageLog(&suppressed, terminated)
+		if err != nil {
+			m = m.setErr(err)
+			return m, nil
+		}
+		newLogs = append(newLogs, marker)
+	}
+
 	m.pageLogBuffer = append(m.pageLogBuffer, newLogs...)
 
 	// track the last log timestamp for this entity so scanner restarts resume from the right point
@@ -998,20 +1299,31 @@ func (m Model) handleNewLogsMsg(msg command.GetNewLogsMsg) (Model, tea.Cmd) {
 	if msg.DoneScanning {
 		return m, nil
 	}
-	return m, command.GetNextLogsCmd(msg.LogScanner, constants.SingleContainerLogCollectionDuration)
+	return m, command.GetNextLogsCmd(msg.LogScanner, m.batchIntervals.LogCollection())
 }
 
-// attemptUpdateSinceTime checks if there are any pending log scanners, and if not, updates the since time.
-// If there are pending log scanners, there's currently no way to stop or cancel pending log scanners,
-// so the since time change is queued up to be attempted again after a delay.
-func (m Model) attemptUpdateSinceTime() (Model, tea.Cmd) {
-	if m.entityTree.AnyScannerStarting() {
-		if !m.components.toast.Visible && m.state.pendingSinceTime != nil {
-			m.components.toast = toast.New(getUpdateSinceTimeText(m.state.pendingSinceTime.LookbackMins))
+func (m Model) newPageLog(log *k8s_log.Log, terminated bool) (model.PageLog, error) {
+	shortName := k8s_model.ContainerNameAndPrefix{}
+	if m.containerToShortName != nil {
+		var err error
+		shortName, err = m.containerToShortName(log.Container)
+		if err != nil {
+			return model.PageLog{}, err
 		}
-		return m, tea.Tick(constants.AttemptUpdateSinceTimeInterval, func(t time.Time) tea.Msg { return message.AttemptUpdateSinceTimeMsg{} })
 	}
-	return m.doUpdateSinceTime()
+	fullName := k8s_model.ContainerNameAndPrefix{
+		Prefix:        log.Container.IDWithoutContainerName(),
+		ContainerName: log.Container.Name,
+	}
+	return model.PageLog{
+		Log: log,
+		ContainerNames: &model.PageLogContainerNames{
+			Short:      shortName,
+			Full:       fullName,
+			Terminated: terminated,
+		},
+		Theme: &m.data.theme,
+	}, nil
 }
 
 func (m Model) doUpdateSinceTime() (Model, tea.Cmd) {
@@ -1034,6 +1346,13 @@ func (m Model) doUpdateSinceTime() (Model, tea.Cmd) {
 			m, cmd = m.doActions(ent, actions)
 			cmds = append(cmds, cmd)
 			logScannersToStopAndRestart = append(logScannersToStopAndRestart, *ent.LogScanner)
+		} else if containerEntity.State == entity.ScannerStarting {
+			// abort the in-flight start and start again with the new since time
+			m = m.cancelScannerStart(containerEntity.Container)
+			containerEntity.LastLogTime = time.Time{}
+			m.entityTree.AddOrReplace(containerEntity)
+			m, cmd = m.doActions(containerEntity, []entity.EntityAction{entity.StartScanner})
+			cmds = append(cmds, cmd)
 		}
 	}
 	// bulk stop log scanners together so they begin restarting one by one only after all have stopped
@@ -1060,6 +1379,9 @@ func (m Model) doActions(ent entity.Entity, actions []entity.EntityAction) (Mode
 			sinceTime := m.state.sinceTime.Time
 			if !ent.LastLogTime.IsZero() {
 				sinceTime = ent.LastLogTime.Add(time.Nanosecond)
+				m.replays.Restart(ent.Container)
+			} else {
+				m.replays.RemoveContainer(ent.Container)
 			}
 			m, cmd = m.getStartLogScannerCmd(m.k8sClient, ent, sinceTime)
 			cmds = append(cmds, cmd)
@@ -1074,6 +1396,8 @@ func (m Model) doActions(ent entity.Entity, actions []entity.EntityAction) (Mode
 			m = m.removeLogsForContainer(ent.Container)
 		case entity.MarkLogsTerminated:
 			m = m.markLogsTerminatedForContainer(ent.Container)
+		case entity.CancelScannerStart:
+			m = m.cancelScannerStart(ent.Container)
 		default:
 			panic(fmt.Sprintf("unknown entity action: %s", action))
 		}
@@ -1113,6 +1437,9 @@ func (m Model) updateShortNamesInBuffer() (Model, error) {
 }
 
 func (m Model) removeLogsForContainer(ct container.Container) Model {
+	m.stats.RemoveContainer(ct.HumanReadable())
+	m.sampler.RemoveContainer(ct)
+	m.replays.RemoveContainer(ct)
 	m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithLogsRemovedForContainer(ct)
 	m = m.removeContainerLogsFromBuffer(ct)
 	if ent := m.entityTree.GetEntity(ct); ent != nil {
@@ -1141,7 +1468,7 @@ func (m Model) markLogsTerminatedForContainer(container container.Container) Mod
 func (m Model) markContainerLogsTerminatedInBuffer(container container.Container) Model {
 	for i := range m.pageLogBuffer {
 		if m.pageLogBuffer[i].Log.Container.Equals(container) {
-			m.pageLogBuffer[i].Terminated = true
+			m.pageLogBuffer[i].ContainerNames.Terminated = true
 		}
 	}
 	return m
@@ -1164,13 +1491,3 @@ func getLookbackMins(keyString string) int {
 	}
 	return newLookbackMins
 }
-
-func getUpdateSinceTimeText(newLookbackMins int) string {
-	if newLookbackMins == 0 {
-		return "Changing time range to start from now onwards..."
-	}
-	if newLookbackMins == -1 {
-		return "Changing time range to start from first available logs..."
-	}
-	return fmt.Sprintf("Changing time range to start from %d minutes ago...", newLookbackMins)
-}
@@ -51,6 +51,7 @@ func newTestModel() Model {
 	m.pages[page.EntitiesPageType] = page.NewEntitiesPage(km, width, contentHeight, entityTree, theme)
 	m.pages[page.LogsPageType] = page.NewLogsPage(km, width, contentHeight, false, theme)
 	m.pages[page.SingleLogPageType] = page.NewSingleLogPage(km, width, contentHeight, theme)
+	m.pages[page.PatternsPageType] = page.NewPatternsPage(km, width, contentHeight, theme)
 	m.data.topBarHeight = 1
 
 	m.pages[m.state.focusedPageType] = m.pages[m.state.focusedPageType].WithFocus()
@@ -124,8 +125,8 @@ func TestScannerError_RestartsWithoutErrorScreen(t *testing.T) {
 
 	// simulate scanner started successfully
 	_, cancel := context.WithCancel(context.Background())
-	scanner := k8s_log.NewLogScanner(ct, nil, cancel, nil)
-	m = updateModel(t, m, command.StartedLogScannerMsg{LogScanner: scanner})
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
 
 	// entity should now be Scanning
 	ent = m.entityTree.GetEntity(ct)
@@ -163,6 +164,57 @@ func TestScannerError_RestartsWithoutErrorScreen(t *testing.T) {
 	}
 }
 
+func TestScannerRestart_PartwayThroughSecond_NoDuplicateLogs(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+
+	second := time.Now().Truncate(time.Second)
+	newLog := func(offset time.Duration, content string, sequence uint64) k8s_log.Log {
+		return k8s_log.Log{Timestamp: second.Add(offset), Container: ct, ContentItem: item.NewItem(content), Sequence: sequence}
+	}
+	m = updateModel(t, m, command.GetNewLogsMsg{
+		LogScanner: scanner,
+		NewLogs: []k8s_log.Log{
+			newLog(100*time.Millisecond, "first", 41),
+			newLog(500*time.Millisecond, "same", 42),
+			newLog(500*time.Millisecond, "same", 43),
+		},
+	})
+
+	// the stream breaks partway through the second and restarts
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, Err: fmt.Errorf("read tcp: read: connection reset by peer")})
+	_, cancel = context.WithCancel(context.Background())
+	restarted := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: restarted})
+
+	// the restarted stream replays the logs of the second, with restarted sequence numbers
+	m = updateModel(t, m, command.GetNewLogsMsg{
+		LogScanner: restarted,
+		NewLogs: []k8s_log.Log{
+			newLog(100*time.Millisecond, "first", 1),
+			newLog(500*time.Millisecond, "same", 2),
+			newLog(500*time.Millisecond, "same", 3),
+			newLog(700*time.Millisecond, "after restart", 4),
+			newLog(700*time.Millisecond, "after restart", 5),
+		},
+	})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+
+	exported := strings.Join(m.pages[page.LogsPageType].ContentForFile(), "\n")
+	for content, want := range map[string]int{"first": 1, "same": 2, "after restart": 2} {
+		if got := strings.Count(exported, content); got != want {
+			t.Errorf("expected %q %d times, got %d:\n%s", content, want, got, exported)
+		}
+	}
+}
+
 func TestContainerSelection_LogsAppearInView(t *testing.T) {
 	m := newTestModel()
 
@@ -174,8 +226,8 @@ func TestContainerSelection_LogsAppearInView(t *testing.T) {
 
 	// simulate scanner started successfully
 	_, cancel := context.WithCancel(context.Background())
-	scanner := k8s_log.NewLogScanner(ct, nil, cancel, nil)
-	m = updateModel(t, m, command.StartedLogScannerMsg{LogScanner: scanner})
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
 
 	// send logs with recognizable content
 	now := time.Now()
@@ -207,6 +259,77 @@ func TestContainerSelection_LogsAppearInView(t *testing.T) {
 	}
 }
 
+func TestCollapseRepeats_OnlyOnLogsPage(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+	m = updateModel(t, m, command.GetNewLogsMsg{
+		LogScanner: scanner,
+		NewLogs:    []k8s_log.Log{{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("hello")}},
+	})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	// shift+d scrolls the single log page by half a page
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.state.focusedPageType != page.SingleLogPageType {
+		t.Fatalf("expected single log page to be focused, got %v", m.state.focusedPageType)
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'D', Text: "D"})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEscape})
+	if m.state.focusedPageType != page.LogsPageType {
+		t.Fatalf("expected logs page to be focused, got %v", m.state.focusedPageType)
+	}
+	if view := m.View().Content; strings.Contains(view, "repeats collapsed") {
+		t.Errorf("expected repeats not to be collapsed from the single log page, got:\n%s", view)
+	}
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'D', Text: "D"})
+	if view := m.View().Content; !strings.Contains(view, "repeats collapsed") {
+		t.Errorf("expected repeats to be collapsed from the logs page, got:\n%s", view)
+	}
+}
+
+func TestBatchUpdate_DropsExpiredLogsWhileIdle(t *testing.T) {
+	m := newTestModel()
+	m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithRetentionPolicy(
+		model.RetentionPolicy{MaxAge: time.Hour},
+	)
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+	m = updateModel(t, m, command.GetNewLogsMsg{
+		LogScanner: scanner,
+		NewLogs: []k8s_log.Log{
+			{Timestamp: time.Now().Add(-time.Hour + 100*time.Millisecond), Container: ct, ContentItem: item.NewItem("expiring")},
+			{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("recent")},
+		},
+	})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	if view := m.View().Content; !strings.Contains(view, "expiring") {
+		t.Fatalf("expected log not to have expired yet, got:\n%s", view)
+	}
+
+	// no logs arrive while the first log expires
+	time.Sleep(200 * time.Millisecond)
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	view := m.View().Content
+	if strings.Contains(view, "expiring") || !strings.Contains(view, "recent") {
+		t.Errorf("expected only the expired log to be dropped, got:\n%s", view)
+	}
+}
+
 func TestContainerArrival_ShowsInEntityView(t *testing.T) {
 	m := newTestModel()
 
@@ -269,8 +392,8 @@ func TestDuplicateDeltas_NoDoubleStartScanner(t *testing.T) {
 
 	// simulate first scanner starting successfully
 	_, cancel1 := context.WithCancel(context.Background())
-	scanner1 := k8s_log.NewLogScanner(runningCt, nil, cancel1, nil)
-	m = updateModel(t, m, command.StartedLogScannerMsg{LogScanner: scanner1})
+	scanner1 := k8s_log.NewLogScanner(runningCt, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel1)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[runningCt.ID()].id, LogScanner: scanner1})
 
 	ent = m.entityTree.GetEntity(runningCt)
 	if ent == nil {
@@ -283,7 +406,7 @@ func TestDuplicateDeltas_NoDoubleStartScanner(t *testing.T) {
 	// if a duplicate StartScanner was dispatched, a second ScannerStarted would arrive
 	// for an entity already in Scanning state, which must not panic
 	_, cancel2 := context.WithCancel(context.Background())
-	scanner2 := k8s_log.NewLogScanner(runningCt, nil, cancel2, nil)
+	scanner2 := k8s_log.NewLogScanner(runningCt, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel2)
 	m = updateModel(t, m, command.StartedLogScannerMsg{LogScanner: scanner2})
 
 	ent = m.entityTree.GetEntity(runningCt)
@@ -294,3 +417,938 @@ func TestDuplicateDeltas_NoDoubleStartScanner(t *testing.T) {
 		t.Fatalf("expected Scanning, got %v", ent.State)
 	}
 }
+
+func TestStartConcurrency_QueuesStartsUntilSlotFrees(t *testing.T) {
+	m := newTestModel()
+	m.config.StartConcurrency = 1
+
+	ct1 := newAppTestContainer()
+	ct2 := newAppTestContainer()
+	ct2.Name = "sidecar"
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct1, true))
+	deltaSet.Add(newAppTestDelta(ct2, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+
+	if m.numScannersStarting != 1 {
+		t.Fatalf("expected 1 scanner starting, got %d", m.numScannersStarting)
+	}
+	if len(m.queuedScannerStarts) != 1 {
+		t.Fatalf("expected 1 queued scanner start, got %d", len(m.queuedScannerStarts))
+	}
+
+	// both containers are pending, even though only one start is in flight
+	for _, ct := range []container.Container{ct1, ct2} {
+		if ent := m.entityTree.GetEntity(ct); ent == nil || ent.State != entity.ScannerStarting {
+			t.Fatalf("expected %s to be ScannerStarting", ct.Name)
+		}
+	}
+	if !strings.Contains(m.topBar(), "2/0/2 Pending/Selected/Total") {
+		t.Errorf("expected both containers pending in top bar, got %q", m.topBar())
+	}
+
+	started := ct1
+	if m.queuedScannerStarts[0].container.Equals(ct1) {
+		started = ct2
+	}
+	_, cancel := context.WithCancel(context.Background())
+	m = updateModel(t, m, newAppTestStartedLogScannerMsg(m, started, cancel))
+
+	if len(m.queuedScannerStarts) != 0 {
+		t.Fatalf("expected queued start to be dispatched, got %d queued", len(m.queuedScannerStarts))
+	}
+	if m.numScannersStarting != 1 {
+		t.Fatalf("expected 1 scanner starting, got %d", m.numScannersStarting)
+	}
+	if ent := m.entityTree.GetEntity(started); ent == nil || ent.State != entity.Scanning {
+		t.Fatalf("expected started container to be Scanning")
+	}
+}
+
+func TestContainerLimit_QueuesSelectionsUntilSlotFrees(t *testing.T) {
+	m := newTestModel()
+	m.config.ContainerLimit = 1
+
+	ct1 := newAppTestContainer()
+	ct2 := newAppTestContainer()
+	ct2.Name = "sidecar"
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct1, true))
+	deltaSet.Add(newAppTestDelta(ct2, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+
+	var started, queued container.Container
+	for _, ct := range []container.Container{ct1, ct2} {
+		ent := m.entityTree.GetEntity(ct)
+		if ent == nil {
+			t.Fatalf("expected %s to exist in tree", ct.Name)
+		}
+		switch ent.State {
+		case entity.ScannerStarting:
+			started = ct
+		case entity.WantScanning:
+			queued = ct
+		default:
+			t.Fatalf("unexpected state %v for %s", ent.State, ct.Name)
+		}
+	}
+	if started.Name == "" || queued.Name == "" {
+		t.Fatal("expected one container starting and one queued")
+	}
+	if ent := m.entityTree.GetEntity(queued); ent.QueuePosition != 1 {
+		t.Errorf("expected queue position 1, got %d", ent.QueuePosition)
+	}
+	if ent := m.entityTree.GetEntity(queued); !strings.Contains(ent.Repr(), "queued #1") {
+		t.Errorf("expected entity repr to show queue position, got %q", ent.Repr())
+	}
+
+	_, cancel := context.WithCancel(context.Background())
+	m = updateModel(t, m, newAppTestStartedLogScannerMsg(m, started, cancel))
+	if ent := m.entityTree.GetEntity(queued); ent.State != entity.WantScanning {
+		t.Fatalf("expected queued container to still be WantScanning, got %v", ent.State)
+	}
+
+	// the started container terminating keeps its slot, as its logs stay selected
+	terminated := started
+	terminated.Status.State = container.ContainerTerminated
+	deltaSet = container.ContainerDeltaSet{}
+	deltaSet.Add(newAppTestDelta(terminated, false))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	if ent := m.entityTree.GetEntity(queued); ent.State != entity.WantScanning {
+		t.Fatalf("expected queued container to still be WantScanning, got %v", ent.State)
+	}
+
+	// deselecting the started container frees its slot for the queued container
+	m, _ = m.doSelectionActions(map[entity.Entity]bool{*m.entityTree.GetEntity(terminated): false})
+
+	ent := m.entityTree.GetEntity(queued)
+	if ent.State != entity.ScannerStarting {
+		t.Fatalf("expected queued container to be ScannerStarting, got %v", ent.State)
+	}
+	if ent.QueuePosition != 0 {
+		t.Errorf("expected queue position to be cleared, got %d", ent.QueuePosition)
+	}
+}
+
+func TestContainerLimit_WaitingContainerUsesSlot(t *testing.T) {
+	m := newTestModel()
+	m.config.ContainerLimit = 1
+
+	waiting := newAppTestContainer()
+	waiting.Status.State = container.ContainerWaiting
+	running := newAppTestContainer()
+	running.Name = "sidecar"
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(waiting, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	deltaSet = container.ContainerDeltaSet{}
+	deltaSet.Add(newAppTestDelta(running, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+
+	if ent := m.entityTree.GetEntity(waiting); ent.State != entity.WantScanning || ent.IsQueued() {
+		t.Fatalf("expected waiting container to be WantScanning and not queued, got %v", ent.State)
+	}
+	ent := m.entityTree.GetEntity(running)
+	if ent.State != entity.WantScanning || ent.QueuePosition != 1 {
+		t.Fatalf("expected running container to be queued behind the waiting one, got %v at position %d", ent.State, ent.QueuePosition)
+	}
+	if _, ok := m.pendingScannerStarts[running.ID()]; ok {
+		t.Error("expected no scanner start for the queued container")
+	}
+}
+
+func newAppTestStartedLogScannerMsg(m Model, ct container.Container, cancel context.CancelFunc) command.StartedLogScannerMsg {
+	return command.StartedLogScannerMsg{
+		StartID:    m.pendingScannerStarts[ct.ID()].id,
+		LogScanner: k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel),
+	}
+}
+
+func TestDeselectScannerStarting_CancelsPendingStart(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+
+	start, ok := m.pendingScannerStarts[ct.ID()]
+	if !ok {
+		t.Fatal("expected a pending scanner start")
+	}
+	// the start finishes after the container is deselected
+	cancelled := false
+	lateMsg := newAppTestStartedLogScannerMsg(m, ct, func() { cancelled = true })
+
+	ent := m.entityTree.GetEntity(ct)
+	m, _ = m.doSelectionActions(map[entity.Entity]bool{*ent: false})
+
+	if ent = m.entityTree.GetEntity(ct); ent.State != entity.Inactive {
+		t.Fatalf("expected Inactive, got %v", ent.State)
+	}
+	if start.ctx.Err() == nil {
+		t.Error("expected in-flight start context to be cancelled")
+	}
+
+	m = updateModel(t, m, lateMsg)
+	if ent = m.entityTree.GetEntity(ct); ent.State != entity.Inactive || ent.LogScanner != nil {
+		t.Errorf("expected late start result to be ignored, got state %v", ent.State)
+	}
+	if !cancelled {
+		t.Error("expected late log scanner to be cancelled")
+	}
+}
+
+func TestUpdateSinceTime_RestartsPendingStart(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	oldStart := m.pendingScannerStarts[ct.ID()]
+
+	newSinceTime := model.NewSinceTime(time.Now(), 0)
+	m.state.pendingSinceTime = &newSinceTime
+	m, _ = m.doUpdateSinceTime()
+
+	if m.state.sinceTime != newSinceTime {
+		t.Error("expected since time to be updated without waiting for the pending start")
+	}
+	if oldStart.ctx.Err() == nil {
+		t.Error("expected old start context to be cancelled")
+	}
+	newStart, ok := m.pendingScannerStarts[ct.ID()]
+	if !ok || newStart.id == oldStart.id {
+		t.Fatal("expected a new pending scanner start")
+	}
+	if ent := m.entityTree.GetEntity(ct); ent.State != entity.ScannerStarting {
+		t.Fatalf("expected ScannerStarting, got %v", ent.State)
+	}
+
+	// the result of the old start is ignored, the new one is used
+	_, cancel := context.WithCancel(context.Background())
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: oldStart.id, LogScanner: k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)})
+	if ent := m.entityTree.GetEntity(ct); ent.State != entity.ScannerStarting {
+		t.Fatalf("expected old start result to be ignored, got %v", ent.State)
+	}
+	m = updateModel(t, m, newAppTestStartedLogScannerMsg(m, ct, cancel))
+	if ent := m.entityTree.GetEntity(ct); ent.State != entity.Scanning {
+		t.Fatalf("expected Scanning, got %v", ent.State)
+	}
+}
+
+func TestPatterns_SelectingPatternFiltersLogs(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+
+	now := time.Now()
+	var logs []k8s_log.Log
+	for i, content := range []string{"request 1 took 5ms", "connection refused", "request 2 took 17ms", "request 3 took 2ms"} {
+		logs = append(logs, k8s_log.Log{Timestamp: now.Add(time.Duration(i) * time.Second), Container: ct, ContentItem: item.NewItem(content)})
+	}
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: logs})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'M', Text: "M"})
+	if m.state.focusedPageType != page.PatternsPageType {
+		t.Fatalf("expected patterns page to be focused, got %v", m.state.focusedPageType)
+	}
+	view := m.View().Content
+	if !strings.Contains(view, "3  ") || !strings.Contains(view, "request <*> took <*>") {
+		t.Errorf("expected view to contain the request pattern with its count, got:\n%s", view)
+	}
+	if !strings.Contains(view, "connection refused") {
+		t.Errorf("expected view to contain the connection pattern, got:\n%s", view)
+	}
+
+	// the most frequent pattern is selected first
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.state.focusedPageType != page.LogsPageType {
+		t.Fatalf("expected logs page to be focused, got %v", m.state.focusedPageType)
+	}
+	if !m.pages[page.LogsPageType].HasAppliedFilter() {
+		t.Error("expected pattern to be applied as a filter on the logs page")
+	}
+}
+
+func TestVolume_JumpingToBucketSelectsLogs(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+
+	now := time.Now()
+	var logs []k8s_log.Log
+	for i, offset := range []time.Duration{0, time.Second, time.Hour, time.Hour + time.Second} {
+		logs = append(logs, k8s_log.Log{Timestamp: now.Add(offset), Container: ct, ContentItem: item.NewItem(fmt.Sprintf("log %d", i))})
+	}
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: logs})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'V', Text: "V"})
+	if view := m.View().Content; !strings.Contains(view, "max 2 logs per") {
+		t.Errorf("expected view to contain the volume histogram, got:\n%s", view)
+	}
+
+	// the first bucket with logs is selected first, then the next bucket with logs, skipping the empty ones
+	expected := []string{"log 0", "log 2"}
+	for _, content := range expected {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: ']', Text: "]"})
+		selected := m.pages[page.LogsPageType].(page.LogsPage).GetSelectedLog()
+		if selected == nil || selected.Log.ContentItem.ContentNoAnsi() != content {
+			t.Fatalf("expected %q to be selected, got %v", content, selected)
+		}
+	}
+	if view := m.View().Content; !strings.Contains(view, ": 2 logs") {
+		t.Errorf("expected view to contain the selected bucket's count, got:\n%s", view)
+	}
+}
+
+func TestJumpToTime_SelectsFirstLogAtOrAfterTime(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var logs []k8s_log.Log
+	for i := range 5 {
+		logs = append(logs, k8s_log.Log{Timestamp: start.Add(time.Duration(i) * time.Minute), Container: ct, ContentItem: item.NewItem(fmt.Sprintf("log %d", i))})
+	}
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: logs})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	selectedContent := func() string {
+		selected := m.pages[page.LogsPageType].(page.LogsPage).GetSelectedLog()
+		if selected == nil {
+			return ""
+		}
+		return selected.Log.ContentItem.ContentNoAnsi()
+	}
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: ':', Text: ":"})
+	if !m.pages[page.LogsPageType].HighjackingInput() {
+		t.Fatal("expected go to time prompt to capture input")
+	}
+	for _, r := range "nope" {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if view := m.View().Content; !strings.Contains(view, `invalid time "nope"`) {
+		t.Errorf("expected view to contain the error, got:\n%s", view)
+	}
+
+	// the time is between logs, so the next log is selected
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+	for _, r := range start.Add(90 * time.Second).Local().Format(time.TimeOnly) {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.pages[page.LogsPageType].HighjackingInput() {
+		t.Fatal("expected go to time prompt to close")
+	}
+	if got := selectedContent(); got != "log 2" {
+		t.Errorf("expected log 2 to be selected, got %q", got)
+	}
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: '}', Text: "}"})
+	if got := selectedContent(); got != "log 3" {
+		t.Errorf("expected log 3 to be selected after stepping forward, got %q", got)
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: '{', Text: "{"})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: '{', Text: "{"})
+	if got := selectedContent(); got != "log 1" {
+		t.Errorf("expected log 1 to be selected after stepping back twice, got %q", got)
+	}
+}
+
+func TestJumpToTime_SelectsAmongMatchingLogsOnly(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	var logs []k8s_log.Log
+	for i, content := range []string{"error 0", "info 1", "info 2", "error 3", "info 4", "error 5"} {
+		logs = append(logs, k8s_log.Log{Timestamp: start.Add(time.Duration(i) * time.Minute), Container: ct, ContentItem: item.NewItem(content)})
+	}
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: logs})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: '/', Text: "/"})
+	for _, r := range "error" {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'x', Text: "x"})
+
+	selectedContent := func() string {
+		selected := m.pages[page.LogsPageType].(page.LogsPage).GetSelectedLog()
+		if selected == nil {
+			return ""
+		}
+		return selected.Log.ContentItem.ContentNoAnsi()
+	}
+
+	// the time is at a log that doesn't match, so the next matching log is selected
+	m = updateModel(t, m, tea.KeyPressMsg{Code: ':', Text: ":"})
+	for _, r := range start.Add(time.Minute).Local().Format(time.TimeOnly) {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if got := selectedContent(); got != "error 3" {
+		t.Errorf("expected error 3 to be selected, got %q", got)
+	}
+
+	// the time is after all logs, so the last matching log is selected
+	m = updateModel(t, m, tea.KeyPressMsg{Code: '}', Text: "}"})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: '}', Text: "}"})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: '}', Text: "}"})
+	if got := selectedContent(); got != "error 5" {
+		t.Errorf("expected error 5 to be selected after stepping past the last log, got %q", got)
+	}
+}
+
+func TestColumns_EnteredAtRuntime(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+
+	logs := []k8s_log.Log{
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem(`{"level":"info","msg":"request done","http":{"status":200}}`)},
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("plain text")},
+	}
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: logs})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'C', Text: "C"})
+	if !m.pages[page.LogsPageType].HighjackingInput() {
+		t.Fatal("expected columns prompt to capture input")
+	}
+	for _, r := range "msg, http.status" {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if m.pages[page.LogsPageType].HighjackingInput() {
+		t.Fatal("expected columns prompt to close")
+	}
+
+	view := m.View().Content
+	for _, expected := range []string{"request done  200", "plain text", "columns msg,http.status"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("expected view to contain %q, got:\n%s", expected, view)
+		}
+	}
+	if strings.Contains(view, `"level"`) {
+		t.Errorf("expected JSON log to be shown as columns, got:\n%s", view)
+	}
+}
+
+func TestLevelThreshold_HidesLowerLevels(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+
+	now := time.Now()
+	var logs []k8s_log.Log
+	for i, content := range []string{"DEBUG polling", "INFO started", "WARN slow request", "    at handler.go:12", "ERROR request failed"} {
+		logs = append(logs, k8s_log.Log{
+			Timestamp:   now.Add(time.Duration(i) * time.Second),
+			Container:   ct,
+			Level:       k8s_log.DetectLevel(content),
+			ContentItem: item.NewItem(content),
+		})
+	}
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: logs})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	// debug, info, then warn and above
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'E', Text: "E"})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'E', Text: "E"})
+	exported := strings.Join(m.pages[page.LogsPageType].ContentForFile(), "\n")
+	for _, content := range []string{"WARN slow request", "at handler.go:12", "ERROR request failed"} {
+		if !strings.Contains(exported, content) {
+			t.Errorf("expected %q to be exported, got:\n%s", content, exported)
+		}
+	}
+	for _, content := range []string{"DEBUG polling", "INFO started"} {
+		if strings.Contains(exported, content) {
+			t.Errorf("expected %q to be hidden, got:\n%s", content, exported)
+		}
+	}
+	view := m.View().Content
+	if !strings.Contains(view, "warn and above") || strings.Contains(view, "INFO started") || !strings.Contains(view, "WARN slow request") {
+		t.Errorf("expected view to show only warn and above, got:\n%s", view)
+	}
+
+	// back to showing all levels
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'E', Text: "E"})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'E', Text: "E"})
+	if view := m.View().Content; !strings.Contains(view, "DEBUG polling") {
+		t.Errorf("expected all levels to be shown, got:\n%s", view)
+	}
+}
+
+func TestJQFilter_MatchesJSONLogs(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+
+	logs := []k8s_log.Log{
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem(`{"status":200,"path":"/api/ok"}`)},
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem(`{"status":503,"path":"/api/fail"}`)},
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem(`{"status":500,"path":"/web/fail"}`)},
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("status 503 /api/plain")},
+	}
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: logs})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'J', Text: "J"})
+	for _, r := range `.status >=` {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if view := m.View().Content; !strings.Contains(view, "unexpected end of query") {
+		t.Errorf("expected syntax error in filter line, got:\n%s", view)
+	}
+	for _, r := range ` 500 and (.path | startswith("/api"))` {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'x', Text: "x"})
+
+	view := m.View().Content
+	if strings.Contains(view, "unexpected") {
+		t.Errorf("expected no syntax error for a valid query, got:\n%s", view)
+	}
+	if !strings.Contains(view, "/api/fail") {
+		t.Errorf("expected matching JSON log to be shown, got:\n%s", view)
+	}
+	for _, unexpected := range []string{"/api/ok", "/web/fail", "/api/plain"} {
+		if strings.Contains(view, unexpected) {
+			t.Errorf("expected %q to be filtered out, got:\n%s", unexpected, view)
+		}
+	}
+
+	content := m.pages[page.LogsPageType].ContentForFile()
+	if len(content) != 1 || !strings.Contains(content[0], "/api/fail") {
+		t.Errorf("expected only the matching log to be saved, got %v", content)
+	}
+}
+
+func TestJQFilter_MatchesLogsShownAsColumns(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+
+	logs := []k8s_log.Log{
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem(`{"status":200,"msg":"request done"}`)},
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem(`{"status":503,"msg":"request failed"}`)},
+	}
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: logs})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	// show timestamps and the messages as columns, so the rendered lines have no JSON
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 't', Text: "t"})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'C', Text: "C"})
+	for _, r := range "msg" {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'J', Text: "J"})
+	for _, r := range `.status >= 500` {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'x', Text: "x"})
+
+	view := m.View().Content
+	if !strings.Contains(view, "request failed") || strings.Contains(view, "request done") {
+		t.Errorf("expected only the failed request to be shown, got:\n%s", view)
+	}
+	content := m.pages[page.LogsPageType].ContentForFile()
+	if len(content) != 1 || !strings.Contains(content[0], "request failed") {
+		t.Errorf("expected only the failed request to be saved, got %v", content)
+	}
+}
+
+func TestExprFilter_MatchesFieldsAndTerms(t *testing.T) {
+	m := newTestModel()
+
+	web := newAppTestContainer()
+	worker := newAppTestContainer()
+	worker.Name = "worker"
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(web, true))
+	deltaSet.Add(newAppTestDelta(worker, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+
+	now := time.Now()
+	for i, ct := range []container.Container{web, worker} {
+		_, cancel := context.WithCancel(context.Background())
+		scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+		m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+		var logs []k8s_log.Log
+		for j, content := range []string{"ERROR request timeout", "INFO request done", "ERROR connection reset"} {
+			logs = append(logs, k8s_log.Log{
+				Timestamp:   now.Add(time.Duration(i*3+j) * time.Second),
+				Container:   ct,
+				Level:       k8s_log.DetectLevel(content),
+				ContentItem: item.NewItem(content),
+			})
+		}
+		m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: logs})
+	}
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'e', Text: "e"})
+	for _, r := range `container:worker AND level:error NOT "connection reset"` {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'x', Text: "x"})
+
+	content := m.pages[page.LogsPageType].ContentForFile()
+	if len(content) != 1 || !strings.Contains(content[0], "worker") || !strings.Contains(content[0], "request timeout") {
+		t.Fatalf("expected only the worker's error timeout log to be saved, got %v", content)
+	}
+	view := m.View().Content
+	if !strings.Contains(view, "request timeout") {
+		t.Errorf("expected matching log to be shown, got:\n%s", view)
+	}
+	for _, unexpected := range []string{"request done", "connection reset"} {
+		if strings.Contains(view, unexpected) {
+			t.Errorf("expected %q to be filtered out, got:\n%s", unexpected, view)
+		}
+	}
+	if strings.Count(view, "request timeout") != 1 {
+		t.Errorf("expected only the worker's timeout log to be shown, got:\n%s", view)
+	}
+}
+
+func TestExprFilter_ResolvesFieldsFromLogsWithNamesHidden(t *testing.T) {
+	m := newTestModel()
+
+	web := newAppTestContainer()
+	worker := newAppTestContainer()
+	worker.Name = "worker"
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(web, true))
+	deltaSet.Add(newAppTestDelta(worker, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+
+	scanners := make(map[string]k8s_log.LogScanner)
+	for _, ct := range []container.Container{web, worker} {
+		_, cancel := context.WithCancel(context.Background())
+		scanners[ct.Name] = k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+		m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanners[ct.Name]})
+	}
+	now := time.Now()
+	newLog := func(ct container.Container, offset int, content string) k8s_log.Log {
+		return k8s_log.Log{
+			Timestamp:   now.Add(time.Duration(offset) * time.Second),
+			Container:   ct,
+			Level:       k8s_log.DetectLevel(content),
+			ContentItem: item.NewItem(content),
+		}
+	}
+	// the same content from both containers, with a level that isn't its first word
+	content := `{"msg":"request timeout","level":"error"}`
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanners[web.Name], NewLogs: []k8s_log.Log{newLog(web, 0, content)}})
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanners[worker.Name], NewLogs: []k8s_log.Log{newLog(worker, 1, content)}})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	// hide the container names
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'c', Text: "c"})
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'e', Text: "e"})
+	for _, r := range `container:worker level:error` {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'x', Text: "x"})
+
+	if view := m.View().Content; strings.Count(view, "request timeout") != 1 {
+		t.Errorf("expected only the worker's log to be shown, got:\n%s", view)
+	}
+
+	// logs appended while filtering resolve their own fields too
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanners[web.Name], NewLogs: []k8s_log.Log{newLog(web, 2, content)}})
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanners[worker.Name], NewLogs: []k8s_log.Log{newLog(worker, 3, content)}})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	if view := m.View().Content; strings.Count(view, "request timeout") != 2 {
+		t.Errorf("expected only the worker's logs to be shown, got:\n%s", view)
+	}
+}
+
+func TestExprFilter_ShowsSyntaxError(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: []k8s_log.Log{
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("request timeout")},
+	}})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'e', Text: "e"})
+	for _, r := range `timeout AND` {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if view := m.View().Content; !strings.Contains(view, "unexpected end of expression") {
+		t.Errorf("expected syntax error in filter line, got:\n%s", view)
+	}
+}
+
+func TestJQFilter_ShowsPipePrecedenceError(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: []k8s_log.Log{
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem(`{"status":503,"path":"/api/users"}`)},
+	}})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'J', Text: "J"})
+	for _, r := range `.status >= 500 and .path | startswith("/api")` {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if view := m.View().Content; !strings.Contains(view, "startswith at 27 is piped") {
+		t.Errorf("expected precedence error in filter line, got:\n%s", view)
+	}
+}
+
+func TestFuzzyFilter_MatchesLogs(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: []k8s_log.Log{
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("request timeout")},
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("connection reset")},
+	}})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'z', Text: "z"})
+	for _, r := range "rqtmt" {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'x', Text: "x"})
+
+	view := m.View().Content
+	// matched characters are highlighted individually
+	if !strings.Contains(view, "ues") || !strings.Contains(view, "[fuzzy]") {
+		t.Errorf("expected fuzzy match to be shown, got:\n%s", view)
+	}
+	if strings.Contains(view, "connection reset") {
+		t.Errorf("expected non-matching log to be filtered out, got:\n%s", view)
+	}
+}
+
+func TestExclude_HidesMatchingLogs(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: []k8s_log.Log{
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("GET /healthz 200")},
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("GET /readyz 200")},
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("order placed")},
+	}})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	exclude := func(m Model, text string) Model {
+		m = updateModel(t, m, tea.KeyPressMsg{Code: 'X', Text: "X"})
+		for _, r := range text {
+			m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+		}
+		return updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	}
+	m = exclude(m, "healthz")
+	m = exclude(m, "/ready(z)?/")
+
+	view := m.View().Content
+	if strings.Contains(view, "GET /") {
+		t.Errorf("expected excluded logs to be hidden, got:\n%s", view)
+	}
+	if !strings.Contains(view, "order placed") || !strings.Contains(view, "excluding [healthz] [/ready(z)?/]") {
+		t.Errorf("expected remaining log and exclusion chips, got:\n%s", view)
+	}
+	if content := m.pages[page.LogsPageType].ContentForFile(); len(content) != 1 || !strings.Contains(content[0], "order placed") {
+		t.Errorf("expected only the remaining log to be saved, got %q", content)
+	}
+
+	// excluding the same text again restores its logs
+	m = exclude(m, "healthz")
+	view = m.View().Content
+	if !strings.Contains(view, "/healthz") || strings.Contains(view, "/readyz") || strings.Contains(view, "[healthz]") {
+		t.Errorf("expected healthz logs to be restored, got:\n%s", view)
+	}
+
+	// an invalid regex is shown and not applied
+	m = exclude(m, "/[/")
+	if view = m.View().Content; !strings.Contains(view, "invalid regex") {
+		t.Errorf("expected invalid regex error, got:\n%s", view)
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEscape})
+
+	// an empty exclusion restores all logs
+	m = exclude(m, "")
+	view = m.View().Content
+	if !strings.Contains(view, "/healthz") || !strings.Contains(view, "/readyz") || strings.Contains(view, "excluding") {
+		t.Errorf("expected all logs to be restored, got:\n%s", view)
+	}
+}
+
+func TestHighlights_ManagedFromList(t *testing.T) {
+	m := newTestModel()
+
+	ct := newAppTestContainer()
+	var deltaSet container.ContainerDeltaSet
+	deltaSet.Add(newAppTestDelta(ct, true))
+	m = updateModel(t, m, command.GetContainerDeltasMsg{DeltaSet: deltaSet})
+	_, cancel := context.WithCancel(context.Background())
+	scanner := k8s_log.NewLogScanner(ct, nil, k8s_log.MultilineRules{}, k8s_log.TimestampRule{}, cancel)
+	m = updateModel(t, m, command.StartedLogScannerMsg{StartID: m.pendingScannerStarts[ct.ID()].id, LogScanner: scanner})
+	m = updateModel(t, m, command.GetNewLogsMsg{LogScanner: scanner, NewLogs: []k8s_log.Log{
+		{Timestamp: time.Now(), Container: ct, ContentItem: item.NewItem("request timeout")},
+	}})
+	m = updateModel(t, m, message.BatchUpdateLogsMsg{})
+	m = m.changeFocusedPage(page.LogsPageType)
+
+	typeText := func(m Model, text string) Model {
+		for _, r := range text {
+			m = updateModel(t, m, tea.KeyPressMsg{Code: r, Text: string(r)})
+		}
+		return m
+	}
+	// the none theme underlines the first rule's matches
+	underlined := "\x1b[4"
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'H', Text: "H"})
+	if view := m.View().Content; !strings.Contains(view, "No highlights") || !strings.Contains(view, "Highlight: ") {
+		t.Errorf("expected empty list of highlights, got:\n%s", view)
+	}
+	m = typeText(m, "timeout")
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = typeText(m, "/req[a-z]+/")
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	view := item.StripAnsi(m.View().Content)
+	if !strings.Contains(view, "1 timeout") || !strings.Contains(view, "2 /req[a-z]+/") {
+		t.Errorf("expected list of highlights, got:\n%s", view)
+	}
+
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEscape})
+	view = m.View().Content
+	if strings.Contains(view, "Highlight: ") || !strings.Contains(view, "2 highlighted") {
+		t.Errorf("expected list to close and highlights to be counted, got:\n%s", view)
+	}
+	if !strings.Contains(view, underlined) {
+		t.Errorf("expected matches to be highlighted, got %q", view)
+	}
+
+	// highlights show in the single log page too
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if view = m.View().Content; !strings.Contains(view, underlined) {
+		t.Errorf("expected single log matches to be highlighted, got %q", view)
+	}
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEscape})
+
+	// choosing the first rule and submitting it removes it
+	m = updateModel(t, m, tea.KeyPressMsg{Code: 'H', Text: "H"})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyDown})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	m = updateModel(t, m, tea.KeyPressMsg{Code: tea.KeyEnter})
+	view = m.View().Content
+	if strings.Contains(view, "Highlight: ") || !strings.Contains(view, "1 highlighted") {
+		t.Errorf("expected list to close with one rule left, got:\n%s", view)
+	}
+	// the remaining rule takes the first style
+	if !strings.Contains(view, underlined) || !strings.Contains(view, "\x1b[m timeout") {
+		t.Errorf("expected only the remaining rule's matches to be highlighted, got %q", view)
+	}
+}
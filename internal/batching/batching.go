@@ -0,0 +1,81 @@
+package batching
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/constants"
+)
+
+const (
+	// costToIntervalRatio keeps adding a batch of logs to the logs page from taking more than a quarter of the time
+	// between batches, leaving the rest for input and rendering
+	costToIntervalRatio = 4
+	// shrinkFactor limits how quickly the interval shrinks when updates get cheap, so it doesn't oscillate
+	shrinkFactor = 0.75
+	// log scanners collect for a bit less than the batch interval so their logs make it into the next batch
+	logCollectionRatio = 0.75
+	// container deltas change less often and are more expensive to apply than logs
+	containerDeltasRatio = 1.5
+)
+
+// Config bounds the batching interval. The interval adapts between Min and Max, or is fixed if they are equal
+type Config struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+func (c Config) Validate() error {
+	if c.Min <= 0 {
+		return fmt.Errorf("minimum batch interval must be positive, got %s", c.Min)
+	}
+	if c.Max < c.Min {
+		return fmt.Errorf("maximum batch interval %s must not be less than minimum %s", c.Max, c.Min)
+	}
+	return nil
+}
+
+// Intervals adapts how often logs and container updates are batched to how expensive it is to apply them.
+// Under low traffic, batches are frequent so new logs show up quickly. As updating the logs page gets more expensive,
+// batches get less frequent so the UI stays responsive
+type Intervals struct {
+	config  Config
+	current time.Duration
+}
+
+// New returns intervals bounded by config. Unset or invalid bounds fall back to the defaults so the interval is
+// never zero, which would update the logs page in a busy loop
+func New(config Config) *Intervals {
+	if config.Min <= 0 {
+		config.Min = constants.DefaultMinBatchInterval
+	}
+	if config.Max <= 0 {
+		config.Max = constants.DefaultMaxBatchInterval
+	}
+	config.Max = max(config.Max, config.Min)
+	return &Intervals{config: config, current: config.Min}
+}
+
+// Observe adapts the intervals to the time it took to apply the last batch
+func (i *Intervals) Observe(cost time.Duration) {
+	desired := cost * costToIntervalRatio
+	if desired < i.current {
+		desired = max(desired, time.Duration(float64(i.current)*shrinkFactor))
+	}
+	i.current = min(max(desired, i.config.Min), i.config.Max)
+}
+
+// BatchUpdateLogs is the interval at which buffered logs are added to the logs page
+func (i *Intervals) BatchUpdateLogs() time.Duration {
+	return i.current
+}
+
+// LogCollection is how long a log scanner collects logs before sending them to be buffered
+func (i *Intervals) LogCollection() time.Duration {
+	return time.Duration(float64(i.current) * logCollectionRatio)
+}
+
+// ContainerDeltas is how long a container listener collects container changes before sending them
+func (i *Intervals) ContainerDeltas() time.Duration {
+	return time.Duration(float64(i.current) * containerDeltasRatio)
+}
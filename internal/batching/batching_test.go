@@ -0,0 +1,101 @@
+package batching
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/constants"
+)
+
+func TestIntervals_Observe(t *testing.T) {
+	intervals := New(Config{Min: 100 * time.Millisecond, Max: 2 * time.Second})
+	if got := intervals.BatchUpdateLogs(); got != 100*time.Millisecond {
+		t.Fatalf("expected to start at minimum interval, got %s", got)
+	}
+
+	steps := []struct {
+		name     string
+		cost     time.Duration
+		expected time.Duration
+	}{
+		{"cheap update stays at minimum", 5 * time.Millisecond, 100 * time.Millisecond},
+		{"expensive update stretches immediately", 100 * time.Millisecond, 400 * time.Millisecond},
+		{"very expensive update is capped at maximum", time.Second, 2 * time.Second},
+		{"cheap update shrinks gradually", 0, 1500 * time.Millisecond},
+		{"cheap update keeps shrinking", 0, 1125 * time.Millisecond},
+		{"shrinking stops where cost is a quarter of interval", 250 * time.Millisecond, time.Second},
+	}
+	for _, step := range steps {
+		intervals.Observe(step.cost)
+		if got := intervals.BatchUpdateLogs(); got != step.expected {
+			t.Errorf("%s: expected %s, got %s", step.name, step.expected, got)
+		}
+	}
+
+	if got := intervals.LogCollection(); got != 750*time.Millisecond {
+		t.Errorf("expected log collection to be derived from batch interval, got %s", got)
+	}
+	if got := intervals.ContainerDeltas(); got != 1500*time.Millisecond {
+		t.Errorf("expected container deltas to be derived from batch interval, got %s", got)
+	}
+
+	for range 20 {
+		intervals.Observe(0)
+	}
+	if got := intervals.BatchUpdateLogs(); got != 100*time.Millisecond {
+		t.Errorf("expected to return to minimum under low traffic, got %s", got)
+	}
+}
+
+func TestIntervals_Fixed(t *testing.T) {
+	intervals := New(Config{Min: 200 * time.Millisecond, Max: 200 * time.Millisecond})
+	for _, cost := range []time.Duration{0, time.Second, 0} {
+		intervals.Observe(cost)
+		if got := intervals.BatchUpdateLogs(); got != 200*time.Millisecond {
+			t.Errorf("expected fixed interval, got %s", got)
+		}
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		config  Config
+		wantErr bool
+	}{
+		{Config{Min: time.Millisecond, Max: time.Second}, false},
+		{Config{Min: time.Second, Max: time.Second}, false},
+		{Config{Min: 0, Max: time.Second}, true},
+		{Config{Min: time.Second, Max: time.Millisecond}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: expected error %v, got %v", tt.config, tt.wantErr, err)
+		}
+	}
+}
+
+func TestNew_UnsetConfigUsesDefaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		min, max time.Duration
+	}{
+		{"zero config", Config{}, constants.DefaultMinBatchInterval, constants.DefaultMaxBatchInterval},
+		{"negative minimum", Config{Min: -time.Second, Max: time.Second}, constants.DefaultMinBatchInterval, time.Second},
+		{"only minimum", Config{Min: 5 * time.Second}, 5 * time.Second, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		intervals := New(tt.config)
+		if got := intervals.BatchUpdateLogs(); got != tt.min {
+			t.Errorf("%s: expected to start at %s, got %s", tt.name, tt.min, got)
+		}
+		intervals.Observe(time.Hour)
+		if got := intervals.BatchUpdateLogs(); got != tt.max {
+			t.Errorf("%s: expected to be capped at %s, got %s", tt.name, tt.max, got)
+		}
+		intervals.Observe(0)
+		if got := intervals.BatchUpdateLogs(); got <= 0 {
+			t.Errorf("%s: expected a positive interval, got %s", tt.name, got)
+		}
+	}
+}
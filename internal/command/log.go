@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -13,39 +14,55 @@ import (
 )
 
 type StartedLogScannerMsg struct {
+	// StartID identifies the start attempt, so results of cancelled or superseded attempts can be ignored
+	StartID    int
 	LogScanner k8s_log.LogScanner
 	Err        error
 }
 
+// StartLogScannerCmd starts a log scanner for a container, joining multi-line records per multiline and extracting
+// the application's timestamps per timestamps. Cancelling ctx via cancel aborts the start if it is still in flight,
+// and the returned LogScanner's Cancel also calls cancel
 func StartLogScannerCmd(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	startID int,
 	client client.K8sClient,
 	container container.Container,
 	sinceTime time.Time,
-	colorize func(string) string,
+	multiline k8s_log.MultilineRules,
+	timestamps k8s_log.TimestampRule,
 ) tea.Cmd {
 	return func() tea.Msg {
 		dev.Debug(fmt.Sprintf("cmd running to start log scanner for container %v", container.HumanReadable()))
 		// update the container status just before getting a log stream in case status is not up to date
-		status, err := client.GetContainerStatus(container)
+		status, err := client.GetContainerStatus(ctx, container)
 		if err != nil {
+			cancel()
 			return StartedLogScannerMsg{
+				StartID:    startID,
 				LogScanner: k8s_log.LogScanner{Container: container},
-				Err:        fmt.Errorf("error getting container status: %v", err),
+				Err:        fmt.Errorf("error getting container status: %w", err),
 			}
 		}
 		container.Status = status
 
 		// attempt to create and start a log scanner from a k8s log stream
-		scanner, cancel, err := client.GetLogStream(container, sinceTime)
+		scanner, cancelStream, err := client.GetLogStream(ctx, container, sinceTime)
 		if err != nil {
+			cancel()
 			return StartedLogScannerMsg{
+				StartID:    startID,
 				LogScanner: k8s_log.LogScanner{Container: container},
-				Err:        fmt.Errorf("error getting log stream: %v", err),
+				Err:        fmt.Errorf("error getting log stream: %w", err),
 			}
 		}
-		ls := k8s_log.NewLogScanner(container, scanner, cancel, colorize)
+		ls := k8s_log.NewLogScanner(container, scanner, multiline, timestamps, func() {
+			cancelStream()
+			cancel()
+		})
 		ls.StartReadingLogs()
-		return StartedLogScannerMsg{LogScanner: ls}
+		return StartedLogScannerMsg{StartID: startID, LogScanner: ls}
 	}
 }
 
@@ -53,7 +70,9 @@ type GetNewLogsMsg struct {
 	LogScanner   k8s_log.LogScanner
 	NewLogs      []k8s_log.Log
 	DoneScanning bool
-	Err          error
+	// ChanDepth is the number of logs left waiting in the log scanner's channel after collecting, for stats
+	ChanDepth int
+	Err       error
 }
 
 func GetNextLogsCmd(ls k8s_log.LogScanner, duration time.Duration) tea.Cmd {
@@ -64,7 +83,12 @@ func GetNextLogsCmd(ls k8s_log.LogScanner, duration time.Duration) tea.Cmd {
 				return GetNewLogsMsg{LogScanner: ls, Err: logs.err}
 			}
 			if len(logs.collectedLogs) > 0 || logs.doneScanning {
-				return GetNewLogsMsg{LogScanner: ls, NewLogs: logs.collectedLogs, DoneScanning: logs.doneScanning}
+				return GetNewLogsMsg{
+					LogScanner:   ls,
+					NewLogs:      logs.collectedLogs,
+					DoneScanning: logs.doneScanning,
+					ChanDepth:    len(ls.LogChan),
+				}
 			}
 		}
 	}
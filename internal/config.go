@@ -1,23 +1,40 @@
 package internal
 
 import (
+	"github.com/robinovitch61/kl/internal/batching"
+	"github.com/robinovitch61/kl/internal/k8s/entity"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
 	"github.com/robinovitch61/kl/internal/model"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
 type Config struct {
 	AllNamespaces    bool
+	AppTimestamp     k8s_log.TimestampRule
+	Batching         batching.Config
+	Burst            int
+	Columns          []string
+	ColumnsTSV       bool
 	ContainerLimit   int
 	Contexts         []string
 	Descending       bool
+	DiskStoreDir     string
+	Highlights       []model.HighlightRule
 	IgnoreOwnerTypes []string
 	KubeConfigPath   string
+	LimitPriority    entity.QueuePriority
 	LogsView         bool
 	LogFilter        model.LogFilter
 	Matchers         model.Matchers
+	MemoryLines      int
+	Multiline        k8s_log.MultilineRules
 	Namespaces       []string
+	QPS              float32
+	Retention        model.RetentionPolicy
+	Sampling         model.SamplingPolicy
 	Selector         labels.Selector
 	SinceTime        model.SinceTime
+	StartConcurrency int
 	ThemeName        string
 	Version          string
 }
@@ -7,20 +7,28 @@ import (
 // *********************************************************************************************************************
 // THESE ARE KEY TO GOOD PERFORMANCE & RESPONSIVENESS IN HIGH LOG VOLUME SETTINGS (EXACT VALUES DETERMINED BY FEEL)
 
-// SingleContainerLogCollectionDuration controls the amount of time an individual container's log scanner will collect
-// logs for before returning them to the main Model via a tea.Msg
-var SingleContainerLogCollectionDuration = 150 * time.Millisecond
+// DefaultMinBatchInterval and DefaultMaxBatchInterval bound the cadence at which the main Model actually updates the
+// logs page with all the newly acquired logs from all the containers. In between updates, it accumulates logs from
+// received messages. The interval adapts to the cost of updating the logs page, and the time log scanners and
+// container listeners collect for before returning to the main Model via a tea.Msg is derived from it
+const (
+	DefaultMinBatchInterval = 100 * time.Millisecond
+	DefaultMaxBatchInterval = 2 * time.Second
+)
+
+// DefaultStartConcurrency controls how many log scanners may be starting at once. Each start makes a container status
+// request and opens a log stream, so selecting hundreds of containers at once otherwise gets throttled client-side
+const DefaultStartConcurrency = 10
 
-// GetNextContainerDeltasDuration controls the amount of time a container listener will collect containers deltas
-// before returning them to the main Model via a tea.Msg
-var GetNextContainerDeltasDuration = 300 * time.Millisecond
+// DefaultMemoryLines controls how many of the most recent logs are kept in memory when logs are stored on disk
+const DefaultMemoryLines = 20000
 
-// BatchUpdateLogsInterval controls the cadence at which the main Model actually updates the logs page with all
-// the newly acquired logs from all the containers. In between updates, it accumulates logs from received messages
-var BatchUpdateLogsInterval = 200 * time.Millisecond
+// DiskStoreSegmentBytes controls the size of each append-only file logs are stored in on disk
+const DiskStoreSegmentBytes = 64 * 1024 * 1024
 
-// AttemptUpdateSinceTimeInterval controls the cadence at which a "since time" update is attempted
-var AttemptUpdateSinceTimeInterval = 500 * time.Millisecond
+// DiskStorePageLines controls how many older logs are loaded back from disk at a time, e.g. when scrolling past the
+// oldest log in memory
+const DiskStorePageLines = 5000
 
 // *********************************************************************************************************************
 
@@ -0,0 +1,508 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"github.com/robinovitch61/viewport/filterableviewport"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+// Fields are the values of the fields an expression's terms can be qualified with, e.g. container:api. Terms on a
+// field that is missing are unknown rather than false, so `NOT container:api` doesn't match a pod, which has no
+// container
+type Fields map[string]string
+
+// the fields terms can be qualified with
+const (
+	FieldCluster   = "cluster"
+	FieldNamespace = "namespace"
+	FieldOwner     = "owner"
+	FieldPod       = "pod"
+	FieldContainer = "container"
+	FieldLevel     = "level"
+)
+
+var fieldNames = []string{FieldCluster, FieldNamespace, FieldOwner, FieldPod, FieldContainer, FieldLevel}
+
+// Expr is a parsed boolean filter expression, e.g. `level:error AND (timeout OR "connection reset") NOT pod:~web-.*`.
+//
+// Terms are words, "quoted phrases" or /regex/ literals, matched case-insensitively except for regexes. A term
+// qualified with a field, e.g. container:api, pod:"web 1" or pod:~web-.*, matches the field's value instead of the
+// content, where ~ makes the value a regex. Terms are combined with NOT, AND and OR, from highest to lowest
+// precedence, and parentheses. Adjacent terms are combined with AND
+type Expr struct {
+	root exprNode
+}
+
+// ParseExpr parses a boolean filter expression, returning an error describing where it is invalid
+func ParseExpr(text string) (*Expr, error) {
+	tokens, err := lexExpr(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != exprEOF {
+		return nil, p.unexpected(t, "expected AND, OR or a term")
+	}
+	return &Expr{root: root}, nil
+}
+
+// Matches returns true if the expression is true for content and fields
+func (e *Expr) Matches(content string, fields Fields) bool {
+	return e.root.eval(content, fields) == truthYes
+}
+
+// matchRanges returns the sorted, non-overlapping ranges of content matched by the expression's content terms that
+// aren't negated
+func (e *Expr) matchRanges(content string) []item.ByteRange {
+	var ranges []item.ByteRange
+	e.root.collect(content, false, &ranges)
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Start < ranges[j].Start
+	})
+	var merged []item.ByteRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.Start <= merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// truth is the result of an expression, unknown if it depends on a field that is missing
+type truth int8
+
+const (
+	truthNo truth = iota
+	truthUnknown
+	truthYes
+)
+
+func (t truth) not() truth {
+	return truthYes - t
+}
+
+type exprNode interface {
+	eval(content string, fields Fields) truth
+	// collect appends the ranges of content matched by the node's content terms, if not negated
+	collect(content string, negated bool, ranges *[]item.ByteRange)
+}
+
+// termNode matches content, or the value of field if set
+type termNode struct {
+	field string
+	re    *regexp.Regexp
+}
+
+func (n termNode) eval(content string, fields Fields) truth {
+	target := content
+	if n.field != "" {
+		value, ok := fields[n.field]
+		if !ok {
+			return truthUnknown
+		}
+		target = value
+	}
+	if n.re.MatchString(target) {
+		return truthYes
+	}
+	return truthNo
+}
+
+func (n termNode) collect(content string, negated bool, ranges *[]item.ByteRange) {
+	if negated || n.field != "" {
+		return
+	}
+	for _, r := range n.re.FindAllStringIndex(content, -1) {
+		if r[1] > r[0] {
+			*ranges = append(*ranges, item.ByteRange{Start: r[0], End: r[1]})
+		}
+	}
+}
+
+type notNode struct {
+	inner exprNode
+}
+
+func (n notNode) eval(content string, fields Fields) truth {
+	return n.inner.eval(content, fields).not()
+}
+
+func (n notNode) collect(content string, negated bool, ranges *[]item.ByteRange) {
+	n.inner.collect(content, !negated, ranges)
+}
+
+type andNode struct {
+	left, right exprNode
+}
+
+func (n andNode) eval(content string, fields Fields) truth {
+	left := n.left.eval(content, fields)
+	if left == truthNo {
+		return truthNo
+	}
+	return min(left, n.right.eval(content, fields))
+}
+
+func (n andNode) collect(content string, negated bool, ranges *[]item.ByteRange) {
+	n.left.collect(content, negated, ranges)
+	n.right.collect(content, negated, ranges)
+}
+
+type orNode struct {
+	left, right exprNode
+}
+
+func (n orNode) eval(content string, fields Fields) truth {
+	left := n.left.eval(content, fields)
+	if left == truthYes {
+		return truthYes
+	}
+	return max(left, n.right.eval(content, fields))
+}
+
+func (n orNode) collect(content string, negated bool, ranges *[]item.ByteRange) {
+	n.left.collect(content, negated, ranges)
+	n.right.collect(content, negated, ranges)
+}
+
+type exprTokenKind int
+
+const (
+	exprEOF exprTokenKind = iota
+	exprAnd
+	exprOr
+	exprNot
+	exprOpen
+	exprClose
+	// exprField is a field name followed by ':', with its value the next token
+	exprField
+	exprWord
+	exprPhrase
+	exprRegex
+)
+
+type exprToken struct {
+	kind exprTokenKind
+	// text is the word, the unquoted phrase, the regex pattern or the field name
+	text string
+	pos  int
+}
+
+func (t exprToken) String() string {
+	switch t.kind {
+	case exprEOF:
+		return "end of expression"
+	case exprPhrase:
+		return strconv.Quote(t.text)
+	case exprRegex:
+		return "/" + t.text + "/"
+	case exprField:
+		return strconv.Quote(t.text + ":")
+	}
+	return strconv.Quote(t.text)
+}
+
+// lexExpr splits an expression into tokens
+func lexExpr(input string) ([]exprToken, error) {
+	var tokens []exprToken
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, exprToken{kind: exprOpen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, exprToken{kind: exprClose, text: ")", pos: i})
+			i++
+		case c == '"':
+			end, s, err := lexPhrase(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, exprToken{kind: exprPhrase, text: s, pos: i})
+			i = end
+		default:
+			if end, pattern, ok := lexRegex(input, i); ok {
+				tokens = append(tokens, exprToken{kind: exprRegex, text: pattern, pos: i})
+				i = end
+				continue
+			}
+			start := i
+			for i < len(input) && !isExprDelimiter(input[i]) && !(input[i] == ':' && isKnownField(input[start:i])) {
+				i++
+			}
+			word := input[start:i]
+			if i < len(input) && input[i] == ':' {
+				field := exprToken{kind: exprField, text: word, pos: start}
+				var err error
+				if tokens, i, err = lexFieldValue(input, i+1, append(tokens, field)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			switch word {
+			case "AND":
+				tokens = append(tokens, exprToken{kind: exprAnd, text: word, pos: start})
+			case "OR":
+				tokens = append(tokens, exprToken{kind: exprOr, text: word, pos: start})
+			case "NOT":
+				tokens = append(tokens, exprToken{kind: exprNot, text: word, pos: start})
+			default:
+				tokens = append(tokens, exprToken{kind: exprWord, text: word, pos: start})
+			}
+		}
+	}
+	return append(tokens, exprToken{kind: exprEOF, pos: len(input)}), nil
+}
+
+// lexFieldValue lexes the value of the field that is the last token, a phrase, a regex after ~ or a word, which
+// may contain ':'
+func lexFieldValue(input string, start int, tokens []exprToken) ([]exprToken, int, error) {
+	i := start
+	kind := exprWord
+	if i < len(input) && input[i] == '~' {
+		kind = exprRegex
+		i++
+	}
+	if i < len(input) && input[i] == '"' {
+		end, s, err := lexPhrase(input, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		if kind == exprWord {
+			kind = exprPhrase
+		}
+		return append(tokens, exprToken{kind: kind, text: s, pos: start}), end, nil
+	}
+	if kind == exprWord {
+		if end, pattern, ok := lexRegex(input, i); ok {
+			return append(tokens, exprToken{kind: exprRegex, text: pattern, pos: start}), end, nil
+		}
+	}
+	valueStart := i
+	for i < len(input) && !isExprDelimiter(input[i]) {
+		i++
+	}
+	if i == valueStart {
+		return nil, 0, fmt.Errorf("expected a value after %s at %d", tokens[len(tokens)-1], start)
+	}
+	return append(tokens, exprToken{kind: kind, text: input[valueStart:i], pos: start}), i, nil
+}
+
+// lexPhrase lexes the quoted phrase at start, returning the index past its closing quote and the unquoted phrase
+func lexPhrase(input string, start int) (int, string, error) {
+	for i := start + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '"':
+			s, err := strconv.Unquote(input[start : i+1])
+			if err != nil {
+				return 0, "", fmt.Errorf("invalid phrase at %d", start)
+			}
+			return i + 1, s, nil
+		}
+	}
+	return 0, "", fmt.Errorf("unterminated phrase at %d", start)
+}
+
+// lexRegex lexes the /regex/ literal at start, returning the index past its closing slash and the pattern. A word
+// like /api/users isn't a regex, as the closing slash must be followed by a delimiter
+func lexRegex(input string, start int) (int, string, bool) {
+	if start >= len(input) || input[start] != '/' {
+		return 0, "", false
+	}
+	for i := start + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '/':
+			if i+1 < len(input) && !isExprDelimiter(input[i+1]) {
+				return 0, "", false
+			}
+			if i == start+1 {
+				return 0, "", false
+			}
+			return i + 1, strings.ReplaceAll(input[start+1:i], `\/`, "/"), true
+		}
+	}
+	return 0, "", false
+}
+
+func isExprDelimiter(c byte) bool {
+	return c == ' ' || c == '\t' || c == '(' || c == ')' || c == '"'
+}
+
+func isKnownField(s string) bool {
+	for _, name := range fieldNames {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// exprParser builds the expression tree by recursive descent, one function per precedence level from lowest to
+// highest: or, and, not and primary
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.tokens[p.pos]
+	if t.kind != exprEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) unexpected(t exprToken, hint string) error {
+	if t.kind == exprEOF {
+		return fmt.Errorf("unexpected end of expression, %s", hint)
+	}
+	return fmt.Errorf("unexpected %s at %d, %s", t, t.pos, hint)
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == exprOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case exprAnd:
+			p.next()
+		case exprNot, exprOpen, exprField, exprWord, exprPhrase, exprRegex:
+			// adjacent terms are combined with AND
+		default:
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+}
+
+func (p *exprParser) parseNot() (exprNode, error) {
+	if p.peek().kind == exprNot {
+		p.next()
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case exprOpen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.peek(); c.kind != exprClose {
+			return nil, p.unexpected(c, `expected ")"`)
+		}
+		p.next()
+		return inner, nil
+	case exprField:
+		value := p.next()
+		return newTermNode(t.text, value)
+	case exprWord, exprPhrase, exprRegex:
+		return newTermNode("", t)
+	}
+	return nil, p.unexpected(t, "expected a term")
+}
+
+// newTermNode returns a term matching the value token, a regex if it is one or otherwise case-insensitive text
+func newTermNode(field string, value exprToken) (exprNode, error) {
+	pattern := "(?i)" + regexp.QuoteMeta(value.text)
+	if value.kind == exprRegex {
+		pattern = value.text
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex at %d: %w", value.pos, err)
+	}
+	return termNode{field: field, re: re}, nil
+}
+
+// FilterExpr identifies the boolean expression filter mode
+const FilterExpr filterableviewport.FilterModeName = "expression"
+
+// ExprFilterMode returns a FilterMode that matches content for which a boolean expression, e.g.
+// `level:error AND (timeout OR "connection reset")`, is true, highlighting the content terms that aren't negated.
+// fields returns a function resolving the fields of each content matched in a scan that terms can be qualified
+// with. It is called once per scan, as the match function is, and may be nil if content has none
+func ExprFilterMode(k key.Binding, fields func() func(content string) Fields) filterableviewport.FilterMode {
+	return filterableviewport.FilterMode{
+		Name:  FilterExpr,
+		Key:   k,
+		Label: "[expr]",
+		GetMatchFunc: func(filterText string) (filterableviewport.MatchFunc, error) {
+			expr, err := ParseExpr(filterText)
+			if err != nil {
+				return nil, err
+			}
+			var contentFields func(string) Fields
+			if fields != nil {
+				contentFields = fields()
+			}
+			return func(content string) []item.ByteRange {
+				var f Fields
+				if contentFields != nil {
+					f = contentFields(content)
+				}
+				if !expr.Matches(content, f) {
+					return nil
+				}
+				if ranges := expr.matchRanges(content); len(ranges) > 0 {
+					return ranges
+				}
+				// matched only by fields or negated terms, so there is nothing in particular to highlight
+				return []item.ByteRange{{Start: 0, End: len(content)}}
+			}, nil
+		},
+	}
+}
@@ -0,0 +1,147 @@
+package filter_test
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/key"
+	"github.com/robinovitch61/kl/internal/filter"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+func TestExpr_Matches(t *testing.T) {
+	content := `12:00:00 web ERR request to /api/users failed: connection reset by peer`
+	fields := filter.Fields{filter.FieldPod: "web-7f9c", filter.FieldContainer: "api", filter.FieldLevel: "error"}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`failed`, true},
+		{`FAILED`, true},
+		{`failed reset`, true},
+		{`failed AND nope`, false},
+		{`failed OR nope`, true},
+		{`nope OR other`, false},
+		{`NOT nope`, true},
+		{`NOT failed`, false},
+		{`"connection reset"`, true},
+		{`"reset connection"`, false},
+		{`/users? failed/`, true},
+		{`/USERS/`, false},
+		{`/api/users`, true},
+		{`container:api`, true},
+		{`container:API`, true},
+		{`container:web`, false},
+		{`pod:~web-.*`, true},
+		{`pod:~^api`, false},
+		{`pod:/^web-[0-9a-f]+$/`, true},
+		{`level:error AND /api/`, true},
+		{`level:error NOT container:api`, false},
+		{`(level:warn OR level:error) AND (timeout OR "connection reset")`, true},
+		{`NOT (container:api OR pod:web)`, false},
+		{`NOT NOT failed`, true},
+		{`namespace:default`, false},
+		{`NOT namespace:default`, false},
+		{`namespace:default OR failed`, true},
+		{`container:api:v2`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := filter.ParseExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := expr.Matches(content, fields); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpr_Errors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{`failed AND`, "unexpected end of expression, expected a term"},
+		{`OR failed`, `unexpected "OR" at 0, expected a term`},
+		{`(failed`, `unexpected end of expression, expected ")"`},
+		{`failed)`, `unexpected ")" at 6, expected AND, OR or a term`},
+		{`"connection reset`, "unterminated phrase at 0"},
+		{`/[a-/`, "invalid regex at 0"},
+		{`pod:~[`, "invalid regex at 4"},
+		{`pod: web`, `expected a value after "pod:" at 4`},
+		{`pod:`, `expected a value after "pod:" at 4`},
+		{`pod:~`, `expected a value after "pod:" at 4`},
+		{`NOT`, "unexpected end of expression, expected a term"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := filter.ParseExpr(tt.expr)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %q, want it to contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExprFilterMode_HighlightsContentTerms(t *testing.T) {
+	content := "GET /api/users timeout after 30s"
+	fields := func() func(string) filter.Fields {
+		return func(string) filter.Fields {
+			return filter.Fields{filter.FieldContainer: "api"}
+		}
+	}
+	mode := filter.ExprFilterMode(key.NewBinding(key.WithKeys("e")), fields)
+
+	tests := []struct {
+		expr string
+		want []item.ByteRange
+	}{
+		{`timeout OR nope`, []item.ByteRange{{Start: 15, End: 22}}},
+		{`/api/ NOT nope`, []item.ByteRange{{Start: 5, End: 8}}},
+		{`"api/users" /users? timeout/`, []item.ByteRange{{Start: 5, End: 22}}},
+		{`container:api`, []item.ByteRange{{Start: 0, End: len(content)}}},
+		{`container:web`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			matchFn, err := mode.GetMatchFunc(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := matchFn(content)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestModel_MatchesFields(t *testing.T) {
+	mode := filter.ExprFilterMode(key.NewBinding(key.WithKeys("e")), nil)
+	f := filter.New("container:api failed", mode)
+	if !f.MatchesFields("request failed", filter.Fields{filter.FieldContainer: "api"}) {
+		t.Error("expected content and fields to match")
+	}
+	if f.MatchesFields("request failed", filter.Fields{filter.FieldContainer: "web"}) {
+		t.Error("expected other container to not match")
+	}
+	if f.Matches("request failed") {
+		t.Error("expected missing fields to not match")
+	}
+	if !filter.New("", mode).MatchesFields("anything", nil) {
+		t.Error("expected empty expression to match")
+	}
+	if filter.New("(", mode).MatchesFields("anything", nil) {
+		t.Error("expected invalid expression to not match")
+	}
+}
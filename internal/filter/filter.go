@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"github.com/robinovitch61/kl/internal/query"
 	"github.com/robinovitch61/viewport/filterableviewport"
 )
 
@@ -8,17 +9,76 @@ import (
 type Model struct {
 	value string
 	mode  filterableviewport.FilterMode
+	// expr is the parsed value if mode is the expression mode, nil if it is invalid
+	expr *Expr
+	// query is the parsed value if mode is the jq mode, nil if it is invalid
+	query *query.Query
 }
 
 // New creates a filter from text and a FilterMode.
 func New(text string, mode filterableviewport.FilterMode) Model {
-	return Model{value: text, mode: mode}
+	m := Model{value: text, mode: mode}
+	if mode.Name == FilterExpr && text != "" {
+		m.expr, _ = ParseExpr(text)
+	}
+	if mode.Name == FilterJQ && text != "" {
+		m.query, _ = query.Parse(text)
+	}
+	return m
 }
 
 func (m Model) Matches(s string) bool {
 	return m.mode.Matches(m.value, s)
 }
 
+// MatchesFields is like Matches, but resolves the fields of an expression's terms from fields rather than from s
+func (m Model) MatchesFields(s string, fields Fields) bool {
+	if m.mode.Name != FilterExpr || m.value == "" {
+		return m.Matches(s)
+	}
+	return m.expr != nil && m.expr.Matches(s, fields)
+}
+
+// MatchesLog is like MatchesFields, but matches a jq query against the JSON object in logContent, a log's content,
+// rather than in s
+func (m Model) MatchesLog(s string, fields Fields, logContent string) bool {
+	if m.mode.Name != FilterJQ || m.value == "" {
+		return m.MatchesFields(s, fields)
+	}
+	if m.query == nil {
+		return false
+	}
+	_, _, ok := matchJSONObject(m.query, logContent)
+	return ok
+}
+
+// Ranks returns true if matches are ranked by Score, i.e. the filter is fuzzy
+func (m Model) Ranks() bool {
+	return m.mode.Name == filterableviewport.FilterFuzzy && m.value != ""
+}
+
+// Score returns whether s matches, and if the filter ranks matches, how well, higher being better. Fuzzy matches
+// rank by how close together the viewport's fuzzy mode finds the characters of the filter text, then by how early
+func (m Model) Score(s string) (int, bool) {
+	if !m.Ranks() {
+		return 0, m.Matches(s)
+	}
+	matchFn, err := m.mode.GetMatchFunc(m.value)
+	if err != nil {
+		return 0, false
+	}
+	ranges := matchFn(s)
+	if len(ranges) == 0 {
+		return 0, false
+	}
+	start, end := ranges[0].Start, ranges[len(ranges)-1].End
+	return -(end-start)*(len(s)+1) - start, true
+}
+
 func (m Model) Value() string {
 	return m.value
 }
+
+func (m Model) ModeName() filterableviewport.FilterModeName {
+	return m.mode.Name
+}
@@ -0,0 +1,80 @@
+package filter
+
+import (
+	"encoding/json"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"github.com/robinovitch61/kl/internal/query"
+	"github.com/robinovitch61/viewport/filterableviewport"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+// FilterJQ identifies the jq-like query filter mode
+const FilterJQ filterableviewport.FilterModeName = "jq"
+
+// JQFilterMode returns a FilterMode that matches JSON objects for which a jq-like query, e.g.
+// `.status >= 500 and (.path | startswith("/api"))`, is true. contents returns a function resolving the log content
+// of each content matched in a scan, as content may be rendered with a prefix like a timestamp or as columns. It is
+// called once per scan, as the match function is, and may be nil if content is the log content. The JSON object is
+// found at the start of the log content or after a prefix like an application timestamp, and is highlighted as a
+// whole where content shows it, or all of content otherwise. Log content without a JSON object never matches
+func JQFilterMode(k key.Binding, contents func() func(content string) string) filterableviewport.FilterMode {
+	return filterableviewport.FilterMode{
+		Name:  FilterJQ,
+		Key:   k,
+		Label: "[jq]",
+		GetMatchFunc: func(filterText string) (filterableviewport.MatchFunc, error) {
+			q, err := query.Parse(filterText)
+			if err != nil {
+				return nil, err
+			}
+			var logContent func(string) string
+			if contents != nil {
+				logContent = contents()
+			}
+			return func(content string) []item.ByteRange {
+				c := content
+				if logContent != nil {
+					c = logContent(content)
+				}
+				start, end, ok := matchJSONObject(q, c)
+				if !ok {
+					return nil
+				}
+				if i := strings.Index(content, c[start:end]); i >= 0 {
+					return []item.ByteRange{{Start: i, End: i + end - start}}
+				}
+				return []item.ByteRange{{Start: 0, End: len(content)}}
+			}, nil
+		},
+	}
+}
+
+// matchJSONObject returns the byte range of the JSON object in content if q matches it
+func matchJSONObject(q *query.Query, content string) (int, int, bool) {
+	start, end, value, ok := findJSONObject(content)
+	if !ok || !q.Matches(value) {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// findJSONObject decodes the JSON object that starts at the beginning of content or at the first " {", returning its
+// byte range
+func findJSONObject(content string) (int, int, interface{}, bool) {
+	start := 0
+	if !strings.HasPrefix(content, "{") {
+		i := strings.Index(content, " {")
+		if i < 0 {
+			return 0, 0, nil, false
+		}
+		start = i + 1
+	}
+	decoder := json.NewDecoder(strings.NewReader(content[start:]))
+	var value map[string]interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return 0, 0, nil, false
+	}
+	return start, start + int(decoder.InputOffset()), value, true
+}
@@ -10,8 +10,10 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/robinovitch61/kl/internal/command"
+	"github.com/robinovitch61/kl/internal/constants"
 	"github.com/robinovitch61/kl/internal/dev"
 	"github.com/robinovitch61/kl/internal/k8s/client"
+	"github.com/robinovitch61/kl/internal/logstore"
 	"github.com/robinovitch61/kl/internal/message"
 	"github.com/robinovitch61/kl/internal/page"
 	"github.com/robinovitch61/kl/internal/style"
@@ -38,6 +40,8 @@ func initializedModel(m Model) (Model, tea.Cmd, error) {
 		m.config.Contexts,
 		m.config.Namespaces,
 		m.config.AllNamespaces,
+		m.config.QPS,
+		m.config.Burst,
 	)
 	if err != nil {
 		return m, nil, err
@@ -46,6 +50,14 @@ func initializedModel(m Model) (Model, tea.Cmd, error) {
 
 	m.entityTree = entity.NewEntityTree(m.k8sClient.AllClusterNamespaces())
 
+	if m.config.DiskStoreDir != "" {
+		store, err := logstore.NewDiskStore(m.config.DiskStoreDir, constants.DiskStoreSegmentBytes)
+		if err != nil {
+			return m, nil, err
+		}
+		m.diskStore = store
+	}
+
 	m = initializePages(m)
 
 	cmds := createInitialCommands(m)
@@ -73,8 +85,22 @@ func initializePages(m Model) Model {
 	m.pages[page.EntitiesPageType] = page.NewEntitiesPage(m.keyMap, m.state.width, contentHeight, m.entityTree, theme)
 	m.pages[page.LogsPageType] = page.NewLogsPage(m.keyMap, m.state.width, contentHeight, m.config.Descending, theme)
 	m.pages[page.SingleLogPageType] = page.NewSingleLogPage(m.keyMap, m.state.width, contentHeight, theme)
+	m.pages[page.PatternsPageType] = page.NewPatternsPage(m.keyMap, m.state.width, contentHeight, theme)
+
+	m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithRetentionPolicy(m.config.Retention)
+	if m.diskStore != nil {
+		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithDiskStore(m.diskStore, m.config.MemoryLines)
+	}
+
+	if len(m.config.Columns) > 0 || m.config.ColumnsTSV {
+		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithColumns(m.config.Columns, m.config.ColumnsTSV)
+	}
+
+	if len(m.config.Highlights) > 0 {
+		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithHighlights(m.config.Highlights)
+	}
 
-	if m.config.LogFilter.Value != "" {
+	if m.config.LogFilter.Value != "" || len(m.config.LogFilter.Excludes) > 0 {
 		m.pages[page.LogsPageType] = m.pages[page.LogsPageType].(page.LogsPage).WithLogFilter(m.config.LogFilter)
 	}
 
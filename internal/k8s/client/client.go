@@ -33,11 +33,12 @@ type K8sClient interface {
 		ignorePodOwnerTypes []string,
 	) (ContainerListener, error)
 
-	// GetContainerStatus returns the status of a container
-	GetContainerStatus(container container.Container) (container.ContainerStatus, error)
+	// GetContainerStatus returns the status of a container. Cancelling ctx aborts the request
+	GetContainerStatus(ctx context.Context, container container.Container) (container.ContainerStatus, error)
 
-	// GetLogStream returns a scanner that reads lines from a container's log stream
-	GetLogStream(container container.Container, sinceTime time.Time) (*bufio.Scanner, context.CancelFunc, error)
+	// GetLogStream returns a scanner that reads lines from a container's log stream. Cancelling ctx aborts
+	// the request, or closes the stream if it is already open
+	GetLogStream(ctx context.Context, container container.Container, sinceTime time.Time) (*bufio.Scanner, context.CancelFunc, error)
 }
 
 type clientImpl struct {
@@ -157,7 +158,18 @@ func (c clientImpl) GetContainerListener(
 	return NewContainerListener(ctx, cluster, namespace, deltaChan, cancel), nil
 }
 
+// withClientCtx returns a child of ctx that is also cancelled when the client's context is cancelled
+func (c clientImpl) withClientCtx(ctx context.Context) (context.Context, context.CancelFunc) {
+	childCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.ctx, cancel)
+	return childCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
 func (c clientImpl) GetContainerStatus(
+	ctx context.Context,
 	ct container.Container,
 ) (container.ContainerStatus, error) {
 	clientset := c.clusterToClientset[ct.Cluster]
@@ -165,14 +177,17 @@ func (c clientImpl) GetContainerStatus(
 		return container.ContainerStatus{}, fmt.Errorf("clientset for cluster %s not found", ct.Cluster)
 	}
 
-	pod, err := clientset.CoreV1().Pods(ct.Namespace).Get(c.ctx, ct.Pod, metav1.GetOptions{})
+	reqCtx, cancel := c.withClientCtx(ctx)
+	defer cancel()
+	pod, err := clientset.CoreV1().Pods(ct.Namespace).Get(reqCtx, ct.Pod, metav1.GetOptions{})
 	if err != nil {
-		return container.ContainerStatus{}, fmt.Errorf("error getting pod %s in namespace %s: %v", ct.Pod, ct.Namespace, err)
+		return container.ContainerStatus{}, fmt.Errorf("error getting pod %s in namespace %s: %w", ct.Pod, ct.Namespace, err)
 	}
 	return getStatus(pod.Status.ContainerStatuses, ct.Name)
 }
 
 func (c clientImpl) GetLogStream(
+	ctx context.Context,
 	container container.Container,
 	sinceTime time.Time,
 ) (*bufio.Scanner, context.CancelFunc, error) {
@@ -188,11 +203,11 @@ func (c clientImpl) GetLogStream(
 		SinceTime:  &metav1.Time{Time: sinceTime},
 	}
 	logs := clientset.CoreV1().Pods(container.Namespace).GetLogs(container.Pod, logOptions)
-	childCtx, cancel := context.WithCancel(c.ctx)
+	childCtx, cancel := c.withClientCtx(ctx)
 	logStream, err := logs.Stream(childCtx)
 	if err != nil {
 		cancel()
-		return nil, nil, fmt.Errorf("error getting log scanner: %v", err)
+		return nil, nil, fmt.Errorf("error getting log scanner: %w", err)
 	}
 
 	// create a scanner that reads lines from the log stream
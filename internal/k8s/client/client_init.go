@@ -20,6 +20,8 @@ func NewK8sClient(
 	contexts []string,
 	namespaces []string,
 	useAllNamespaces bool,
+	qps float32,
+	burst int,
 ) (K8sClient, error) {
 	rawKubeConfig, loadingRules, err := getKubeConfig(kubeConfigPath)
 	if err != nil {
@@ -87,7 +89,7 @@ func NewK8sClient(
 		}
 	}
 
-	clusterToClientSet, err := createClientSets(clusters, clusterToContext, loadingRules)
+	clusterToClientSet, err := createClientSets(clusters, clusterToContext, loadingRules, qps, burst)
 	if err != nil {
 		return clientImpl{}, err
 	}
@@ -114,10 +116,10 @@ func getKubeConfig(kubeConfigPath string) (api.Config, *clientcmd.ClientConfigLo
 	return rawKubeConfig, loadingRules, nil
 }
 
-func createClientSets(clusters []string, clusterToContext map[string]string, loadingRules *clientcmd.ClientConfigLoadingRules) (map[string]*kubernetes.Clientset, error) {
+func createClientSets(clusters []string, clusterToContext map[string]string, loadingRules *clientcmd.ClientConfigLoadingRules, qps float32, burst int) (map[string]*kubernetes.Clientset, error) {
 	clusterToClientSet := make(map[string]*kubernetes.Clientset)
 	for _, cluster := range clusters {
-		clientset, err := createClientSetForCluster(cluster, clusterToContext, loadingRules)
+		clientset, err := createClientSetForCluster(cluster, clusterToContext, loadingRules, qps, burst)
 		if err != nil {
 			return nil, err
 		}
@@ -126,7 +128,7 @@ func createClientSets(clusters []string, clusterToContext map[string]string, loa
 	return clusterToClientSet, nil
 }
 
-func createClientSetForCluster(cluster string, clusterToContext map[string]string, loadingRules *clientcmd.ClientConfigLoadingRules) (*kubernetes.Clientset, error) {
+func createClientSetForCluster(cluster string, clusterToContext map[string]string, loadingRules *clientcmd.ClientConfigLoadingRules, qps float32, burst int) (*kubernetes.Clientset, error) {
 	contextName, exists := clusterToContext[cluster]
 	if !exists {
 		return nil, fmt.Errorf("no context found for cluster %s in kubeconfig", cluster)
@@ -146,6 +148,14 @@ func createClientSetForCluster(cluster string, clusterToContext map[string]strin
 		return nil, fmt.Errorf("failed to get client config for cluster %s: %w", cluster, err)
 	}
 
+	// zero values leave client-go's default rate limiting in place
+	if qps > 0 {
+		config.QPS = qps
+	}
+	if burst > 0 {
+		config.Burst = burst
+	}
+
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create clientset for cluster %s: %w", cluster, err)
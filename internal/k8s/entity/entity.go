@@ -2,10 +2,12 @@ package entity
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/robinovitch61/kl/internal/constants"
 	"github.com/robinovitch61/kl/internal/dev"
+	"github.com/robinovitch61/kl/internal/filter"
 	"github.com/robinovitch61/kl/internal/k8s/container"
 	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
 	"github.com/robinovitch61/kl/internal/util"
@@ -20,12 +22,37 @@ type Entity struct {
 	Prefix                                    string
 	State                                     EntityState
 	LastLogTime                               time.Time
+	// QueuePosition is the 1-indexed position of a queued entity waiting for a free selection slot, 0 if not queued
+	QueuePosition int
 }
 
 func (e Entity) GetItem() item.Item {
 	return item.NewItem(e.Repr())
 }
 
+// FilterFields returns the fields filter expressions can qualify terms with, e.g. pod:web. An entity only has the
+// fields of itself and its ancestors, e.g. a pod has no container field
+func (e Entity) FilterFields() filter.Fields {
+	fields := filter.Fields{filter.FieldCluster: e.Container.Cluster}
+	if e.IsCluster {
+		return fields
+	}
+	fields[filter.FieldNamespace] = e.Container.Namespace
+	if e.IsNamespace {
+		return fields
+	}
+	fields[filter.FieldOwner] = e.Container.PodOwner
+	if e.IsPodOwner {
+		return fields
+	}
+	fields[filter.FieldPod] = e.Container.Pod
+	if e.IsPod {
+		return fields
+	}
+	fields[filter.FieldContainer] = e.Container.Name
+	return fields
+}
+
 // Repr is a faster equivalent to e.Render().Content()
 func (e Entity) Repr() string {
 	if e.IsCluster {
@@ -74,6 +101,11 @@ func (e Entity) Repr() string {
 		res += " - NEW"
 	}
 
+	// show where queued containers are in line for a selection slot
+	if e.IsQueued() && e.QueuePosition > 0 {
+		res += " - queued #" + strconv.Itoa(e.QueuePosition)
+	}
+
 	res += ")"
 	return res
 }
@@ -101,6 +133,15 @@ func (e Entity) AssertIsContainer() error {
 	return nil
 }
 
+// IsQueued returns true if the entity wants to scan and its container is ready to be scanned,
+// i.e. it is only waiting for a free selection slot
+func (e Entity) IsQueued() bool {
+	if e.State != WantScanning {
+		return false
+	}
+	return e.Container.Status.State == container.ContainerRunning || e.Container.Status.State == container.ContainerTerminated
+}
+
 func (e Entity) IsChildContainerOfCluster(cluster Entity) bool {
 	return e.IsContainer() && e.Container.InClusterOf(cluster.Container)
 }
@@ -158,8 +199,13 @@ func (e Entity) Deactivate(tree Tree) (Entity, Tree, []EntityAction) {
 	switch e.State {
 	case WantScanning:
 		e.State = Inactive
+		e.QueuePosition = 0
 		tree.AddOrReplace(e)
 		return e, tree, []EntityAction{RemoveLogs}
+	case ScannerStarting:
+		e.State = Inactive
+		tree.AddOrReplace(e)
+		return e, tree, []EntityAction{CancelScannerStart, RemoveLogs}
 	case Scanning:
 		e.State = ScannerStopping
 		tree.AddOrReplace(e)
@@ -187,6 +233,42 @@ func (e Entity) Restart(tree Tree) (Entity, Tree, []EntityAction) {
 	}
 }
 
+// Queue moves an entity that was about to start scanning back to waiting, e.g. when the selection limit is reached
+func (e Entity) Queue(tree Tree) (Entity, Tree, []EntityAction) {
+	dev.Debug(fmt.Sprintf("Queue %v starts %v", e.Container.HumanReadable(), e.State))
+	defer func() {
+		dev.Debug(fmt.Sprintf("Queue %v ends %v", e.Container.HumanReadable(), e.State))
+	}()
+	switch e.State {
+	case ScannerStarting:
+		e.State = WantScanning
+		tree.AddOrReplace(e)
+		return e, tree, []EntityAction{}
+	default:
+		panic(fmt.Sprintf("Queue called for entity in %v state", e.State))
+	}
+}
+
+// StartQueued starts scanning for an entity that was waiting for a free selection slot
+func (e Entity) StartQueued(tree Tree) (Entity, Tree, []EntityAction) {
+	dev.Debug(fmt.Sprintf("StartQueued %v starts %v", e.Container.HumanReadable(), e.State))
+	defer func() {
+		dev.Debug(fmt.Sprintf("StartQueued %v ends %v", e.Container.HumanReadable(), e.State))
+	}()
+	switch e.State {
+	case WantScanning:
+		if !e.IsQueued() {
+			panic(fmt.Sprintf("StartQueued called for entity with container in %v state", e.Container.Status.State))
+		}
+		e.State = ScannerStarting
+		e.QueuePosition = 0
+		tree.AddOrReplace(e)
+		return e, tree, []EntityAction{StartScanner}
+	default:
+		panic(fmt.Sprintf("StartQueued called for entity in %v state", e.State))
+	}
+}
+
 func (e Entity) Delete(tree Tree, delta container.ContainerDelta) (Entity, Tree, []EntityAction) {
 	dev.Debug(fmt.Sprintf("Delete %v starts %v", e.Container.HumanReadable(), e.State))
 	defer func() {
@@ -201,7 +283,7 @@ func (e Entity) Delete(tree Tree, delta container.ContainerDelta) (Entity, Tree,
 		tree.AddOrReplace(e)
 		return e, tree, []EntityAction{}
 	case ScannerStarting:
-		return e, tree, []EntityAction{RemoveEntity, StopScanner}
+		return e, tree, []EntityAction{RemoveEntity, CancelScannerStart}
 	case Scanning:
 		e.State = Deleted
 		e.Container.Status = delta.Container.Status
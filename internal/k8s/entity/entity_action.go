@@ -9,6 +9,7 @@ const (
 	RemoveEntity
 	RemoveLogs
 	MarkLogsTerminated
+	CancelScannerStart
 )
 
 func (a EntityAction) String() string {
@@ -25,6 +26,8 @@ func (a EntityAction) String() string {
 		return "RemoveLogs"
 	case MarkLogsTerminated:
 		return "MarkLogsTerminated"
+	case CancelScannerStart:
+		return "CancelScannerStart"
 	default:
 		return "Unknown"
 	}
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/robinovitch61/kl/internal/k8s/container"
+)
+
+// QueuePriority determines the order in which queued entities are given free selection slots
+type QueuePriority string
+
+const (
+	// QueueInTreeOrder starts queued entities in the order they appear in the entity tree
+	QueueInTreeOrder QueuePriority = ""
+
+	// QueueMostRecent starts queued entities with running containers before terminated ones,
+	// and the most recently started containers first
+	QueueMostRecent QueuePriority = "recent"
+)
+
+// ParseQueuePriority returns the QueuePriority with the given name
+func ParseQueuePriority(name string) (QueuePriority, error) {
+	switch QueuePriority(name) {
+	case QueueInTreeOrder, QueueMostRecent:
+		return QueuePriority(name), nil
+	default:
+		return QueueInTreeOrder, fmt.Errorf("invalid queue priority %q (valid options: '%s')", name, QueueMostRecent)
+	}
+}
+
+// SortByQueuePriority sorts entities in place such that the first entity should get the next free selection slot.
+// Entities that are equal given the priority keep their existing order
+func SortByQueuePriority(entities []Entity, priority QueuePriority) {
+	switch priority {
+	case QueueMostRecent:
+		sort.SliceStable(entities, func(i, j int) bool {
+			iRunning := entities[i].Container.Status.State == container.ContainerRunning
+			jRunning := entities[j].Container.Status.State == container.ContainerRunning
+			if iRunning != jRunning {
+				return iRunning
+			}
+			return entities[i].Container.Status.StartedAt.After(entities[j].Container.Status.StartedAt)
+		})
+	default:
+	}
+}
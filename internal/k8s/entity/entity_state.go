@@ -65,7 +65,7 @@ func (s EntityState) StatusIndicator() string {
 
 func (s EntityState) ActivatesWhenSelected() bool {
 	switch s {
-	case Scanning, WantScanning, Deleted:
+	case Scanning, WantScanning, ScannerStarting, Deleted:
 		return false
 	default:
 		return true
@@ -44,8 +44,9 @@ func newTestScanner() k8s_log.LogScanner {
 			Status:    container.ContainerStatus{State: container.ContainerRunning},
 		},
 		nil,
+		k8s_log.MultilineRules{},
+		k8s_log.TimestampRule{},
 		cancel,
-		nil,
 	)
 }
 
@@ -156,6 +157,17 @@ func TestDeactivate_FromWantScanning(t *testing.T) {
 	assertActions(t, actions, []entity.EntityAction{entity.RemoveLogs})
 }
 
+func TestDeactivate_FromScannerStarting(t *testing.T) {
+	tree := newTestTree()
+	ent := newTestEntity(entity.ScannerStarting, container.ContainerRunning)
+	tree.AddOrReplace(ent)
+
+	result, _, actions := ent.Deactivate(tree)
+
+	assertState(t, result, entity.Inactive)
+	assertActions(t, actions, []entity.EntityAction{entity.CancelScannerStart, entity.RemoveLogs})
+}
+
 func TestDeactivate_FromScanning(t *testing.T) {
 	tree := newTestTree()
 	ent := newTestEntity(entity.Scanning, container.ContainerRunning)
@@ -178,7 +190,7 @@ func TestDeactivate_FromDeleted(t *testing.T) {
 }
 
 func TestDeactivate_FromInvalidState_Panics(t *testing.T) {
-	for _, state := range []entity.EntityState{entity.Inactive, entity.ScannerStarting, entity.ScannerStopping} {
+	for _, state := range []entity.EntityState{entity.Inactive, entity.ScannerStopping} {
 		t.Run(state.String(), func(t *testing.T) {
 			tree := newTestTree()
 			ent := newTestEntity(state, container.ContainerRunning)
@@ -216,6 +228,130 @@ func TestRestart_FromInvalidState_Panics(t *testing.T) {
 	}
 }
 
+// --- Queue ---
+
+func TestQueue_FromScannerStarting(t *testing.T) {
+	tree := newTestTree()
+	ent := newTestEntity(entity.ScannerStarting, container.ContainerRunning)
+	tree.AddOrReplace(ent)
+
+	result, _, actions := ent.Queue(tree)
+
+	assertState(t, result, entity.WantScanning)
+	assertActions(t, actions, []entity.EntityAction{})
+	if !result.IsQueued() {
+		t.Error("expected entity to be queued")
+	}
+}
+
+func TestQueue_FromInvalidState_Panics(t *testing.T) {
+	for _, state := range []entity.EntityState{entity.Inactive, entity.WantScanning, entity.Scanning, entity.ScannerStopping, entity.Deleted} {
+		t.Run(state.String(), func(t *testing.T) {
+			tree := newTestTree()
+			ent := newTestEntity(state, container.ContainerRunning)
+			tree.AddOrReplace(ent)
+			assertPanics(t, fmt.Sprintf("Queue from %v", state), func() {
+				ent.Queue(tree)
+			})
+		})
+	}
+}
+
+// --- StartQueued ---
+
+func TestStartQueued_FromWantScanning(t *testing.T) {
+	tree := newTestTree()
+	ent := newTestEntity(entity.WantScanning, container.ContainerRunning)
+	ent.QueuePosition = 2
+	tree.AddOrReplace(ent)
+
+	result, _, actions := ent.StartQueued(tree)
+
+	assertState(t, result, entity.ScannerStarting)
+	assertActions(t, actions, []entity.EntityAction{entity.StartScanner})
+	if result.QueuePosition != 0 {
+		t.Errorf("expected queue position to be reset, got %d", result.QueuePosition)
+	}
+}
+
+func TestStartQueued_WaitingContainer_Panics(t *testing.T) {
+	tree := newTestTree()
+	ent := newTestEntity(entity.WantScanning, container.ContainerWaiting)
+	tree.AddOrReplace(ent)
+	if ent.IsQueued() {
+		t.Error("entity with waiting container should not be queued")
+	}
+	assertPanics(t, "StartQueued with waiting container", func() {
+		ent.StartQueued(tree)
+	})
+}
+
+func TestStartQueued_FromInvalidState_Panics(t *testing.T) {
+	for _, state := range []entity.EntityState{entity.Inactive, entity.ScannerStarting, entity.Scanning, entity.ScannerStopping, entity.Deleted} {
+		t.Run(state.String(), func(t *testing.T) {
+			tree := newTestTree()
+			ent := newTestEntity(state, container.ContainerRunning)
+			tree.AddOrReplace(ent)
+			assertPanics(t, fmt.Sprintf("StartQueued from %v", state), func() {
+				ent.StartQueued(tree)
+			})
+		})
+	}
+}
+
+func TestSortByQueuePriority(t *testing.T) {
+	now := time.Now()
+	newEnt := func(name string, state container.ContainerState, startedAt time.Time) entity.Entity {
+		ent := newTestEntity(entity.WantScanning, state)
+		ent.Container.Name = name
+		ent.Container.Status.StartedAt = startedAt
+		return ent
+	}
+	ents := func() []entity.Entity {
+		return []entity.Entity{
+			newEnt("old-terminated", container.ContainerTerminated, now.Add(-3*time.Hour)),
+			newEnt("old-running", container.ContainerRunning, now.Add(-2*time.Hour)),
+			newEnt("new-terminated", container.ContainerTerminated, now.Add(-time.Minute)),
+			newEnt("new-running", container.ContainerRunning, now.Add(-time.Hour)),
+		}
+	}
+	names := func(ents []entity.Entity) []string {
+		var res []string
+		for _, e := range ents {
+			res = append(res, e.Container.Name)
+		}
+		return res
+	}
+
+	tests := []struct {
+		priority entity.QueuePriority
+		expected []string
+	}{
+		{entity.QueueInTreeOrder, []string{"old-terminated", "old-running", "new-terminated", "new-running"}},
+		{entity.QueueMostRecent, []string{"new-running", "old-running", "new-terminated", "old-terminated"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.priority), func(t *testing.T) {
+			got := ents()
+			entity.SortByQueuePriority(got, tt.priority)
+			if fmt.Sprint(names(got)) != fmt.Sprint(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names(got))
+			}
+		})
+	}
+}
+
+func TestParseQueuePriority(t *testing.T) {
+	for _, name := range []string{"", "recent"} {
+		if _, err := entity.ParseQueuePriority(name); err != nil {
+			t.Errorf("unexpected error for %q: %v", name, err)
+		}
+	}
+	if _, err := entity.ParseQueuePriority("oldest"); err == nil {
+		t.Error("expected error for invalid priority")
+	}
+}
+
 // --- Delete ---
 
 func TestDelete_FromInactive(t *testing.T) {
@@ -257,17 +393,17 @@ func TestDelete_FromScannerStarting(t *testing.T) {
 		t.Fatalf("expected 2 actions, got %d: %v", len(actions), actions)
 	}
 	hasRemoveEntity := false
-	hasStopScanner := false
+	hasCancelScannerStart := false
 	for _, a := range actions {
 		if a == entity.RemoveEntity {
 			hasRemoveEntity = true
 		}
-		if a == entity.StopScanner {
-			hasStopScanner = true
+		if a == entity.CancelScannerStart {
+			hasCancelScannerStart = true
 		}
 	}
-	if !hasRemoveEntity || !hasStopScanner {
-		t.Errorf("expected RemoveEntity and StopScanner, got %v", actions)
+	if !hasRemoveEntity || !hasCancelScannerStart {
+		t.Errorf("expected RemoveEntity and CancelScannerStart, got %v", actions)
 	}
 	if tree.GetEntity(ent.Container) == nil {
 		t.Error("entity should still be in tree")
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/robinovitch61/kl/internal/k8s/container"
 	"github.com/robinovitch61/kl/internal/k8s/k8s_model"
 	"github.com/robinovitch61/kl/internal/util"
+	"github.com/robinovitch61/viewport/filterableviewport"
 )
 
 // Tree is a tree of entities with hierarchy Cluster > Namespace > PodOwner > Pod > Container
@@ -72,18 +74,20 @@ type entityNode struct {
 
 type isVisibleCache struct {
 	filter string
+	mode   filterableviewport.FilterModeName
 	cache  map[string]bool
 }
 
 func newIsVisibleCache(filter filter.Model) isVisibleCache {
 	return isVisibleCache{
 		filter: filter.Value(),
+		mode:   filter.ModeName(),
 		cache:  make(map[string]bool),
 	}
 }
 
 func (c isVisibleCache) ValidFor(filter filter.Model) bool {
-	return c.cache != nil && filter.Value() == c.filter
+	return c.cache != nil && filter.Value() == c.filter && filter.ModeName() == c.mode
 }
 
 func (c isVisibleCache) Contains(e Entity) (bool, bool) {
@@ -240,8 +244,32 @@ func (et *entityTreeImpl) addOrReplaceContainer(entity Entity, replace bool) {
 }
 
 func (et *entityTreeImpl) GetEntities() []Entity {
+	return et.orderedEntities(nil)
+}
+
+// orderedEntities returns the entities in tree order, with clusters and namespaces in the configured order and the
+// pod owners, pods and containers of each parent in descending order of rank if rank is set, otherwise by name
+func (et *entityTreeImpl) orderedEntities(rank func(node *entityNode) int) []Entity {
 	var result []Entity
 
+	sortedChildren := func(node *entityNode) []*entityNode {
+		ids := make([]string, 0, len(node.children))
+		for id := range node.children {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		children := make([]*entityNode, len(ids))
+		for i, id := range ids {
+			children[i] = node.children[id]
+		}
+		if rank != nil {
+			sort.SliceStable(children, func(i, j int) bool {
+				return rank(children[i]) > rank(children[j])
+			})
+		}
+		return children
+	}
+
 	for _, clusterNamespaces := range et.allClusterNamespaces {
 		if cluster, ok := et.root[clusterNamespaces.Cluster]; ok {
 			result = append(result, cluster.entity)
@@ -250,34 +278,13 @@ func (et *entityTreeImpl) GetEntities() []Entity {
 				if namespace, ok := cluster.children[namespaceID]; ok {
 					result = append(result, namespace.entity)
 
-					podOwners := make([]string, 0, len(namespace.children))
-					for podOwnerID := range namespace.children {
-						podOwners = append(podOwners, podOwnerID)
-					}
-					sort.Strings(podOwners)
-
-					for _, podOwnerID := range podOwners {
-						podOwner := namespace.children[podOwnerID]
+					for _, podOwner := range sortedChildren(namespace) {
 						result = append(result, podOwner.entity)
 
-						pods := make([]string, 0, len(podOwner.children))
-						for podID := range podOwner.children {
-							pods = append(pods, podID)
-						}
-						sort.Strings(pods)
-
-						for _, podID := range pods {
-							pod := podOwner.children[podID]
+						for _, pod := range sortedChildren(podOwner) {
 							result = append(result, pod.entity)
 
-							containers := make([]string, 0, len(pod.children))
-							for containerID := range pod.children {
-								containers = append(containers, containerID)
-							}
-							sort.Strings(containers)
-
-							for _, containerID := range containers {
-								container := pod.children[containerID]
+							for _, container := range sortedChildren(pod) {
 								result = append(result, container.entity)
 							}
 						}
@@ -290,8 +297,36 @@ func (et *entityTreeImpl) GetEntities() []Entity {
 	return result
 }
 
+// rankFunc returns a function ranking a node by the best score of it and its descendants given a ranking filter
+func rankFunc(filter filter.Model) func(node *entityNode) int {
+	ranks := make(map[*entityNode]int)
+	var rank func(node *entityNode) int
+	rank = func(node *entityNode) int {
+		if r, ok := ranks[node]; ok {
+			return r
+		}
+		best := math.MinInt
+		if score, ok := filter.Score(node.entity.Repr()); ok {
+			best = score
+		}
+		for _, child := range node.children {
+			best = max(best, rank(child))
+		}
+		ranks[node] = best
+		return best
+	}
+	return rank
+}
+
+// GetVisibleEntities returns the entities visible given the filter, ranked among their siblings if the filter ranks
+// matches, e.g. is fuzzy
 func (et entityTreeImpl) GetVisibleEntities(filter filter.Model) []Entity {
-	allEntities := et.GetEntities()
+	var allEntities []Entity
+	if filter.Ranks() {
+		allEntities = et.orderedEntities(rankFunc(filter))
+	} else {
+		allEntities = et.GetEntities()
+	}
 	visibleEntities := make([]Entity, 0)
 	for _, entity := range allEntities {
 		if et.IsVisibleGivenFilter(entity, filter) {
@@ -326,7 +361,7 @@ func (et *entityTreeImpl) IsVisibleGivenFilter(entity Entity, filter filter.Mode
 		et.isVisibleCache = newIsVisibleCache(filter)
 	}
 
-	if filter.Matches(entity.Repr()) {
+	if filter.MatchesFields(entity.Repr(), entity.FilterFields()) {
 		return et.isVisibleCache.SetAndReturn(entity, true)
 	}
 
@@ -341,7 +376,7 @@ func (et *entityTreeImpl) IsVisibleGivenFilter(entity Entity, filter filter.Mode
 
 	parent := et.getParentEntity(entity)
 	for !parent.EqualTo(Entity{}) {
-		if filter.Matches(parent.Repr()) {
+		if filter.MatchesFields(parent.Repr(), parent.FilterFields()) {
 			return et.isVisibleCache.SetAndReturn(entity, true)
 		}
 		parent = et.getParentEntity(parent)
@@ -432,7 +467,7 @@ func (et *entityTreeImpl) GetSelectionActions(selectedEntity Entity, filter filt
 			if !shouldActivate {
 				delete(actions, entity)
 			}
-		case WantScanning:
+		case WantScanning, ScannerStarting:
 			if shouldActivate {
 				delete(actions, entity)
 			}
@@ -350,6 +350,69 @@ func TestEntityTreeImpl_IsVisibleGivenFilter(t *testing.T) {
 	}
 }
 
+func TestEntityTreeImpl_IsVisibleGivenExprFilter(t *testing.T) {
+	tree := newTree()
+	tree.AddOrReplace(container1Cluster1)
+	tree.AddOrReplace(container2Cluster1)
+	tree.AddOrReplace(container1Cluster2)
+
+	tests := []struct {
+		name      string
+		expr      string
+		entity    entity.Entity
+		isVisible bool
+	}{
+		{"Field matches container", "container:container1", container1Cluster1, true},
+		{"Field hides other container", "container:container1", container2Cluster1, false},
+		{"Fields of ancestors match container", "cluster:cluster2 container:container1", container1Cluster2, true},
+		{"Fields of ancestors hide container", "cluster:cluster2 container:container1", container1Cluster1, false},
+		{"Regex field matches container", "pod:~^pod[12]$ AND container:~2$", container2Cluster1, true},
+		{"Negated field hides container", "NOT cluster:cluster1", container1Cluster1, false},
+		{"Negated field shows other cluster's container", "NOT cluster:cluster1", container1Cluster2, true},
+		{"Negated descendant field doesn't show ancestor of hidden containers", "NOT container:container1", cluster2, false},
+		{"Negated descendant field shows ancestor of shown containers", "NOT container:container1", cluster1, true},
+		{"Or of fields", "container:container2 OR cluster:cluster2", container1Cluster2, true},
+		{"Text term", "container2 OR nope", container2Cluster1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := filter.New(tt.expr, filter.ExprFilterMode(key.NewBinding(key.WithKeys("e")), nil))
+			got := tree.IsVisibleGivenFilter(tt.entity, f)
+			if got != tt.isVisible {
+				t.Errorf("IsVisibleGivenFilter() = %v, want %v", got, tt.isVisible)
+			}
+		})
+	}
+}
+
+func TestEntityTreeImpl_GetVisibleEntities_RanksFuzzyMatches(t *testing.T) {
+	tree := newTree()
+	weakMatch := entity.Entity{
+		Container: container.Container{Cluster: "cluster1", Namespace: "namespace1", PodOwner: "podOwner1", Pod: "pod1", Name: "payments-api-worker"},
+	}
+	strongMatch := entity.Entity{
+		Container: container.Container{Cluster: "cluster1", Namespace: "namespace1", PodOwner: "podOwner1", Pod: "pod1", Name: "payw-api"},
+	}
+	tree.AddOrReplace(weakMatch)
+	tree.AddOrReplace(strongMatch)
+	tree.AddOrReplace(container1Cluster1)
+
+	fuzzy := filter.New("payw", filterableviewport.FuzzyFilterMode(key.NewBinding(key.WithKeys("z"))))
+	got := tree.GetVisibleEntities(fuzzy)
+	expected := []entity.Entity{cluster1, namespace1, podOwner1, pod1, strongMatch, weakMatch}
+	if !entitiesEqual(got, expected) {
+		t.Errorf("GetVisibleEntities():\n%v\nWant\n%v", formatEntities(got), formatEntities(expected))
+	}
+
+	// without a fuzzy filter, containers are ordered by name
+	got = tree.GetVisibleEntities(newFilter("pay", false))
+	expected = []entity.Entity{cluster1, namespace1, podOwner1, pod1, weakMatch, strongMatch}
+	if !entitiesEqual(got, expected) {
+		t.Errorf("GetVisibleEntities():\n%v\nWant\n%v", formatEntities(got), formatEntities(expected))
+	}
+}
+
 func TestEntityTreeImpl_GetContainerEntities(t *testing.T) {
 	tree := newTree()
 	tree.AddOrReplace(container1Cluster1)
@@ -429,25 +492,25 @@ func TestEntityTreeImpl_GetSelectionActions(t *testing.T) {
 			name:            "Select pod1",
 			selectedEntity:  pod1,
 			filter:          emptyFilter,
-			expectedActions: 1,
+			expectedActions: 2, // deactivates both the scanning and the pending container
 		},
 		{
 			name:            "Select podOwner1",
 			selectedEntity:  podOwner1,
 			filter:          emptyFilter,
-			expectedActions: 1,
+			expectedActions: 2, // deactivates both the scanning and the pending container
 		},
 		{
 			name:            "Select namespace1",
 			selectedEntity:  namespace1,
 			filter:          emptyFilter,
-			expectedActions: 1,
+			expectedActions: 2, // deactivates both the scanning and the pending container
 		},
 		{
 			name:            "Select cluster1",
 			selectedEntity:  cluster1,
 			filter:          emptyFilter,
-			expectedActions: 1,
+			expectedActions: 2, // deactivates both the scanning and the pending container
 		},
 		{
 			name:            "Select cluster1 with container1 filter",
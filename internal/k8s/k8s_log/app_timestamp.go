@@ -0,0 +1,124 @@
+package k8s_log
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// appTimestampLayouts are the layouts tried when parsing an application's timestamp. Ones without a zone are in
+// local time
+var appTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05,999999999",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// TimestampRule extracts the time the application logged a line from its content, which can differ from the time
+// Kubernetes captured the line when the application buffers its output
+type TimestampRule struct {
+	// JSONField is the key of the timestamp in JSON logs, e.g. "ts". Dots separate the keys of nested objects, e.g.
+	// "meta.time"
+	JSONField string
+	// Pattern matches the timestamp in the content. If it has a capture group, the first group is the timestamp
+	Pattern *regexp.Regexp
+}
+
+func (r TimestampRule) enabled() bool {
+	return r.JSONField != "" || r.Pattern != nil
+}
+
+// Extract returns the application's timestamp in content, or false if it has none or it can't be parsed
+func (r TimestampRule) Extract(content string) (time.Time, bool) {
+	if r.JSONField != "" {
+		if t, ok := jsonTimestamp(content, r.JSONField); ok {
+			return t, true
+		}
+	}
+	if r.Pattern != nil {
+		match := r.Pattern.FindStringSubmatch(content)
+		switch {
+		case len(match) > 1:
+			return parseAppTimestamp(match[1])
+		case len(match) == 1:
+			return parseAppTimestamp(match[0])
+		}
+	}
+	return time.Time{}, false
+}
+
+func jsonTimestamp(content, field string) (time.Time, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(content), "{") {
+		return time.Time{}, false
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(content)))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return time.Time{}, false
+	}
+	for _, key := range strings.Split(field, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return time.Time{}, false
+		}
+		if value, ok = obj[key]; !ok {
+			return time.Time{}, false
+		}
+	}
+	switch v := value.(type) {
+	case string:
+		return parseAppTimestamp(v)
+	case json.Number:
+		return parseEpoch(string(v))
+	}
+	return time.Time{}, false
+}
+
+// parseAppTimestamp parses a timestamp in one of appTimestampLayouts, or as a unix epoch
+func parseAppTimestamp(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range appTimestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return parseEpoch(value)
+}
+
+// parseEpoch parses a unix epoch in seconds, milliseconds, microseconds or nanoseconds, telling them apart by
+// magnitude. Seconds can have a fraction
+func parseEpoch(value string) (time.Time, bool) {
+	whole, frac, hasFrac := strings.Cut(value, ".")
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+	switch {
+	case n < 1e11:
+		var nanos uint64
+		if hasFrac {
+			// parse exactly rather than as a float, keeping up to nanosecond precision
+			frac = (frac + "000000000")[:9]
+			if nanos, err = strconv.ParseUint(frac, 10, 64); err != nil {
+				return time.Time{}, false
+			}
+		}
+		return time.Unix(n, int64(nanos)), true
+	case hasFrac:
+		return time.Time{}, false
+	case n < 1e14:
+		return time.UnixMilli(n), true
+	case n < 1e17:
+		return time.UnixMicro(n), true
+	}
+	return time.Unix(0, n), true
+}
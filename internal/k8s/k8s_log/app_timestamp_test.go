@@ -0,0 +1,50 @@
+package k8s_log_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+)
+
+func TestTimestampRule_Extract(t *testing.T) {
+	expected := time.Date(2024, 1, 2, 10, 32, 15, 123000000, time.UTC)
+	tests := []struct {
+		name    string
+		rule    k8s_log.TimestampRule
+		content string
+		ok      bool
+	}{
+		{"json string", k8s_log.TimestampRule{JSONField: "ts"}, `{"ts":"2024-01-02T10:32:15.123Z","msg":"hi"}`, true},
+		{"json nested", k8s_log.TimestampRule{JSONField: "meta.time"}, `{"meta":{"time":"2024-01-02T10:32:15.123Z"}}`, true},
+		{"json epoch seconds", k8s_log.TimestampRule{JSONField: "ts"}, `{"ts":1704191535.123}`, true},
+		{"json epoch millis", k8s_log.TimestampRule{JSONField: "ts"}, `{"ts":1704191535123}`, true},
+		{"json epoch nanos", k8s_log.TimestampRule{JSONField: "ts"}, `{"ts":1704191535123000000}`, true},
+		{"json missing field", k8s_log.TimestampRule{JSONField: "ts"}, `{"time":"2024-01-02T10:32:15.123Z"}`, false},
+		{"json unparseable", k8s_log.TimestampRule{JSONField: "ts"}, `{"ts":"yesterday"}`, false},
+		{"not json", k8s_log.TimestampRule{JSONField: "ts"}, `ts=2024-01-02T10:32:15.123Z`, false},
+		{"regex group", k8s_log.TimestampRule{Pattern: regexp.MustCompile(`^\[([^\]]+)\]`)}, `[2024-01-02 10:32:15.123Z] hi`, true},
+		{"regex match", k8s_log.TimestampRule{Pattern: regexp.MustCompile(`\S+Z`)}, `at 2024-01-02T10:32:15.123Z hi`, true},
+		{"regex no match", k8s_log.TimestampRule{Pattern: regexp.MustCompile(`^\[([^\]]+)\]`)}, `hi`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.rule.Extract(tt.content)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if ok && !got.Equal(expected) {
+				t.Errorf("expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestTimestampRule_NoZoneIsLocal(t *testing.T) {
+	rule := k8s_log.TimestampRule{JSONField: "ts"}
+	got, ok := rule.Extract(`{"ts":"2024-01-02 10:32:15"}`)
+	if !ok || !got.Equal(time.Date(2024, 1, 2, 10, 32, 15, 0, time.Local)) {
+		t.Errorf("expected local time, got %v, %v", got, ok)
+	}
+}
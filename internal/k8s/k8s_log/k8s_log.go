@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"hash/fnv"
 	"strings"
 	"time"
 	"unicode"
@@ -19,37 +20,153 @@ import (
 type LogTimestamps struct {
 	Short string
 	Full  string
+	// App is the application's own timestamp formatted like Full, empty if it wasn't extracted
+	App string
 }
 
+// fullTimestampLayout formats full timestamps
+const fullTimestampLayout = "2006-01-02T15:04:05.000Z07:00"
+
 type Log struct {
-	Timestamp      time.Time
-	Timestamps     LogTimestamps
-	Container      container.Container
-	ContentItem    item.SingleItem
-	colorize       func(string) string // optional JSON colorizer
-	prettyItems    []item.SingleItem   // pretty-printed JSON lines, nil if not valid JSON or single item
+	// Timestamp is when Kubernetes captured the log
+	Timestamp time.Time
+	// AppTimestamp is when the application logged the log per its content, zero if not extracted
+	AppTimestamp time.Time
+	Timestamps   LogTimestamps
+	// Sequence is the position of the log in its log stream, used to keep the original order of logs with identical timestamps
+	Sequence  uint64
+	Container container.Container
+	// Level is detected from the content when the log is read
+	Level Level
+	// ContentItem is the log's raw content. Colorization is only applied when rendering
+	ContentItem item.SingleItem
+	rendered    *renderedContent // content as last rendered, nil until rendered
+	hashes      *contentHashes   // hashes of the content, nil until first needed
+}
+
+// contentHashes identify the log's content without keeping a copy of it, so repeated content can be detected
+// without comparing the content
+type contentHashes struct {
+	exact uint64
+	// masked ignores digits, so lines differing only in numbers like ids, counts or durations hash the same
+	masked uint64
+}
+
+// renderedContent caches the log's content as rendered with the given colors, so only logs that are rendered
+// are colorized, and are colorized again only if the colors change
+type renderedContent struct {
+	colors         *util.JSONColorStyles
+	item           *item.SingleItem
+	prettyItems    []item.SingleItem // pretty-printed lines, nil if not JSON or logfmt or single item
 	prettyComputed bool
+	lineItems      []item.SingleItem // lines of a multi-line record, nil if a single line
+	linesComputed  bool
+}
+
+// OrderTime returns the time logs are ordered by: the application's timestamp if extracted, else the time
+// Kubernetes captured the log
+func (l *Log) OrderTime() time.Time {
+	if !l.AppTimestamp.IsZero() {
+		return l.AppTimestamp
+	}
+	return l.Timestamp
+}
+
+// Item returns the log's content
+func (l *Log) Item() item.SingleItem {
+	return l.ContentItem
+}
+
+// ContentSize returns the size of the log's content in bytes
+func (l *Log) ContentSize() int {
+	return len(l.ContentItem.Content())
+}
+
+// ContentHash returns a hash of the log's content. If maskNumbers is true, each run of digits in the content hashes
+// the same regardless of its value
+func (l *Log) ContentHash(maskNumbers bool) uint64 {
+	if l.hashes == nil {
+		content := l.Item().Content()
+		exact := fnv.New64a()
+		_, _ = exact.Write([]byte(content))
+		masked := fnv.New64a()
+		inDigits := false
+		for i := 0; i < len(content); i++ {
+			isDigit := content[i] >= '0' && content[i] <= '9'
+			if !isDigit {
+				_, _ = masked.Write([]byte{content[i]})
+			} else if !inDigits {
+				_, _ = masked.Write([]byte{'#'})
+			}
+			inDigits = isDigit
+		}
+		l.hashes = &contentHashes{exact: exact.Sum64(), masked: masked.Sum64()}
+	}
+	if maskNumbers {
+		return l.hashes.masked
+	}
+	return l.hashes.exact
 }
 
-// Colorize returns the colorize function, or nil if no colorization is configured.
-func (l *Log) Colorize() func(string) string {
-	return l.colorize
+// ColorizedItem returns the log's content with JSON or logfmt colorized with colors, or the raw content if colors is nil.
+// The result is cached until colors changes
+func (l *Log) ColorizedItem(colors *util.JSONColorStyles) item.SingleItem {
+	if colors == nil {
+		return l.Item()
+	}
+	r := l.renderedWith(colors)
+	if r.item == nil {
+		content := l.Item()
+		if colorized := util.ColorizeStructured(content.Content(), *colors); colorized != content.Content() {
+			content = item.NewItem(colorized)
+		}
+		r.item = &content
+	}
+	return *r.item
+}
+
+// GetPrettyItems returns the pretty-printed JSON or logfmt lines for this log, colorized with colors if non-nil,
+// computing and caching the result on first access. Returns nil if the content doesn't pretty-print to multiple lines.
+func (l *Log) GetPrettyItems(colors *util.JSONColorStyles) []item.SingleItem {
+	r := l.renderedWith(colors)
+	if !r.prettyComputed {
+		if lines := util.PrettyPrintStructured(l.Item().ContentNoAnsi(), colors); len(lines) > 1 {
+			r.prettyItems = make([]item.SingleItem, len(lines))
+			for i, line := range lines {
+				r.prettyItems[i] = item.NewItem(line)
+			}
+		}
+		r.prettyComputed = true
+	}
+	return r.prettyItems
 }
 
-// GetPrettyItems returns the pretty-printed JSON lines for this log, computing
-// and caching the result on first access. Returns nil if the content is not
-// multi-line JSON.
-func (l *Log) GetPrettyItems() []item.SingleItem {
-	if !l.prettyComputed {
-		if lines := util.PrettyPrintJSON(l.ContentItem.ContentNoAnsi(), l.colorize); len(lines) > 1 {
-			l.prettyItems = make([]item.SingleItem, len(lines))
+// LineItems returns the lines of a log joined from multiple lines, like a stack trace, each colorized with colors if
+// non-nil, computing and caching the result on first access. Returns nil if the content is a single line.
+func (l *Log) LineItems(colors *util.JSONColorStyles) []item.SingleItem {
+	r := l.renderedWith(colors)
+	if !r.linesComputed {
+		if content := l.Item().Content(); strings.Contains(content, "\n") {
+			lines := strings.Split(content, "\n")
+			r.lineItems = make([]item.SingleItem, len(lines))
 			for i, line := range lines {
-				l.prettyItems[i] = item.NewItem(line)
+				if colors != nil {
+					line = util.ColorizeStructured(line, *colors)
+				}
+				r.lineItems[i] = item.NewItem(line)
 			}
 		}
-		l.prettyComputed = true
+		r.linesComputed = true
 	}
-	return l.prettyItems
+	return r.lineItems
+}
+
+// renderedWith returns the cached rendered content, discarding it if it was rendered with different colors
+func (l *Log) renderedWith(colors *util.JSONColorStyles) *renderedContent {
+	if l.rendered == nil || l.rendered.colors != colors {
+		l.rendered = &renderedContent{colors: colors}
+	}
+	return l.rendered
 }
 
 type LogScanner struct {
@@ -59,10 +176,17 @@ type LogScanner struct {
 	cancel         context.CancelFunc
 	uuid           string
 	logLineScanner *bufio.Scanner
-	colorize       func(string) string
+	multiline      MultilineRules
+	timestamps     TimestampRule
 }
 
-func NewLogScanner(ct container.Container, scanner *bufio.Scanner, cancelK8sStream context.CancelFunc, colorize func(string) string) LogScanner {
+func NewLogScanner(
+	ct container.Container,
+	scanner *bufio.Scanner,
+	multiline MultilineRules,
+	timestamps TimestampRule,
+	cancelK8sStream context.CancelFunc,
+) LogScanner {
 	return LogScanner{
 		Container:      ct,
 		LogChan:        make(chan Log, 1), // this value doesn't seem to affect performance much
@@ -70,13 +194,25 @@ func NewLogScanner(ct container.Container, scanner *bufio.Scanner, cancelK8sStre
 		cancel:         cancelK8sStream,
 		uuid:           uuid.New().String(),
 		logLineScanner: scanner,
-		colorize:       colorize,
+		multiline:      multiline,
+		timestamps:     timestamps,
 	}
 }
 
-// StartReadingLogs starts a goroutine that reads logs from the scanner and sends them to the LogChan
+// StartReadingLogs starts a goroutine that reads logs from the scanner and sends them to the LogChan, joining
+// lines into multi-line records if multi-line rules are enabled
 func (ls LogScanner) StartReadingLogs() {
 	go func() {
+		out := ls.LogChan
+		var joinDone chan struct{}
+		if ls.multiline.enabled() {
+			lines := make(chan Log)
+			joinDone = make(chan struct{})
+			go ls.joinLines(lines, joinDone)
+			out = lines
+		}
+
+		var sequence uint64
 		for ls.logLineScanner != nil && ls.logLineScanner.Scan() {
 			bs := ls.logLineScanner.Bytes()
 
@@ -100,24 +236,34 @@ func (ls LogScanner) StartReadingLogs() {
 			logContent = strings.ReplaceAll(logContent, "\t", "    ")
 			logContent = util.SanitizeTerminalSequences(logContent)
 
-			if ls.colorize != nil {
-				logContent = ls.colorize(logContent)
-			}
-
 			localTime := parsedTime.Local()
 
 			contentItem := item.NewItem(logContent)
 
-			ls.LogChan <- Log{
+			sequence++
+			log := Log{
 				Timestamp: parsedTime,
 				Timestamps: LogTimestamps{
 					Short: localTime.Format(time.TimeOnly),
-					Full:  localTime.Format("2006-01-02T15:04:05.000Z07:00"),
+					Full:  localTime.Format(fullTimestampLayout),
 				},
+				Sequence:    sequence,
 				Container:   ls.Container,
+				Level:       DetectLevel(logContent),
 				ContentItem: contentItem,
-				colorize:    ls.colorize,
 			}
+			if ls.timestamps.enabled() {
+				if appTime, ok := ls.timestamps.Extract(logContent); ok {
+					log.AppTimestamp = appTime
+					log.Timestamps.App = appTime.Local().Format(fullTimestampLayout)
+				}
+			}
+			out <- log
+		}
+
+		if joinDone != nil {
+			close(out)
+			<-joinDone
 		}
 
 		err := ls.logLineScanner.Err()
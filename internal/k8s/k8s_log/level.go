@@ -0,0 +1,214 @@
+package k8s_log
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Level is the severity of a log, detected from its content
+type Level uint8
+
+const (
+	LevelUnknown Level = iota
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// levelKeys are the JSON and logfmt keys whose values are log levels
+var levelKeys = []string{"level", "severity", "lvl"}
+
+func (lv Level) String() string {
+	switch lv {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Badge returns a fixed width label for the level, or an empty string if the level is unknown
+func (lv Level) Badge() string {
+	switch lv {
+	case LevelDebug:
+		return "DBG"
+	case LevelInfo:
+		return "INF"
+	case LevelWarn:
+		return "WRN"
+	case LevelError:
+		return "ERR"
+	default:
+		return ""
+	}
+}
+
+// AtLeast returns true if the level is threshold or more severe. Logs of unknown level are never below a threshold,
+// as they are often continuations of logs that have one, like stack traces
+func (lv Level) AtLeast(threshold Level) bool {
+	return lv == LevelUnknown || lv >= threshold
+}
+
+// ParseLevel returns the level named by value, case-insensitively, including common abbreviations and the
+// severities of syslog
+func ParseLevel(value string) Level {
+	switch strings.ToLower(value) {
+	case "trace", "debug", "dbg":
+		return LevelDebug
+	case "info", "inf", "information", "notice":
+		return LevelInfo
+	case "warn", "wrn", "warning":
+		return LevelWarn
+	case "error", "err", "fatal", "crit", "critical", "panic", "alert", "emerg", "emergency":
+		return LevelError
+	default:
+		return LevelUnknown
+	}
+}
+
+// parseNumericLevel returns the level of a numeric level as used by bunyan and pino, e.g. 30 for info
+func parseNumericLevel(value string) Level {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return LevelUnknown
+	}
+	switch {
+	case n < 10:
+		return LevelUnknown
+	case n < 30:
+		return LevelDebug
+	case n < 40:
+		return LevelInfo
+	case n < 50:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+// DetectLevel detects the level of a log from its content: a level field in JSON or logfmt, a klog header like
+// "E0612 10:32:15.123456", or a level word like "ERROR" or "[WARN]" among its first words
+func DetectLevel(content string) Level {
+	if strings.HasPrefix(content, "{") {
+		if lv := jsonLevel(content); lv != LevelUnknown {
+			return lv
+		}
+	}
+	if lv := klogLevel(content); lv != LevelUnknown {
+		return lv
+	}
+	if lv := logfmtLevel(content); lv != LevelUnknown {
+		return lv
+	}
+	return prefixLevel(content)
+}
+
+// jsonLevel returns the level of the first level key of a JSON object with a string or numeric value. The
+// content is scanned rather than parsed, as levels are detected for every log
+func jsonLevel(content string) Level {
+	for _, key := range levelKeys {
+		quotedKey := `"` + key + `"`
+		idx := strings.Index(content, quotedKey)
+		if idx < 0 {
+			continue
+		}
+		rest := strings.TrimLeft(content[idx+len(quotedKey):], " ")
+		if !strings.HasPrefix(rest, ":") {
+			continue
+		}
+		rest = strings.TrimLeft(rest[1:], " ")
+		if strings.HasPrefix(rest, `"`) {
+			if end := strings.IndexByte(rest[1:], '"'); end >= 0 {
+				return ParseLevel(rest[1 : end+1])
+			}
+			continue
+		}
+		end := strings.IndexAny(rest, ",} ")
+		if end < 0 {
+			end = len(rest)
+		}
+		return parseNumericLevel(rest[:end])
+	}
+	return LevelUnknown
+}
+
+// klogLevel returns the level of a log with a klog header, a level letter followed by the month and day
+func klogLevel(content string) Level {
+	if len(content) < 6 || content[5] != ' ' {
+		return LevelUnknown
+	}
+	for i := 1; i < 5; i++ {
+		if content[i] < '0' || content[i] > '9' {
+			return LevelUnknown
+		}
+	}
+	switch content[0] {
+	case 'I':
+		return LevelInfo
+	case 'W':
+		return LevelWarn
+	case 'E', 'F':
+		return LevelError
+	default:
+		return LevelUnknown
+	}
+}
+
+// logfmtLevel returns the value of the first level key in logfmt, e.g. level=info or lvl="warn"
+func logfmtLevel(content string) Level {
+	for _, key := range levelKeys {
+		pair := key + "="
+		idx := strings.Index(content, pair)
+		for idx > 0 && content[idx-1] != ' ' {
+			next := strings.Index(content[idx+1:], pair)
+			if next < 0 {
+				idx = -1
+				break
+			}
+			idx += next + 1
+		}
+		if idx < 0 {
+			continue
+		}
+		value := strings.TrimPrefix(content[idx+len(pair):], `"`)
+		if end := strings.IndexAny(value, `" `); end >= 0 {
+			value = value[:end]
+		}
+		return ParseLevel(value)
+	}
+	return LevelUnknown
+}
+
+// maxPrefixWords is how many words at the start of a log are checked for a level word, allowing for a date and time
+const maxPrefixWords = 4
+
+// prefixLevel returns the level of the first upper case level word, optionally in brackets or followed by a colon,
+// among the first words of a log, e.g. "ERROR", "[WARN]" or "INFO:"
+func prefixLevel(content string) Level {
+	rest := content
+	for range maxPrefixWords {
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" {
+			return LevelUnknown
+		}
+		end := strings.IndexByte(rest, ' ')
+		if end < 0 {
+			end = len(rest)
+		}
+		word := strings.Trim(rest[:end], "[]():")
+		if word != "" && word == strings.ToUpper(word) {
+			if lv := ParseLevel(word); lv != LevelUnknown {
+				return lv
+			}
+		}
+		rest = rest[end:]
+	}
+	return LevelUnknown
+}
@@ -0,0 +1,51 @@
+package k8s_log_test
+
+import (
+	"testing"
+
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+)
+
+func TestDetectLevel(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected k8s_log.Level
+	}{
+		{`{"level":"info","msg":"started"}`, k8s_log.LevelInfo},
+		{`{"msg":"slow", "severity": "WARNING"}`, k8s_log.LevelWarn},
+		{`{"lvl":"dbg","msg":"x"}`, k8s_log.LevelDebug},
+		{`{"level":50,"msg":"failed"}`, k8s_log.LevelError},
+		{`{"level":30}`, k8s_log.LevelInfo},
+		{`time=2024-01-02T10:00:00Z level=error msg="connection refused"`, k8s_log.LevelError},
+		{`ts=1 lvl="warn" msg=slow`, k8s_log.LevelWarn},
+		{`msg="toplevel=debug"`, k8s_log.LevelUnknown},
+		{`E0612 10:32:15.123456       1 controller.go:42] sync failed`, k8s_log.LevelError},
+		{`I0612 10:32:15.123456       1 controller.go:42] synced`, k8s_log.LevelInfo},
+		{`W0612 10:32:15.123456       1 controller.go:42] slow`, k8s_log.LevelWarn},
+		{`ERROR something broke`, k8s_log.LevelError},
+		{`[WARN] disk almost full`, k8s_log.LevelWarn},
+		{`2024-01-02 10:00:00 INFO: started`, k8s_log.LevelInfo},
+		{`2024-01-02 10:00:00,123 [main] DEBUG app - starting`, k8s_log.LevelDebug},
+		{`info about the thing`, k8s_log.LevelUnknown},
+		{`request from the client was an ERROR`, k8s_log.LevelUnknown},
+		{`    at com.example.Main.run(Main.java:10)`, k8s_log.LevelUnknown},
+		{``, k8s_log.LevelUnknown},
+	}
+	for _, tt := range tests {
+		if got := k8s_log.DetectLevel(tt.content); got != tt.expected {
+			t.Errorf("DetectLevel(%q): expected %v, got %v", tt.content, tt.expected, got)
+		}
+	}
+}
+
+func TestLevel_AtLeast(t *testing.T) {
+	if k8s_log.LevelInfo.AtLeast(k8s_log.LevelWarn) {
+		t.Error("expected info to be below warn")
+	}
+	if !k8s_log.LevelError.AtLeast(k8s_log.LevelWarn) {
+		t.Error("expected error to be at least warn")
+	}
+	if !k8s_log.LevelUnknown.AtLeast(k8s_log.LevelError) {
+		t.Error("expected unknown level never to be below a threshold")
+	}
+}
@@ -0,0 +1,163 @@
+package k8s_log
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+const (
+	// multilineFlushDelay is how long a record waits for more lines before it is sent. Lines of a stack trace are
+	// written together, so they arrive well within this
+	multilineFlushDelay = 100 * time.Millisecond
+	// maxRecordLines bounds the lines joined into a record, so a misbehaving rule can't hold logs back indefinitely
+	maxRecordLines = 1000
+)
+
+var (
+	// goFrame matches the function lines of a Go stack trace, e.g. "main.(*Server).handle(0xc000010000, ...)"
+	goFrame = regexp.MustCompile(`^[^\s(]+\(.*\)$`)
+	// pythonChained matches the lines between the tracebacks of chained Python exceptions
+	pythonChained = []string{
+		"During handling of the above exception, another exception occurred:",
+		"The above exception was the direct cause of the following exception:",
+	}
+)
+
+// MultilineRules decide which log lines continue the record started by an earlier line, like the frames of a stack
+// trace, so they are joined into a single log
+type MultilineRules struct {
+	// Enabled joins lines using built-in rules for Java, Python and Go stack traces: indented lines and the lines
+	// that follow the start of a Python traceback or Go panic continue the record
+	Enabled bool
+	// StartPattern, if set, matches the first line of every record, and replaces the built-in rules: every line it
+	// doesn't match continues the record
+	StartPattern *regexp.Regexp
+}
+
+func (r MultilineRules) enabled() bool {
+	return r.Enabled || r.StartPattern != nil
+}
+
+type recordKind int
+
+const (
+	recordGeneric recordKind = iota
+	recordPythonTraceback
+	recordGoPanic
+)
+
+// multilineRecord is a record that is still being joined
+type multilineRecord struct {
+	first Log
+	lines []string
+	kind  recordKind
+	// closed is true once a Python traceback's exception line arrives, which ends the traceback
+	closed bool
+}
+
+func newMultilineRecord(log Log) *multilineRecord {
+	content := log.ContentItem.Content()
+	rec := &multilineRecord{first: log, lines: []string{content}}
+	switch {
+	case content == "Traceback (most recent call last):":
+		rec.kind = recordPythonTraceback
+	case strings.HasPrefix(content, "panic: ") || strings.HasPrefix(content, "fatal error: "):
+		rec.kind = recordGoPanic
+	}
+	return rec
+}
+
+// log returns the record as a single log with the timestamp and level of its first line
+func (rec *multilineRecord) log() Log {
+	log := rec.first
+	if len(rec.lines) > 1 {
+		log.ContentItem = item.NewItem(strings.Join(rec.lines, "\n"))
+	}
+	return log
+}
+
+// continues returns true if line continues the record, updating the record's state
+func (r MultilineRules) continues(rec *multilineRecord, line string) bool {
+	if len(rec.lines) >= maxRecordLines {
+		return false
+	}
+	if r.StartPattern != nil {
+		return !r.StartPattern.MatchString(line)
+	}
+
+	if strings.HasPrefix(line, " ") {
+		return true
+	}
+	switch rec.kind {
+	case recordPythonTraceback:
+		switch {
+		case line == "Traceback (most recent call last):":
+			rec.closed = false
+			return true
+		case line == "" || isPythonChained(line):
+			return true
+		case !rec.closed:
+			// the unindented line after the frames is the exception, which ends the traceback
+			rec.closed = true
+			return true
+		}
+	case recordGoPanic:
+		if line == "" || goFrame.MatchString(line) || strings.HasPrefix(line, "goroutine ") ||
+			strings.HasPrefix(line, "created by ") || strings.HasPrefix(line, "[signal ") {
+			return true
+		}
+	}
+	// Java chains exceptions with unindented lines
+	return strings.HasPrefix(line, "Caused by: ")
+}
+
+func isPythonChained(line string) bool {
+	for _, chained := range pythonChained {
+		if line == chained {
+			return true
+		}
+	}
+	return false
+}
+
+// joinLines joins the logs from in into records sent to the log channel. A record is sent once a line that starts
+// a new record arrives, or once no line has arrived for multilineFlushDelay. Closes done once in is closed and
+// all records are sent
+func (ls LogScanner) joinLines(in <-chan Log, done chan<- struct{}) {
+	defer close(done)
+	var rec *multilineRecord
+	send := func() {
+		if rec != nil {
+			ls.LogChan <- rec.log()
+			rec = nil
+		}
+	}
+
+	flush := time.NewTimer(multilineFlushDelay)
+	flush.Stop()
+	for {
+		var flushC <-chan time.Time
+		if rec != nil {
+			flushC = flush.C
+		}
+		select {
+		case log, ok := <-in:
+			if !ok {
+				send()
+				return
+			}
+			if rec != nil && ls.multiline.continues(rec, log.ContentItem.Content()) {
+				rec.lines = append(rec.lines, log.ContentItem.Content())
+			} else {
+				send()
+				rec = newMultilineRecord(log)
+			}
+			flush.Reset(multilineFlushDelay)
+		case <-flushC:
+			send()
+		}
+	}
+}
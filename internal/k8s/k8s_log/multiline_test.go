@@ -0,0 +1,152 @@
+package k8s_log_test
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+)
+
+// scanLogs reads lines through a log scanner, returning the content of the resulting logs
+func scanLogs(t *testing.T, rules k8s_log.MultilineRules, lines ...string) []string {
+	t.Helper()
+	var stream strings.Builder
+	ts := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	for i, line := range lines {
+		stream.WriteString(ts.Add(time.Duration(i)*time.Millisecond).Format(time.RFC3339Nano) + " " + line + "\n")
+	}
+	scanner := bufio.NewScanner(strings.NewReader(stream.String()))
+	ls := k8s_log.NewLogScanner(container.Container{Name: "c"}, scanner, rules, k8s_log.TimestampRule{}, func() {})
+	ls.StartReadingLogs()
+
+	var contents []string
+	for log := range ls.LogChan {
+		contents = append(contents, log.Item().Content())
+	}
+	return contents
+}
+
+func assertContents(t *testing.T, got, expected []string) {
+	t.Helper()
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestMultiline_Disabled(t *testing.T) {
+	got := scanLogs(t, k8s_log.MultilineRules{}, "Exception in thread \"main\"", "\tat Main.main(Main.java:3)")
+	assertContents(t, got, []string{"Exception in thread \"main\"", "    at Main.main(Main.java:3)"})
+}
+
+func TestMultiline_Java(t *testing.T) {
+	got := scanLogs(t, k8s_log.MultilineRules{Enabled: true},
+		"starting",
+		"ERROR request failed",
+		"java.lang.IllegalStateException: boom",
+		"\tat com.example.Handler.handle(Handler.java:42)",
+		"Caused by: java.io.IOException: closed",
+		"\tat com.example.Conn.read(Conn.java:7)",
+		"\t... 3 more",
+		"next log",
+	)
+	assertContents(t, got, []string{
+		"starting",
+		"ERROR request failed",
+		strings.Join([]string{
+			"java.lang.IllegalStateException: boom",
+			"    at com.example.Handler.handle(Handler.java:42)",
+			"Caused by: java.io.IOException: closed",
+			"    at com.example.Conn.read(Conn.java:7)",
+			"    ... 3 more",
+		}, "\n"),
+		"next log",
+	})
+}
+
+func TestMultiline_Python(t *testing.T) {
+	got := scanLogs(t, k8s_log.MultilineRules{Enabled: true},
+		"Traceback (most recent call last):",
+		`  File "app.py", line 3, in <module>`,
+		"    main()",
+		"KeyError: 'a'",
+		"",
+		"During handling of the above exception, another exception occurred:",
+		"",
+		"Traceback (most recent call last):",
+		`  File "app.py", line 5, in <module>`,
+		"ValueError: b",
+		"next log",
+	)
+	assertContents(t, got, []string{
+		strings.Join([]string{
+			"Traceback (most recent call last):",
+			`  File "app.py", line 3, in <module>`,
+			"    main()",
+			"KeyError: 'a'",
+			"",
+			"During handling of the above exception, another exception occurred:",
+			"",
+			"Traceback (most recent call last):",
+			`  File "app.py", line 5, in <module>`,
+			"ValueError: b",
+		}, "\n"),
+		"next log",
+	})
+}
+
+func TestMultiline_Go(t *testing.T) {
+	got := scanLogs(t, k8s_log.MultilineRules{Enabled: true},
+		"panic: runtime error: index out of range [3] with length 2",
+		"",
+		"goroutine 1 [running]:",
+		"main.handle(0xc000010000, 0x2)",
+		"\t/app/main.go:12 +0x1d",
+		"created by main.main in goroutine 1",
+		"\t/app/main.go:20 +0x25",
+		"next log",
+	)
+	assertContents(t, got, []string{
+		strings.Join([]string{
+			"panic: runtime error: index out of range [3] with length 2",
+			"",
+			"goroutine 1 [running]:",
+			"main.handle(0xc000010000, 0x2)",
+			"    /app/main.go:12 +0x1d",
+			"created by main.main in goroutine 1",
+			"    /app/main.go:20 +0x25",
+		}, "\n"),
+		"next log",
+	})
+}
+
+func TestMultiline_StartPattern(t *testing.T) {
+	rules := k8s_log.MultilineRules{StartPattern: regexp.MustCompile(`^\[\d+\]`)}
+	got := scanLogs(t, rules, "[1] first", "unindented continuation", "[2] second", "[3] third", "more")
+	assertContents(t, got, []string{
+		"[1] first\nunindented continuation",
+		"[2] second",
+		"[3] third\nmore",
+	})
+}
+
+func TestMultiline_FlushesAfterDelay(t *testing.T) {
+	reader, writer := io.Pipe()
+	ls := k8s_log.NewLogScanner(container.Container{Name: "c"}, bufio.NewScanner(reader), k8s_log.MultilineRules{Enabled: true}, k8s_log.TimestampRule{}, func() {})
+	ls.StartReadingLogs()
+	defer func() { _ = writer.Close() }()
+
+	_, _ = writer.Write([]byte("2024-01-02T10:00:00Z first\n2024-01-02T10:00:00Z   continued\n"))
+	select {
+	case log := <-ls.LogChan:
+		if log.Item().Content() != "first\n  continued" {
+			t.Errorf("expected joined record, got %q", log.Item().Content())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected record to be sent without waiting for the next record")
+	}
+}
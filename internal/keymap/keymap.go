@@ -6,31 +6,47 @@ import (
 
 type KeyMap struct {
 	Clear                 key.Binding
+	CollapseRepeats       key.Binding
+	Columns               key.Binding
 	Copy                  key.Binding
 	Context               key.Binding
 	Enter                 key.Binding
+	Exclude               key.Binding
 	DeselectAll           key.Binding
 	Filter                key.Binding
+	FilterExpr            key.Binding
 	FilterFuzzy           key.Binding
+	FilterJQ              key.Binding
 	FilterRegex           key.Binding
 	FilterCaseInsensitive key.Binding
 	FilterNextRow         key.Binding
 	FilterPrevRow         key.Binding
 	Fullscreen            key.Binding
 	Help                  key.Binding
+	Highlights            key.Binding
+	JumpToTime            key.Binding
+	LevelThreshold        key.Binding
 	Logs                  key.Binding
 	LogsFullScreen        key.Binding
 	Name                  key.Binding
 	NextLog               key.Binding
+	Patterns              key.Binding
 	PrevLog               key.Binding
 	Quit                  key.Binding
+	RateLimit             key.Binding
 	ReverseOrder          key.Binding
 	Save                  key.Binding
 	Selection             key.Binding
 	SelectionFullScreen   key.Binding
 	SinceTime             key.Binding
+	Stats                 key.Binding
+	TimeBack              key.Binding
+	TimeForward           key.Binding
 	Timestamps            key.Binding
 	TogglePause           key.Binding
+	Volume                key.Binding
+	VolumeNext            key.Binding
+	VolumePrev            key.Binding
 	Wrap                  key.Binding
 	PrettyPrint           key.Binding
 
@@ -55,6 +71,14 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "discard filter"),
 		),
+		CollapseRepeats: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "collapse repeats off/exact/ignoring numbers"),
+		),
+		Columns: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "show fields as columns"),
+		),
 		Copy: key.NewBinding(
 			key.WithKeys("ctrl+y"),
 			key.WithHelp("ctrl+y", "copy zoomed log"),
@@ -67,6 +91,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("enter"),
 			key.WithHelp("enter", ""), // means different things on different pages
 		),
+		Exclude: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "exclude matching logs"),
+		),
 		DeselectAll: key.NewBinding(
 			key.WithKeys("R"),
 			key.WithHelp("R", "deselect all containers"),
@@ -75,10 +103,18 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("/"),
 			key.WithHelp("/", "edit filter"),
 		),
+		FilterExpr: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "expression filter"),
+		),
 		FilterFuzzy: key.NewBinding(
 			key.WithKeys("z"),
 			key.WithHelp("z", "fuzzy filter"),
 		),
+		FilterJQ: key.NewBinding(
+			key.WithKeys("J"),
+			key.WithHelp("J", "jq filter"),
+		),
 		FilterRegex: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("r", "regex filter"),
@@ -103,6 +139,18 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("?"),
 			key.WithHelp("?", "show/hide help"),
 		),
+		Highlights: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "manage highlights"),
+		),
+		JumpToTime: key.NewBinding(
+			key.WithKeys(":"),
+			key.WithHelp(":", "go to time"),
+		),
+		LevelThreshold: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "minimum level debug/info/warn/error"),
+		),
 		Logs: key.NewBinding(
 			key.WithKeys("l"),
 			key.WithHelp("l", "focus logs"),
@@ -119,6 +167,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("j", "down"),
 			key.WithHelp("↓/j", "next log"),
 		),
+		Patterns: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "show log patterns"),
+		),
 		PrevLog: key.NewBinding(
 			key.WithKeys("k", "up"),
 			key.WithHelp("↑/k", "previous log"),
@@ -127,6 +179,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("ctrl+c"),
 			key.WithHelp("ctrl+c", "quit"),
 		),
+		RateLimit: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "lift/restore rate limit for container"),
+		),
 		ReverseOrder: key.NewBinding(
 			key.WithKeys("o"),
 			key.WithHelp("o", "reverse timestamp order"),
@@ -147,6 +203,18 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("0", "1", "2", "3", "4", "5", "6", "7", "8", "9"),
 			key.WithHelp("0-9", "change log start time"),
 		),
+		Stats: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "show/hide throughput stats"),
+		),
+		TimeBack: key.NewBinding(
+			key.WithKeys("{"),
+			key.WithHelp("{", "jump back 1m"),
+		),
+		TimeForward: key.NewBinding(
+			key.WithKeys("}"),
+			key.WithHelp("}", "jump forward 1m"),
+		),
 		Timestamps: key.NewBinding(
 			key.WithKeys("t"),
 			key.WithHelp("t", "show short/full/no timestamps"),
@@ -155,6 +223,18 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("P"),
 			key.WithHelp("P", "pause/resume logs"),
 		),
+		Volume: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "show/hide log volume histogram"),
+		),
+		VolumeNext: key.NewBinding(
+			key.WithKeys("]"),
+			key.WithHelp("]", "jump to next volume bucket"),
+		),
+		VolumePrev: key.NewBinding(
+			key.WithKeys("["),
+			key.WithHelp("[", "jump to previous volume bucket"),
+		),
 		Wrap: key.NewBinding(
 			key.WithKeys("w"),
 			key.WithHelp("w", "toggle line wrap"),
@@ -244,10 +324,23 @@ func DescriptiveKeyBindings(km KeyMap) []key.Binding {
 		km.Timestamps,
 		km.Name,
 		km.ReverseOrder,
+		km.CollapseRepeats,
+		km.LevelThreshold,
+		km.Columns,
+		km.Highlights,
+		km.Volume,
+		km.VolumePrev,
+		km.VolumeNext,
+		km.JumpToTime,
+		km.TimeBack,
+		km.TimeForward,
 		km.Filter,
 		km.FilterFuzzy,
 		km.FilterRegex,
 		km.FilterCaseInsensitive,
+		km.FilterJQ,
+		km.FilterExpr,
+		km.Exclude,
 		km.Clear,
 		WithDesc(km.Enter, "apply filter"),
 		km.FilterNextRow,
@@ -263,7 +356,11 @@ func DescriptiveKeyBindings(km KeyMap) []key.Binding {
 		km.Bottom,
 		km.Save,
 		km.TogglePause,
+		km.RateLimit,
+		km.Stats,
 		WithDesc(km.Enter, "zoom on log"),
+		km.Patterns,
+		WithDesc(km.Enter, "filter logs by pattern"),
 		WithDesc(km.Clear, "back to all logs"),
 		km.Copy,
 		km.Quit,
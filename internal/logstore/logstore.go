@@ -0,0 +1,195 @@
+package logstore
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type segment struct {
+	file *os.File
+	w    *bufio.Writer
+	// first is the position of the segment's first record
+	first int
+	size  int64
+	// the index of the segment's records: when each starts, and the time and size each was appended with
+	offsets []int64
+	times   []int64
+	sizes   []int32
+}
+
+func (seg *segment) end() int {
+	return seg.first + len(seg.offsets)
+}
+
+// DiskStore stores records in append-only segment files in a session directory, so long sessions don't need to hold
+// all logs in memory. Records are numbered by their position in the order they were appended and indexed by time,
+// so they can be read back a page at a time. Records are dropped from the start, deleting each segment once all its
+// records are dropped. It is not safe for concurrent use
+type DiskStore struct {
+	dir             string
+	maxSegmentBytes int64
+	// segments are in the order they were written, the last being written to
+	segments []*segment
+	// first is the position of the first record kept, and end the position after the last
+	first, end int
+}
+
+// NewDiskStore creates a DiskStore in a new session directory inside parentDir, or inside the default
+// temporary directory if parentDir is empty
+func NewDiskStore(parentDir string, maxSegmentBytes int64) (*DiskStore, error) {
+	dir, err := os.MkdirTemp(parentDir, "kl-logs-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating log store directory: %w", err)
+	}
+	s := &DiskStore{dir: dir, maxSegmentBytes: maxSegmentBytes}
+	if err := s.openSegment(); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	return s, nil
+}
+
+// Dir returns the session directory of the store
+func (s *DiskStore) Dir() string {
+	return s.dir
+}
+
+// First returns the position of the first record kept
+func (s *DiskStore) First() int {
+	return s.first
+}
+
+// End returns the position the next record is appended at
+func (s *DiskStore) End() int {
+	return s.end
+}
+
+// Append appends a record indexed by ts, accounted for by size, starting a new segment if the active one is full
+func (s *DiskStore) Append(record []byte, ts time.Time, size int) error {
+	active := s.segments[len(s.segments)-1]
+	if active.size > 0 && active.size+int64(len(record)) > s.maxSegmentBytes {
+		if err := s.openSegment(); err != nil {
+			return err
+		}
+		active = s.segments[len(s.segments)-1]
+	}
+
+	n, err := active.w.Write(record)
+	if err != nil {
+		return fmt.Errorf("error writing to log store: %w", err)
+	}
+	active.offsets = append(active.offsets, active.size)
+	active.times = append(active.times, ts.UnixNano())
+	active.sizes = append(active.sizes, int32(size))
+	active.size += int64(n)
+	s.end++
+	return nil
+}
+
+// Time returns the time the record at pos was appended with
+func (s *DiskStore) Time(pos int) time.Time {
+	seg := s.segmentOf(pos)
+	return time.Unix(0, seg.times[pos-seg.first])
+}
+
+// Size returns the size the record at pos was appended with
+func (s *DiskStore) Size(pos int) int {
+	seg := s.segmentOf(pos)
+	return int(seg.sizes[pos-seg.first])
+}
+
+// Search returns the position of the first record kept appended with a time at or after ts, or End if there is none.
+// Records are assumed to be appended in time order
+func (s *DiskStore) Search(ts time.Time) int {
+	return s.first + sort.Search(s.end-s.first, func(i int) bool {
+		return !s.Time(s.first + i).Before(ts)
+	})
+}
+
+// Read returns the records from position from up to position to, reading each segment once
+func (s *DiskStore) Read(from, to int) ([][]byte, error) {
+	if from < s.first || to > s.end {
+		return nil, fmt.Errorf("log store positions %d to %d not kept", from, to)
+	}
+	records := make([][]byte, 0, to-from)
+	for pos := from; pos < to; {
+		seg := s.segmentOf(pos)
+		segTo := min(to, seg.end())
+		if err := seg.w.Flush(); err != nil {
+			return nil, fmt.Errorf("error writing to log store: %w", err)
+		}
+		start := seg.offsets[pos-seg.first]
+		stop := seg.size
+		if segTo < seg.end() {
+			stop = seg.offsets[segTo-seg.first]
+		}
+		buf := make([]byte, stop-start)
+		if _, err := seg.file.ReadAt(buf, start); err != nil {
+			return nil, fmt.Errorf("error reading from log store: %w", err)
+		}
+		for i := pos; i < segTo; i++ {
+			recordEnd := stop
+			if i+1 < segTo {
+				recordEnd = seg.offsets[i+1-seg.first]
+			}
+			records = append(records, buf[seg.offsets[i-seg.first]-start:recordEnd-start])
+		}
+		pos = segTo
+	}
+	return records, nil
+}
+
+// DropFirst drops the first record kept, deleting its segment if it was the segment's last
+func (s *DiskStore) DropFirst() {
+	if s.first == s.end {
+		return
+	}
+	s.first++
+	s.deleteDroppedSegments()
+}
+
+// Close closes and deletes all segments along with the session directory
+func (s *DiskStore) Close() error {
+	for _, seg := range s.segments {
+		_ = seg.file.Close()
+	}
+	s.segments = nil
+	return os.RemoveAll(s.dir)
+}
+
+// segmentOf returns the segment holding the record at pos
+func (s *DiskStore) segmentOf(pos int) *segment {
+	i := sort.Search(len(s.segments), func(i int) bool { return s.segments[i].end() > pos })
+	return s.segments[i]
+}
+
+func (s *DiskStore) openSegment() error {
+	name := filepath.Join(s.dir, fmt.Sprintf("segment-%06d.log", s.end))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("error creating log store segment: %w", err)
+	}
+	if n := len(s.segments); n > 0 {
+		// the previous segment is only read from now on
+		if err := s.segments[n-1].w.Flush(); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("error writing to log store: %w", err)
+		}
+	}
+	s.segments = append(s.segments, &segment{file: f, w: bufio.NewWriter(f), first: s.end})
+	s.deleteDroppedSegments()
+	return nil
+}
+
+// deleteDroppedSegments deletes the segments all of whose records were dropped, except the one being written to
+func (s *DiskStore) deleteDroppedSegments() {
+	for len(s.segments) > 1 && s.segments[0].end() <= s.first {
+		_ = s.segments[0].file.Close()
+		_ = os.Remove(s.segments[0].file.Name())
+		s.segments = s.segments[1:]
+	}
+}
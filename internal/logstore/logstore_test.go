@@ -0,0 +1,158 @@
+package logstore_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/logstore"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func numSegments(t *testing.T, s *logstore.DiskStore) int {
+	t.Helper()
+	entries, err := os.ReadDir(s.Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+func appendRecords(t *testing.T, s *logstore.DiskStore, records ...string) {
+	t.Helper()
+	for _, r := range records {
+		if err := s.Append([]byte(r), start.Add(time.Duration(s.End())*time.Second), len(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readRecords(t *testing.T, s *logstore.DiskStore, from, to int) []string {
+	t.Helper()
+	records, err := s.Read(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res []string
+	for _, r := range records {
+		res = append(res, string(r))
+	}
+	return res
+}
+
+func TestDiskStore_AppendRead(t *testing.T) {
+	// small segments, so reads span several
+	s, err := logstore.NewDiskStore(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+
+	records := []string{"first", "", "third line with \x1b[31mansi\x1b[0m", "4", "five"}
+	appendRecords(t, s, records...)
+	if s.First() != 0 || s.End() != len(records) {
+		t.Fatalf("expected positions 0 to %d, got %d to %d", len(records), s.First(), s.End())
+	}
+
+	for from := 0; from < len(records); from++ {
+		for to := from; to <= len(records); to++ {
+			got := readRecords(t, s, from, to)
+			if fmt.Sprint(got) != fmt.Sprint(records[from:to]) {
+				t.Errorf("reading %d to %d: expected %q, got %q", from, to, records[from:to], got)
+			}
+		}
+	}
+	if got := s.Size(2); got != len(records[2]) {
+		t.Errorf("expected size %d, got %d", len(records[2]), got)
+	}
+}
+
+func TestDiskStore_Search(t *testing.T) {
+	s, err := logstore.NewDiskStore(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	appendRecords(t, s, "a", "b", "c", "d")
+
+	tests := []struct {
+		ts       time.Time
+		expected int
+	}{
+		{start.Add(-time.Hour), 0},
+		{start, 0},
+		{start.Add(1500 * time.Millisecond), 2},
+		{start.Add(3 * time.Second), 3},
+		{start.Add(time.Hour), 4},
+	}
+	for _, tt := range tests {
+		if got := s.Search(tt.ts); got != tt.expected {
+			t.Errorf("searching %v: expected %d, got %d", tt.ts, tt.expected, got)
+		}
+	}
+
+	s.DropFirst()
+	if got := s.Search(start); got != 1 {
+		t.Errorf("expected dropped records not to be found, got %d", got)
+	}
+}
+
+func TestDiskStore_DroppedSegmentsAreDeleted(t *testing.T) {
+	s, err := logstore.NewDiskStore(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+
+	// each record fills a segment, so the next starts a new one
+	appendRecords(t, s, "0123456789", "abcdefghij", "ABCDEFGHIJ")
+	if numSegments(t, s) != 3 {
+		t.Fatalf("expected 3 segments, got %d", numSegments(t, s))
+	}
+
+	s.DropFirst()
+	if numSegments(t, s) != 2 {
+		t.Errorf("expected dropped segment to be deleted, got %d segments", numSegments(t, s))
+	}
+	if _, err := s.Read(0, 1); err == nil {
+		t.Error("expected error reading dropped record")
+	}
+	if got := readRecords(t, s, 1, 3); fmt.Sprint(got) != "[abcdefghij ABCDEFGHIJ]" {
+		t.Errorf("expected kept records, got %q", got)
+	}
+
+	// the segment being written to is kept even if all its records are dropped
+	s.DropFirst()
+	s.DropFirst()
+	if numSegments(t, s) != 1 {
+		t.Errorf("expected only the active segment to be kept, got %d segments", numSegments(t, s))
+	}
+	appendRecords(t, s, "next")
+	if got := readRecords(t, s, 3, 4); fmt.Sprint(got) != "[next]" {
+		t.Errorf("expected appended record, got %q", got)
+	}
+	if numSegments(t, s) != 1 {
+		t.Errorf("expected the fully dropped segment to be deleted, got %d segments", numSegments(t, s))
+	}
+}
+
+func TestDiskStore_CloseRemovesDir(t *testing.T) {
+	parent := t.TempDir()
+	s, err := logstore.NewDiskStore(parent, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(s.Dir()) != parent {
+		t.Errorf("expected store in %s, got %s", parent, s.Dir())
+	}
+	appendRecords(t, s, "content")
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(s.Dir()); !os.IsNotExist(err) {
+		t.Errorf("expected store dir to be removed, got %v", err)
+	}
+}
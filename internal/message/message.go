@@ -10,8 +10,6 @@ type BatchUpdateLogsMsg struct{}
 
 type StartMaintainEntitySelectionMsg struct{}
 
-type AttemptUpdateSinceTimeMsg struct{}
-
 type UpdateSinceTimeTextMsg struct {
 	UUID string
 }
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/robinovitch61/kl/internal/util"
+)
+
+const (
+	// maxColumnWidth bounds the width columns are padded to, so a few long values don't push the other columns far
+	// apart
+	maxColumnWidth  = 40
+	columnSeparator = "  "
+)
+
+// columnValueReplacer keeps each column value on a single line with no tabs, so values stay aligned and TSV stays valid
+var columnValueReplacer = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// ParseColumns parses comma-separated fields, e.g. "level, msg,http.status", ignoring empty fields
+func ParseColumns(value string) []string {
+	var columns []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			columns = append(columns, field)
+		}
+	}
+	return columns
+}
+
+// SetColumns shows the given fields of JSON and logfmt logs as aligned columns instead of their content, or the
+// content if columns is empty. Column widths start over and grow as logs are fit
+func (d *PageLogDisplay) SetColumns(columns []string) {
+	if len(columns) == 0 {
+		d.Columns, d.ColumnWidths = nil, nil
+		return
+	}
+	d.Columns = columns
+	d.ColumnWidths = make([]int, len(columns))
+}
+
+// FitColumns grows the column widths to fit the logs' values
+func (d *PageLogDisplay) FitColumns(logs []PageLog) {
+	if len(d.Columns) == 0 {
+		return
+	}
+	for _, l := range logs {
+		values, ok := l.columnValues()
+		if !ok {
+			continue
+		}
+		for i, value := range values {
+			d.ColumnWidths[i] = max(d.ColumnWidths[i], min(lipgloss.Width(value), maxColumnWidth))
+		}
+	}
+}
+
+// TSVHeader returns the header row of logs exported as TSV, naming the timestamp, container and column fields shown
+func (d PageLogDisplay) TSVHeader() string {
+	var names []string
+	if d.TimestampFormat != FormatNone {
+		names = append(names, "timestamp")
+	}
+	if d.NameFormat != FormatNone {
+		names = append(names, "container")
+	}
+	return strings.Join(append(names, d.Columns...), "\t")
+}
+
+// alignColumns pads each value but the last to the width of its column, without trailing padding when the last
+// values are missing
+func (d PageLogDisplay) alignColumns(values []string) string {
+	var b strings.Builder
+	for i, value := range values {
+		if i > 0 {
+			b.WriteString(columnSeparator)
+		}
+		b.WriteString(value)
+		if i < len(values)-1 {
+			b.WriteString(strings.Repeat(" ", max(d.ColumnWidths[i]-lipgloss.Width(value), 0)))
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// columnValues returns the values of the log's column fields, or false if columns aren't shown or the log has none
+// of the fields
+func (l PageLog) columnValues() ([]string, bool) {
+	if l.Display == nil || len(l.Display.Columns) == 0 {
+		return nil, false
+	}
+	values, ok := util.ExtractFields(l.Log.Item().ContentNoAnsi(), l.Display.Columns)
+	if !ok {
+		return nil, false
+	}
+	for i := range values {
+		values[i] = util.SanitizeTerminalSequences(columnValueReplacer.Replace(values[i]))
+	}
+	return values, true
+}
+
+// tsvRow returns the log's timestamp, container and column values separated by tabs, with the fields of
+// TSVHeader
+func (l PageLog) tsvRow(values []string) string {
+	var fields []string
+	if l.Display.TimestampFormat != FormatNone {
+		fields = append(fields, l.timestamp())
+	}
+	if l.Display.NameFormat != FormatNone {
+		fields = append(fields, l.RenderName(l.name(), false))
+	}
+	return strings.Join(append(fields, values...), "\t")
+}
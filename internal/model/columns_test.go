@@ -0,0 +1,64 @@
+package model_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/robinovitch61/kl/internal/k8s/k8s_model"
+	"github.com/robinovitch61/kl/internal/model"
+)
+
+func TestParseColumns(t *testing.T) {
+	if got := model.ParseColumns(" level, msg,,http.status "); !slices.Equal(got, []string{"level", "msg", "http.status"}) {
+		t.Errorf("expected trimmed fields, got %q", got)
+	}
+	if got := model.ParseColumns(" "); got != nil {
+		t.Errorf("expected no fields, got %q", got)
+	}
+}
+
+func TestColumns_AlignedAndRaw(t *testing.T) {
+	logs := []model.PageLog{
+		makePageLog(`{"level":"info","msg":"started","status":200}`, "", nil, false, nil),
+		makePageLog(`{"level":"warning","msg":"slow"}`, "", nil, false, nil),
+		makePageLog("plain text", "", nil, false, nil),
+	}
+	display := logs[0].Display
+	for i := range logs {
+		logs[i].Display = display
+	}
+	display.SetColumns([]string{"level", "msg", "status"})
+	display.FitColumns(logs)
+
+	expected := []string{
+		"info     started  200",
+		"warning  slow",
+		"plain text",
+	}
+	for i, l := range logs {
+		if got := l.ContentForFile(); got != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], got)
+		}
+	}
+
+	display.SetColumns(nil)
+	if got := logs[0].ContentForFile(); got != `{"level":"info","msg":"started","status":200}` {
+		t.Errorf("expected raw content without columns, got %q", got)
+	}
+}
+
+func TestColumns_TSV(t *testing.T) {
+	name := &k8s_model.ContainerNameAndPrefix{Prefix: "my-pod", ContainerName: "web"}
+	pl := makePageLog(`level=info msg="tab\there"`, "12:00:00", name, false, nil)
+	pl.Display.SetColumns([]string{"level", "msg"})
+	pl.Display.ColumnsTSV = true
+	if got := pl.Display.TSVHeader(); got != "timestamp\tcontainer\tlevel\tmsg" {
+		t.Errorf("unexpected header %q", got)
+	}
+	if got := pl.ContentForFile(); got != "12:00:00\tmy-pod/web\tinfo\ttab\\there" {
+		t.Errorf("unexpected row %q", got)
+	}
+	if got := pl.GetItem().ContentNoAnsi(); got != "12:00:00 my-pod/web info  tab\\there" {
+		t.Errorf("expected aligned columns when displayed, got %q", got)
+	}
+}
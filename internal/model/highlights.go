@@ -0,0 +1,78 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/robinovitch61/kl/internal/style"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+// HighlightRule highlights the text it matches in logs, regardless of the filter
+type HighlightRule struct {
+	// Text is the exact text highlighted, or a regex if wrapped in slashes like /trace_id=\w+/
+	Text  string
+	regex *regexp.Regexp
+}
+
+// NewHighlightRule creates a rule highlighting text, a regex if wrapped in slashes, otherwise exact text
+func NewHighlightRule(text string) (HighlightRule, error) {
+	pattern, ok := regexLiteral(text)
+	if !ok {
+		pattern = regexp.QuoteMeta(text)
+	}
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return HighlightRule{}, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	return HighlightRule{Text: text, regex: regex}, nil
+}
+
+// ParseHighlightRules parses a highlight rule from each text, e.g. "timeout" or "/trace_id=\w+/", ignoring empty texts
+func ParseHighlightRules(texts []string) ([]HighlightRule, error) {
+	var rules []HighlightRule
+	for _, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		rule, err := NewHighlightRule(text)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// highlight styles the text in s matched by each rule with the theme's style for the rule. Where matches of rules
+// overlap, the earlier rule's match is highlighted
+func highlight(s string, rules []HighlightRule, theme style.Theme) string {
+	if len(rules) == 0 {
+		return s
+	}
+	it := item.NewItem(s)
+	content := it.ContentNoAnsi()
+	var highlights []item.Highlight
+	for i, rule := range rules {
+		ruleStyle := theme.HighlightStyle(i)
+		for _, loc := range rule.regex.FindAllStringIndex(content, -1) {
+			r := item.ByteRange{Start: loc[0], End: loc[1]}
+			if r.Start == r.End || slices.ContainsFunc(highlights, func(h item.Highlight) bool {
+				return r.Start < h.ByteRangeUnstyledContent.End && h.ByteRangeUnstyledContent.Start < r.End
+			}) {
+				continue
+			}
+			highlights = append(highlights, item.Highlight{Style: ruleStyle, ByteRangeUnstyledContent: r})
+		}
+	}
+	if len(highlights) == 0 {
+		return s
+	}
+	slices.SortFunc(highlights, func(a, b item.Highlight) int {
+		return a.ByteRangeUnstyledContent.Start - b.ByteRangeUnstyledContent.Start
+	})
+	highlighted, _ := it.Take(0, it.Width(), "", highlights)
+	return highlighted
+}
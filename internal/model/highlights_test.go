@@ -0,0 +1,96 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/lipgloss/v2"
+	"github.com/robinovitch61/kl/internal/model"
+	"github.com/robinovitch61/kl/internal/style"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+func highlightedContent(t *testing.T, content string, texts ...string) string {
+	t.Helper()
+	theme := style.NoColorTheme()
+	theme.HighlightColors = []lipgloss.Style{lipgloss.NewStyle().Bold(true), lipgloss.NewStyle().Italic(true)}
+	l := makePageLog(content, "", nil, false, &theme)
+	for _, text := range texts {
+		rule, err := model.NewHighlightRule(text)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		l.Display.Highlights = append(l.Display.Highlights, rule)
+	}
+	if got := l.ContentForFile(); got != content {
+		t.Errorf("expected content saved to file without highlights, got %q", got)
+	}
+	return l.GetItem().Content()
+}
+
+func TestPageLog_Highlights(t *testing.T) {
+	content := "request trace_id=abc123 timeout after 30s (timeout)"
+	tests := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{"no rules", nil, content},
+		{"no matches", []string{"nope"}, content},
+		{
+			"each rule in its own style",
+			[]string{"timeout", `/trace_id=\w+/`},
+			"request \x1b[3mtrace_id=abc123\x1b[m \x1b[1mtimeout\x1b[m after 30s (\x1b[1mtimeout\x1b[m)",
+		},
+		{
+			"earlier rule wins overlaps",
+			[]string{"timeout", "/out after/", "30s"},
+			"request trace_id=abc123 \x1b[1mtimeout\x1b[m after \x1b[1m30s\x1b[m (\x1b[1mtimeout\x1b[m)",
+		},
+		{"exact text is not a regex", []string{"(timeout)"}, "request trace_id=abc123 timeout after 30s \x1b[1m(timeout)\x1b[m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightedContent(t, content, tt.texts...); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPageLog_HighlightsKeepJSONColors(t *testing.T) {
+	theme := style.NoColorTheme()
+	theme.HighlightColors = []lipgloss.Style{lipgloss.NewStyle().Bold(true)}
+	l := makePageLog(`{"msg":"timeout"}`, "", nil, false, &theme)
+	rule, err := model.NewHighlightRule("timeout")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l.Display.Highlights = []model.HighlightRule{rule}
+	if got := l.Highlight("\x1b[32mmsg\x1b[m timeout"); got != "\x1b[32mmsg\x1b[m \x1b[1mtimeout\x1b[m" {
+		t.Errorf("expected existing styles to be kept, got %q", got)
+	}
+	if got := item.NewItem(l.GetItem().Content()).ContentNoAnsi(); got != `{"msg":"timeout"}` {
+		t.Errorf("expected content to be unchanged, got %q", got)
+	}
+}
+
+func TestParseHighlightRules(t *testing.T) {
+	rules, err := model.ParseHighlightRules([]string{"timeout", "", `/a{1,3}/`, "slow, retrying"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules) != 3 || rules[0].Text != "timeout" || rules[1].Text != `/a{1,3}/` || rules[2].Text != "slow, retrying" {
+		t.Fatalf("expected a rule per non-empty text, got %v", rules)
+	}
+	theme := style.NoColorTheme()
+	theme.HighlightColors = []lipgloss.Style{lipgloss.NewStyle().Bold(true)}
+	l := makePageLog("", "", nil, false, &theme)
+	l.Display.Highlights = rules[1:2]
+	if got := l.Highlight("baaab"); got != "b\x1b[1maaa\x1b[mb" {
+		t.Errorf("expected regex with a comma to match, got %q", got)
+	}
+	if _, err := model.ParseHighlightRules([]string{"ok", "/[a-/"}); err == nil || !strings.Contains(err.Error(), "invalid regex") {
+		t.Errorf("expected invalid regex error, got %v", err)
+	}
+}
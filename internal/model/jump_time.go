@@ -0,0 +1,55 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// jumpTimeOfDayLayouts are times of day, on the day of the latest log
+	jumpTimeOfDayLayouts = []string{"15:04:05.999999999", "15:04"}
+	// jumpDateTimeLayouts are absolute times, in the local time zone unless they include one
+	jumpDateTimeLayouts = []string{
+		time.RFC3339Nano,
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04",
+		time.DateOnly,
+	}
+)
+
+// ParseJumpTime parses a time to jump to in the logs. It is either an offset from now, like "-5m" or "-1h30m", a
+// time of day, like "10:32" or "10:32:15", or a date and time, like "2024-01-02 10:32:15". A time of day is on the
+// day of the latest log, or the day before if that would be after the latest log, so a time just before midnight
+// finds logs from before midnight
+func ParseJumpTime(value string, now, latest time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		offset, err := time.ParseDuration(value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid offset %q, expected e.g. -5m", value)
+		}
+		return now.Add(offset), nil
+	}
+
+	for _, layout := range jumpTimeOfDayLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			latest = latest.Local()
+			y, m, d := latest.Date()
+			jumpTime := time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local)
+			if jumpTime.After(latest) {
+				jumpTime = jumpTime.AddDate(0, 0, -1)
+			}
+			return jumpTime, nil
+		}
+	}
+
+	for _, layout := range jumpDateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected e.g. -5m, 10:32:15 or 2024-01-02 10:32:15", value)
+}
@@ -0,0 +1,48 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/model"
+)
+
+func TestParseJumpTime(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 0, 0, 0, time.Local)
+	latest := time.Date(2024, 1, 2, 0, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{value: "-5m", expected: now.Add(-5 * time.Minute)},
+		{value: " -1h30m ", expected: now.Add(-90 * time.Minute)},
+		{value: "+1m", expected: now.Add(time.Minute)},
+		{value: "00:10:15", expected: time.Date(2024, 1, 2, 0, 10, 15, 0, time.Local)},
+		{value: "00:10:15.5", expected: time.Date(2024, 1, 2, 0, 10, 15, 500_000_000, time.Local)},
+		{value: "00:10", expected: time.Date(2024, 1, 2, 0, 10, 0, 0, time.Local)},
+		// a time of day after the latest log is on the day before
+		{value: "23:59", expected: time.Date(2024, 1, 1, 23, 59, 0, 0, time.Local)},
+		{value: "2023-12-31 10:32:15", expected: time.Date(2023, 12, 31, 10, 32, 15, 0, time.Local)},
+		{value: "2023-12-31T10:32", expected: time.Date(2023, 12, 31, 10, 32, 0, 0, time.Local)},
+		{value: "2023-12-31", expected: time.Date(2023, 12, 31, 0, 0, 0, 0, time.Local)},
+		{value: "2023-12-31T10:32:15Z", expected: time.Date(2023, 12, 31, 10, 32, 15, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := model.ParseJumpTime(tt.value, now, latest)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	for _, value := range []string{"", "yesterday", "-5", "25:00"} {
+		if _, err := model.ParseJumpTime(value, now, latest); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
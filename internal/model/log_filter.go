@@ -1,8 +1,44 @@
 package model
 
-import "github.com/robinovitch61/viewport/filterableviewport"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/robinovitch61/viewport/filterableviewport"
+)
 
 type LogFilter struct {
 	Value string
 	Mode  filterableviewport.FilterModeName
+	// Excludes hide the logs they match, stacking so several kinds of noise can be hidden at once
+	Excludes []LogFilter
+}
+
+// ParseLogExclusion parses the text of logs to exclude, a regex if wrapped in slashes like /health(z|check)/,
+// otherwise exact text
+func ParseLogExclusion(text string) (LogFilter, error) {
+	if pattern, ok := regexLiteral(text); ok {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return LogFilter{}, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+		return LogFilter{Value: pattern, Mode: filterableviewport.FilterRegex}, nil
+	}
+	return LogFilter{Value: text, Mode: filterableviewport.FilterExact}, nil
+}
+
+// regexLiteral returns the regex text is wrapped in slashes around, or false if it isn't
+func regexLiteral(text string) (string, bool) {
+	if len(text) > 2 && strings.HasPrefix(text, "/") && strings.HasSuffix(text, "/") {
+		return text[1 : len(text)-1], true
+	}
+	return "", false
+}
+
+// ExclusionText returns the text an exclusion is parsed from, its regex wrapped in slashes if it is one
+func (lf LogFilter) ExclusionText() string {
+	if lf.Mode == filterableviewport.FilterRegex {
+		return "/" + lf.Value + "/"
+	}
+	return lf.Value
 }
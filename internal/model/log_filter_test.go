@@ -0,0 +1,40 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/robinovitch61/kl/internal/model"
+	"github.com/robinovitch61/viewport/filterableviewport"
+)
+
+func TestParseLogExclusion(t *testing.T) {
+	tests := []struct {
+		text      string
+		wantValue string
+		wantMode  filterableviewport.FilterModeName
+	}{
+		{"healthz", "healthz", filterableviewport.FilterExact},
+		{"/GET /(healthz|readyz)/", "GET /(healthz|readyz)", filterableviewport.FilterRegex},
+		{"/healthz", "/healthz", filterableviewport.FilterExact},
+		{"//", "//", filterableviewport.FilterExact},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := model.ParseLogExclusion(tt.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Value != tt.wantValue || got.Mode != tt.wantMode {
+				t.Errorf("got %q %q, want %q %q", got.Value, got.Mode, tt.wantValue, tt.wantMode)
+			}
+			if got.ExclusionText() != tt.text {
+				t.Errorf("got text %q, want %q", got.ExclusionText(), tt.text)
+			}
+		})
+	}
+
+	if _, err := model.ParseLogExclusion("/[a-/"); err == nil || !strings.Contains(err.Error(), "invalid regex") {
+		t.Errorf("expected invalid regex error, got %v", err)
+	}
+}
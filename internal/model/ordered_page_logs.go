@@ -0,0 +1,150 @@
+package model
+
+import "sort"
+
+// orderedPageLogs is a sorted slice of logs with spare capacity at both ends, so logs can be added to or removed from
+// either end in amortized constant time. Logs mostly arrive in order, so most inserts are at one of the ends, and
+// inserts elsewhere only shift the logs on the nearer side.
+// Removed logs are left in the spare capacity rather than cleared, as slices of the logs handed out, e.g. to the
+// viewport, stay in use until they are replaced. They are released when the logs are next reallocated
+type orderedPageLogs struct {
+	buf        []PageLog
+	start, end int
+	compare    func(a, b PageLog) int
+}
+
+func newOrderedPageLogs(compare func(a, b PageLog) int) *orderedPageLogs {
+	return &orderedPageLogs{compare: compare}
+}
+
+func (o *orderedPageLogs) len() int {
+	return o.end - o.start
+}
+
+// logs returns the ordered logs without copying them
+func (o *orderedPageLogs) logs() []PageLog {
+	return o.buf[o.start:o.end]
+}
+
+func (o *orderedPageLogs) first() *PageLog {
+	if o.len() == 0 {
+		return nil
+	}
+	log := o.buf[o.start]
+	return &log
+}
+
+func (o *orderedPageLogs) last() *PageLog {
+	if o.len() == 0 {
+		return nil
+	}
+	log := o.buf[o.end-1]
+	return &log
+}
+
+// search returns the index of the first log that does not sort before log, and whether that log has the same key
+func (o *orderedPageLogs) search(log PageLog) (int, bool) {
+	logs := o.logs()
+	i := sort.Search(len(logs), func(i int) bool {
+		return o.compare(logs[i], log) >= 0
+	})
+	return i, i < len(logs) && o.compare(logs[i], log) == 0
+}
+
+// insert adds log in order. A log with the same key is replaced and returned
+func (o *orderedPageLogs) insert(log PageLog) (PageLog, bool) {
+	if last := o.last(); last == nil || o.compare(log, *last) > 0 {
+		o.pushBack(log)
+		return PageLog{}, false
+	}
+	if o.compare(log, *o.first()) < 0 {
+		o.pushFront(log)
+		return PageLog{}, false
+	}
+
+	i, found := o.search(log)
+	if found {
+		replaced := o.buf[o.start+i]
+		o.buf[o.start+i] = log
+		return replaced, true
+	}
+	if i < o.len()/2 {
+		if o.start == 0 {
+			o.grow()
+		}
+		copy(o.buf[o.start-1:], o.buf[o.start:o.start+i])
+		o.start--
+	} else {
+		if o.end == len(o.buf) {
+			o.grow()
+		}
+		copy(o.buf[o.start+i+1:], o.buf[o.start+i:o.end])
+		o.end++
+	}
+	o.buf[o.start+i] = log
+	return PageLog{}, false
+}
+
+func (o *orderedPageLogs) pushBack(log PageLog) {
+	if o.end == len(o.buf) {
+		o.grow()
+	}
+	o.buf[o.end] = log
+	o.end++
+}
+
+func (o *orderedPageLogs) pushFront(log PageLog) {
+	if o.start == 0 {
+		o.grow()
+	}
+	o.start--
+	o.buf[o.start] = log
+}
+
+// remove removes the log at index i of the ordered logs
+func (o *orderedPageLogs) remove(i int) {
+	if i < o.len()/2 {
+		copy(o.buf[o.start+1:], o.buf[o.start:o.start+i])
+		o.start++
+	} else {
+		copy(o.buf[o.start+i:], o.buf[o.start+i+1:o.end])
+		o.end--
+	}
+}
+
+// removeIf removes all logs for which shouldRemove returns true, returning the removed logs
+func (o *orderedPageLogs) removeIf(shouldRemove func(PageLog) bool) []PageLog {
+	var removed []PageLog
+	kept := o.start
+	for i := o.start; i < o.end; i++ {
+		if shouldRemove(o.buf[i]) {
+			removed = append(removed, o.buf[i])
+			continue
+		}
+		o.buf[kept] = o.buf[i]
+		kept++
+	}
+	o.end = kept
+	return removed
+}
+
+func (o *orderedPageLogs) reverse() {
+	for i, j := o.start, o.end-1; i < j; i, j = i+1, j-1 {
+		o.buf[i], o.buf[j] = o.buf[j], o.buf[i]
+	}
+}
+
+func (o *orderedPageLogs) clear() {
+	o.buf = nil
+	o.start, o.end = 0, 0
+}
+
+// grow reallocates with free capacity on both ends proportional to the number of logs
+func (o *orderedPageLogs) grow() {
+	n := o.len()
+	spare := n/2 + 8
+	buf := make([]PageLog, n+2*spare)
+	copy(buf[spare:], o.logs())
+	o.buf = buf
+	o.start, o.end = spare, spare+n
+}
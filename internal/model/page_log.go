@@ -1,31 +1,62 @@
 package model
 
 import (
+	"strings"
 	"time"
 
 	"charm.land/lipgloss/v2"
 	"github.com/emirpasic/gods/trees/redblacktree"
-	"github.com/robinovitch61/kl/internal/dev"
+	"github.com/robinovitch61/kl/internal/filter"
+	"github.com/robinovitch61/kl/internal/k8s/container"
 	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
 	"github.com/robinovitch61/kl/internal/k8s/k8s_model"
+	"github.com/robinovitch61/kl/internal/logstore"
 	"github.com/robinovitch61/kl/internal/style"
+	"github.com/robinovitch61/kl/internal/util"
 	"github.com/robinovitch61/viewport/viewport/item"
 )
 
+const (
+	FormatNone  = "none"
+	FormatShort = "short"
+	FormatFull  = "full"
+)
+
+// PageLogContainerNames are the names of a container. They can be shared by all logs from a run of the container,
+// so renaming or terminating the container updates all of its logs at once
 type PageLogContainerNames struct {
-	Short k8s_model.ContainerNameAndPrefix
-	Full  k8s_model.ContainerNameAndPrefix
+	Short      k8s_model.ContainerNameAndPrefix
+	Full       k8s_model.ContainerNameAndPrefix
+	Terminated bool
 }
 
-// PageLog is a Log with metadata. It has mostly pointer fields for efficient copying
+// PageLogDisplay determines how logs are rendered. It is shared by all logs in a page and read at render time,
+// so changing it doesn't require updating each log
+type PageLogDisplay struct {
+	TimestampFormat string
+	NameFormat      string
+	PrettyPrint     bool
+	// JSONColors colorizes JSON and logfmt content when rendered, nil for no colorization. Replacing it recolors all logs
+	JSONColors *util.JSONColorStyles
+	// Columns are the fields of JSON and logfmt logs shown as aligned columns instead of their content, nil to show
+	// the content. Logs with none of the fields show their content
+	Columns []string
+	// ColumnWidths are the widths columns are padded to, grown as logs with wider values are fit
+	ColumnWidths []int
+	// ColumnsTSV exports columns separated by tabs in ContentForFile, rather than aligned as displayed
+	ColumnsTSV bool
+	// Highlights are the rules highlighting the text they match in the logs' content, each in its own theme style
+	Highlights []HighlightRule
+}
+
+// PageLog is a Log with metadata. It has pointer fields for efficient copying
 type PageLog struct {
-	Log              *k8s_log.Log
-	ContainerNames   *PageLogContainerNames
-	CurrentName      *k8s_model.ContainerNameAndPrefix
-	CurrentTimestamp string
-	Terminated       bool
-	Theme            *style.Theme
-	PrettyPrinted    bool
+	Log            *k8s_log.Log
+	ContainerNames *PageLogContainerNames
+	Display        *PageLogDisplay
+	Theme          *style.Theme
+	// Repeats are the logs collapsed into this log's row when repeated lines are collapsed, nil otherwise
+	Repeats *PageLogRepeats
 }
 
 func (l PageLog) GetItem() item.Item {
@@ -34,57 +65,201 @@ func (l PageLog) GetItem() item.Item {
 
 // ContentForFile returns the log content without any ANSI escape codes, suitable for saving to a file.
 func (l PageLog) ContentForFile() string {
+	if l.Display != nil && l.Display.ColumnsTSV {
+		if values, ok := l.columnValues(); ok {
+			return item.NewItem(l.tsvRow(values)).ContentNoAnsi()
+		}
+	}
 	return l.getItem(false).ContentNoAnsi()
 }
 
+// FilterFields returns the fields filter expressions can qualify terms with, e.g. container:api or level:error
+func (l PageLog) FilterFields() filter.Fields {
+	c := l.Log.Container
+	return filter.Fields{
+		filter.FieldCluster:   c.Cluster,
+		filter.FieldNamespace: c.Namespace,
+		filter.FieldOwner:     c.PodOwner,
+		filter.FieldPod:       c.Pod,
+		filter.FieldContainer: c.Name,
+		filter.FieldLevel:     l.Log.Level.String(),
+	}
+}
+
 func (l PageLog) getItem(includeStyle bool) item.Item {
-	if l.PrettyPrinted {
-		if cached := l.Log.GetPrettyItems(); cached != nil {
-			prefix := l.renderPrefix(includeStyle)
-			segments := make([]item.SingleItem, len(cached))
-			if prefix != "" {
-				segments[0] = item.NewItem(prefix + cached[0].Content())
-			} else {
-				segments[0] = cached[0]
-			}
-			copy(segments[1:], cached[1:])
-			return item.NewMultiLineItem(segments...)
+	var colors *util.JSONColorStyles
+	if includeStyle && l.Display != nil {
+		colors = l.Display.JSONColors
+	}
+	if l.Display != nil && l.Display.PrettyPrint {
+		if cached := l.Log.GetPrettyItems(colors); cached != nil {
+			return l.multiLineItem(l.highlightedItems(cached, includeStyle), includeStyle)
 		}
 	}
+	if values, ok := l.columnValues(); ok {
+		return l.singleLineItem(l.highlightedItem(item.NewItem(l.Display.alignColumns(values)), includeStyle), includeStyle)
+	}
+	if lines := l.Log.LineItems(colors); lines != nil {
+		return l.multiLineItem(l.highlightedItems(lines, includeStyle), includeStyle)
+	}
+	return l.singleLineItem(l.highlightedItem(l.Log.ColorizedItem(colors), includeStyle), includeStyle)
+}
+
+// Highlight returns the rendered content s with the text matched by the display's highlight rules highlighted
+func (l PageLog) Highlight(s string) string {
+	if l.Display == nil || l.Theme == nil {
+		return s
+	}
+	return highlight(s, l.Display.Highlights, *l.Theme)
+}
+
+// highlightedItem returns the content item with the text matched by the display's highlight rules highlighted, if
+// styled
+func (l PageLog) highlightedItem(contentItem item.SingleItem, includeStyle bool) item.SingleItem {
+	if !includeStyle || l.Display == nil || len(l.Display.Highlights) == 0 {
+		return contentItem
+	}
+	return item.NewItem(l.Highlight(contentItem.Content()))
+}
+
+// highlightedItems is like highlightedItem for each line, copying the lines rather than changing cached ones
+func (l PageLog) highlightedItems(lines []item.SingleItem, includeStyle bool) []item.SingleItem {
+	if !includeStyle || l.Display == nil || len(l.Display.Highlights) == 0 {
+		return lines
+	}
+	highlighted := make([]item.SingleItem, len(lines))
+	for i, line := range lines {
+		highlighted[i] = l.highlightedItem(line, includeStyle)
+	}
+	return highlighted
+}
+
+// singleLineItem renders the log's content item with the prefix and suffix
+func (l PageLog) singleLineItem(contentItem item.SingleItem, includeStyle bool) item.Item {
 	prefix := l.renderPrefix(includeStyle)
+	if suffix := l.renderSuffix(includeStyle); suffix != "" {
+		if prefix == "" {
+			return item.NewConcat(contentItem, item.NewItem(suffix))
+		}
+		return item.NewConcat(item.NewItem(prefix), contentItem, item.NewItem(suffix))
+	}
 	if prefix == "" {
-		return l.Log.ContentItem
+		return contentItem
 	}
-	return item.NewConcat(item.NewItem(prefix), l.Log.ContentItem)
+	return item.NewConcat(item.NewItem(prefix), contentItem)
 }
 
-// renderPrefix returns the styled prefix (timestamp + container name + trailing space if needed)
+// multiLineItem renders the log's lines as one item, with the prefix on the first line and the suffix on the last
+func (l PageLog) multiLineItem(lines []item.SingleItem, includeStyle bool) item.Item {
+	segments := make([]item.SingleItem, len(lines))
+	copy(segments, lines)
+	if prefix := l.renderPrefix(includeStyle); prefix != "" {
+		segments[0] = item.NewItem(prefix + segments[0].Content())
+	}
+	if suffix := l.renderSuffix(includeStyle); suffix != "" {
+		last := len(segments) - 1
+		segments[last] = item.NewItem(segments[last].Content() + suffix)
+	}
+	return item.NewMultiLineItem(segments...)
+}
+
+// renderSuffix returns the styled suffix summarizing the logs collapsed into the log's row, if any
+func (l PageLog) renderSuffix(includeStyle bool) string {
+	suffix := l.repeatsSuffix()
+	if suffix == "" {
+		return ""
+	}
+	if includeStyle && l.Theme != nil {
+		suffix = l.Theme.TimestampPrefix.Render(suffix)
+	}
+	return " " + suffix
+}
+
+// renderPrefix returns the styled prefix (timestamp + container name + level badge + trailing space if needed)
 func (l PageLog) renderPrefix(includeStyle bool) string {
-	ts := ""
-	if l.CurrentTimestamp != "" {
-		if includeStyle && l.Theme != nil {
-			ts = l.Theme.TimestampPrefix.Render(l.CurrentTimestamp)
-		} else {
-			ts = l.CurrentTimestamp
-		}
+	ts := l.timestamp()
+	if ts != "" && includeStyle && l.Theme != nil {
+		ts = l.Theme.TimestampPrefix.Render(ts)
 	}
 	label := ""
-	if l.CurrentName != nil && l.CurrentName.ContainerName != "" {
+	if name := l.name(); name.ContainerName != "" {
 		if ts != "" {
 			label += " "
 		}
-		label += l.RenderName(*l.CurrentName, includeStyle)
+		label += l.RenderName(name, includeStyle)
+	}
+
+	if badge := l.Log.Level.Badge(); badge != "" {
+		if includeStyle && l.Theme != nil {
+			badge = levelStyle(*l.Theme, l.Log.Level).Render(badge)
+		}
+		if ts != "" || label != "" {
+			label += " "
+		}
+		label += badge
 	}
 
 	prefix := ts + label
 	if len(prefix) > 0 {
-		if l.Log.ContentItem.Content() != "" {
+		if l.Log.ContentSize() > 0 {
 			prefix = prefix + " "
 		}
 	}
 	return prefix
 }
 
+func levelStyle(theme style.Theme, level k8s_log.Level) lipgloss.Style {
+	switch level {
+	case k8s_log.LevelDebug:
+		return theme.LevelDebug
+	case k8s_log.LevelInfo:
+		return theme.LevelInfo
+	case k8s_log.LevelWarn:
+		return theme.LevelWarn
+	default:
+		return theme.LevelError
+	}
+}
+
+func (l PageLog) timestamp() string {
+	if l.Display == nil {
+		return ""
+	}
+	switch l.Display.TimestampFormat {
+	case FormatShort:
+		return l.Log.Timestamps.Short
+	case FormatFull:
+		return l.Log.Timestamps.Full
+	default:
+		return ""
+	}
+}
+
+func (l PageLog) name() k8s_model.ContainerNameAndPrefix {
+	var name k8s_model.ContainerNameAndPrefix
+	if l.Display == nil || l.ContainerNames == nil {
+		return name
+	}
+	switch l.Display.NameFormat {
+	case FormatShort:
+		name = l.ContainerNames.Short
+	case FormatFull:
+		name = l.ContainerNames.Full
+	}
+	if l.ContainerNames.Terminated && len(name.ContainerName) > 0 {
+		name.ContainerName += " [TERMINATED]"
+	}
+	return name
+}
+
+// JSONColors returns the colors JSON and logfmt are colorized with as the log is rendered, or nil if it is not colorized
+func (l PageLog) JSONColors() *util.JSONColorStyles {
+	if l.Display == nil {
+		return nil
+	}
+	return l.Display.JSONColors
+}
+
 func (l PageLog) Equals(other interface{}) bool {
 	otherLog, ok := other.(PageLog)
 	if !ok {
@@ -123,69 +298,334 @@ func (l PageLog) RenderName(name k8s_model.ContainerNameAndPrefix, includeStyle
 }
 
 type PageLogContainer struct {
-	allLogs   *redblacktree.Tree
+	logs      *orderedPageLogs
 	ascending bool
+
+	retention  RetentionPolicy
+	numBytes   int
+	numDropped int
+	// containerLogs holds each container's logs in ascending order, only tracked if retention has per-container limits
+	containerLogs map[string]*containerLogs
+
+	// numRemoved counts the logs removed from memory, dropped or moved to disk, and numRemovedNotOldest those of them
+	// that weren't the oldest log in memory when removed
+	numRemoved          int
+	numRemovedNotOldest int
+
+	// if diskStore is set, logs are also appended to it, and only the maxInMemory most recently appended are kept
+	// in memory along with those loaded back from it
+	diskStore   *logstore.DiskStore
+	maxInMemory int
+	// inMemory are the logs in memory in the order they were appended to the disk store, the first at position
+	// firstInMemory. Logs before it are only on disk, and logs removed since are left in place to keep positions
+	inMemory      []*k8s_log.Log
+	firstInMemory int
+	// numLoaded is how many of the first logs in inMemory were loaded back from disk, kept until UnloadOlder
+	numLoaded int
+	// removedContainers holds the disk store's end position when each container's logs were removed, so the
+	// container's logs before it are not loaded back
+	removedContainers map[string]int
 }
 
-func pageLogComparatorAsc(a, b interface{}) int {
-	e1 := a.(PageLog)
-	e2 := b.(PageLog)
+type containerLogs struct {
+	logs     *redblacktree.Tree
+	numBytes int
+}
+
+func comparePageLogs(e1, e2 PageLog) int {
 	switch {
-	case e1.Log.Timestamp.Before(e2.Log.Timestamp):
+	case e1.Log.OrderTime().Before(e2.Log.OrderTime()):
+		return -1
+	case e1.Log.OrderTime().After(e2.Log.OrderTime()):
+		return 1
+	// logs with identical timestamps must be ordered deterministically for logs not to overwrite each other.
+	// Order them as they were received from their log stream, then by container
+	case e1.Log.Sequence < e2.Log.Sequence:
 		return -1
-	case e1.Log.Timestamp.After(e2.Log.Timestamp):
+	case e1.Log.Sequence > e2.Log.Sequence:
 		return 1
 	default:
-		return 0
+		return strings.Compare(e1.Log.Container.ID(), e2.Log.Container.ID())
 	}
 }
 
-func pageLogComparatorDesc(a, b interface{}) int {
-	return -pageLogComparatorAsc(a, b)
+func comparePageLogsDesc(e1, e2 PageLog) int {
+	return -comparePageLogs(e1, e2)
+}
+
+func pageLogComparatorAsc(a, b interface{}) int {
+	return comparePageLogs(a.(PageLog), b.(PageLog))
 }
 
 func NewPageLogContainer(ascending bool) *PageLogContainer {
-	comparator := pageLogComparatorAsc
+	compare := comparePageLogs
 	if !ascending {
-		comparator = pageLogComparatorDesc
+		compare = comparePageLogsDesc
 	}
 	return &PageLogContainer{
-		allLogs:   redblacktree.NewWith(comparator),
+		logs:      newOrderedPageLogs(compare),
 		ascending: ascending,
 	}
 }
 
 func (lc PageLogContainer) Len() int {
-	return lc.allLogs.Size()
+	return lc.logs.len()
 }
 
-// LastTimestamp returns the timestamp of the last log in the current ordering.
-// In ascending mode this is the max timestamp; in descending mode the min.
-func (lc PageLogContainer) LastTimestamp() (time.Time, bool) {
-	node := lc.allLogs.Right()
-	if node == nil {
-		return time.Time{}, false
+// TimeRange returns the timestamps of the earliest and latest logs, or false if there are no logs
+func (lc PageLogContainer) TimeRange() (earliest, latest time.Time, ok bool) {
+	first, last := lc.logs.first(), lc.logs.last()
+	if first == nil || last == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	if lc.ascending {
+		return first.Log.OrderTime(), last.Log.OrderTime(), true
 	}
-	return node.Key.(PageLog).Log.Timestamp, true
+	return last.Log.OrderTime(), first.Log.OrderTime(), true
+}
+
+// SortsAfterLast returns true if the log would be placed after the last log in the current ordering,
+// or if there are no logs
+func (lc PageLogContainer) SortsAfterLast(log PageLog) bool {
+	last := lc.logs.last()
+	return last == nil || lc.logs.compare(log, *last) > 0
+}
+
+// OlderThanOldest returns true if the log is older than the oldest log kept, or if there are no logs
+func (lc PageLogContainer) OlderThanOldest(log PageLog) bool {
+	oldest := lc.oldest()
+	return oldest == nil || comparePageLogs(log, *oldest) < 0
+}
+
+// SetRetentionPolicy sets the policy that bounds the logs kept, dropping logs that exceed it
+func (lc *PageLogContainer) SetRetentionPolicy(retention RetentionPolicy) {
+	lc.retention = retention
+	lc.containerLogs = nil
+	if retention.hasPerContainerLimit() && lc.diskStore == nil {
+		lc.containerLogs = make(map[string]*containerLogs)
+		for _, log := range lc.logs.logs() {
+			lc.trackContainerLog(log)
+		}
+	}
+	lc.enforceRetention(time.Now(), nil)
+}
+
+// SetDiskStore appends all logs to store as they are added, keeping only the maxInMemory most recently added in
+// memory. Older logs are loaded back with LoadOlder or LoadSince. With a disk store, retention drops logs in the order
+// they were added, and per-container limits are not applied
+func (lc *PageLogContainer) SetDiskStore(store *logstore.DiskStore, maxInMemory int) {
+	lc.diskStore = store
+	lc.maxInMemory = maxInMemory
+	lc.inMemory = nil
+	lc.firstInMemory = store.End()
+	lc.numLoaded = 0
+	lc.removedContainers = make(map[string]int)
+	lc.containerLogs = nil
+	logs := lc.logs.logs()
+	for i := range logs {
+		if lc.ascending {
+			lc.appendToDisk(logs[i])
+		} else {
+			lc.appendToDisk(logs[len(logs)-1-i])
+		}
+	}
+	lc.moveOldToDisk()
+}
+
+// NumDropped returns the number of logs dropped due to the retention policy
+func (lc PageLogContainer) NumDropped() int {
+	return lc.numDropped
+}
+
+// NumRemoved returns the number of logs removed from memory, dropped due to the retention policy or moved to disk,
+// and how many of them weren't the oldest log in memory when removed
+func (lc PageLogContainer) NumRemoved() (removed, notOldest int) {
+	return lc.numRemoved, lc.numRemovedNotOldest
+}
+
+// DropExpired drops the logs older than the retention policy's MaxAge, as logs are otherwise only checked for their
+// age as logs are added
+func (lc *PageLogContainer) DropExpired(now time.Time) {
+	lc.dropExpired(now)
 }
 
 func (lc *PageLogContainer) AppendLog(log PageLog, _ interface{}) {
-	lc.allLogs.Put(log, nil)
+	// a log with an identical key replaces the existing one, so stop accounting for the existing one
+	if replaced, ok := lc.logs.insert(log); ok {
+		lc.untrack(replaced)
+	}
+	lc.numBytes += logSize(log)
+	if lc.containerLogs != nil {
+		lc.trackContainerLog(log)
+	}
+	if lc.diskStore != nil {
+		lc.appendToDisk(log)
+	}
+	lc.enforceRetention(time.Now(), &log)
+	lc.moveOldToDisk()
+}
+
+// indexOf returns the index of the log in memory, or false if it is not in memory
+func (lc PageLogContainer) indexOf(log *k8s_log.Log) (int, bool) {
+	i, found := lc.logs.search(PageLog{Log: log})
+	return i, found && lc.logs.logs()[i].Log == log
+}
+
+// remove removes the log at index i from memory
+func (lc *PageLogContainer) remove(i int) {
+	if (lc.ascending && i > 0) || (!lc.ascending && i < lc.logs.len()-1) {
+		lc.numRemovedNotOldest++
+	}
+	lc.numRemoved++
+	lc.logs.remove(i)
 }
 
 func (lc *PageLogContainer) RemoveAllLogs() {
-	lc.allLogs.Clear()
+	lc.logs.clear()
+	lc.numBytes = 0
+	if lc.diskStore != nil {
+		for lc.diskStore.First() < lc.diskStore.End() {
+			lc.diskStore.DropFirst()
+		}
+		lc.inMemory = nil
+		lc.firstInMemory = lc.diskStore.End()
+		lc.numLoaded = 0
+	}
+	if lc.containerLogs != nil {
+		lc.containerLogs = make(map[string]*containerLogs)
+	}
 }
 
-func (lc PageLogContainer) GetOrderedLogs() []PageLog {
-	var allLogs []PageLog
-	dev.Debug("iterating logs")
-	defer dev.Debug("done iterating logs")
-	allKeys := lc.allLogs.Keys()
-	for i := range allKeys {
-		allLogs = append(allLogs, allKeys[i].(PageLog))
+// RemoveLogsForContainer removes all logs of the container in a single pass, keeping the order of the rest
+func (lc *PageLogContainer) RemoveLogsForContainer(ct container.Container) {
+	removed := lc.logs.removeIf(func(log PageLog) bool {
+		return log.Log.Container.Equals(ct)
+	})
+	if lc.diskStore != nil {
+		// the logs stay on disk and accounted for until dropped, but are not loaded back
+		lc.removedContainers[ct.ID()] = lc.diskStore.End()
+	} else {
+		for _, log := range removed {
+			lc.numBytes -= logSize(log)
+		}
+	}
+	if lc.containerLogs != nil {
+		delete(lc.containerLogs, ct.ID())
+	}
+}
+
+// enforceRetention drops the oldest logs until the retention policy is satisfied. If added is non-nil,
+// only its container is checked against the per-container limits
+func (lc *PageLogContainer) enforceRetention(now time.Time, added *PageLog) {
+	if lc.diskStore != nil {
+		lc.enforceDiskRetention(now)
+		return
+	}
+	r := lc.retention
+	lc.dropExpired(now)
+	for r.MaxLines > 0 && lc.logs.len() > r.MaxLines {
+		lc.drop(*lc.oldest())
 	}
-	return allLogs
+	for r.MaxBytes > 0 && lc.numBytes > r.MaxBytes && lc.logs.len() > 0 {
+		lc.drop(*lc.oldest())
+	}
+
+	if lc.containerLogs == nil {
+		return
+	}
+	if added != nil {
+		lc.enforceContainerRetention(added.Log.Container.ID())
+		return
+	}
+	for id := range lc.containerLogs {
+		lc.enforceContainerRetention(id)
+	}
+}
+
+func (lc *PageLogContainer) dropExpired(now time.Time) {
+	if lc.retention.MaxAge <= 0 {
+		return
+	}
+	if lc.diskStore != nil {
+		lc.enforceDiskRetention(now)
+		return
+	}
+	cutoff := now.Add(-lc.retention.MaxAge)
+	for oldest := lc.oldest(); oldest != nil && oldest.Log.OrderTime().Before(cutoff); oldest = lc.oldest() {
+		lc.drop(*oldest)
+	}
+}
+
+func (lc *PageLogContainer) enforceContainerRetention(id string) {
+	r := lc.retention
+	for {
+		cl, ok := lc.containerLogs[id]
+		if !ok {
+			return
+		}
+		overLines := r.MaxLinesPerContainer > 0 && cl.logs.Size() > r.MaxLinesPerContainer
+		overBytes := r.MaxBytesPerContainer > 0 && cl.numBytes > r.MaxBytesPerContainer
+		if !overLines && !overBytes {
+			return
+		}
+		lc.drop(cl.logs.Left().Key.(PageLog))
+	}
+}
+
+// oldest returns the log with the earliest timestamp, or nil if there are no logs
+func (lc PageLogContainer) oldest() *PageLog {
+	if lc.ascending {
+		return lc.logs.first()
+	}
+	return lc.logs.last()
+}
+
+func (lc *PageLogContainer) drop(log PageLog) {
+	if i, found := lc.logs.search(log); found {
+		lc.remove(i)
+	}
+	lc.untrack(log)
+	lc.numDropped++
+}
+
+func (lc *PageLogContainer) untrack(log PageLog) {
+	if lc.diskStore == nil {
+		// with a disk store, logs are accounted for until dropped from it
+		lc.numBytes -= logSize(log)
+	}
+	if lc.containerLogs == nil {
+		return
+	}
+	id := log.Log.Container.ID()
+	if cl, ok := lc.containerLogs[id]; ok {
+		cl.logs.Remove(log)
+		cl.numBytes -= logSize(log)
+		if cl.logs.Size() == 0 {
+			delete(lc.containerLogs, id)
+		}
+	}
+}
+
+func (lc *PageLogContainer) trackContainerLog(log PageLog) {
+	id := log.Log.Container.ID()
+	cl, ok := lc.containerLogs[id]
+	if !ok {
+		cl = &containerLogs{logs: redblacktree.NewWith(pageLogComparatorAsc)}
+		lc.containerLogs[id] = cl
+	}
+	cl.logs.Put(log, nil)
+	cl.numBytes += logSize(log)
+}
+
+// logSize approximates the size of a log by the length of its content
+func logSize(log PageLog) int {
+	return log.Log.ContentSize()
+}
+
+// GetOrderedLogs returns the logs in the current order without copying them. The returned slice is only valid until
+// the container is next modified and must not be modified by the caller
+func (lc PageLogContainer) GetOrderedLogs() []PageLog {
+	return lc.logs.logs()
 }
 
 func (lc PageLogContainer) Ascending() bool {
@@ -193,10 +633,11 @@ func (lc PageLogContainer) Ascending() bool {
 }
 
 func (lc *PageLogContainer) ToggleAscending() {
+	lc.logs.reverse()
 	if lc.ascending {
-		lc.allLogs.Comparator = pageLogComparatorDesc
+		lc.logs.compare = comparePageLogsDesc
 	} else {
-		lc.allLogs.Comparator = pageLogComparatorAsc
+		lc.logs.compare = comparePageLogs
 	}
 	lc.ascending = !lc.ascending
 }
@@ -0,0 +1,232 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/dev"
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+// storedLog is a log as appended to the disk store
+type storedLog struct {
+	Timestamp    time.Time
+	AppTimestamp time.Time
+	Timestamps   k8s_log.LogTimestamps
+	Sequence     uint64
+	Container    container.Container
+	Level        k8s_log.Level
+	Content      string
+	Names        PageLogContainerNames
+}
+
+func encodeLog(log PageLog) ([]byte, error) {
+	stored := storedLog{
+		Timestamp:    log.Log.Timestamp,
+		AppTimestamp: log.Log.AppTimestamp,
+		Timestamps:   log.Log.Timestamps,
+		Sequence:     log.Log.Sequence,
+		Container:    log.Log.Container,
+		Level:        log.Log.Level,
+		Content:      log.Log.ContentItem.Content(),
+	}
+	if log.ContainerNames != nil {
+		stored.Names = *log.ContainerNames
+	}
+	return json.Marshal(stored)
+}
+
+// decodeLog returns the log stored in record, with its own container names and nothing else set
+func decodeLog(record []byte) (PageLog, error) {
+	var stored storedLog
+	if err := json.Unmarshal(record, &stored); err != nil {
+		return PageLog{}, fmt.Errorf("error reading log from disk: %w", err)
+	}
+	return PageLog{
+		Log: &k8s_log.Log{
+			Timestamp:    stored.Timestamp,
+			AppTimestamp: stored.AppTimestamp,
+			Timestamps:   stored.Timestamps,
+			Sequence:     stored.Sequence,
+			Container:    stored.Container,
+			Level:        stored.Level,
+			ContentItem:  item.NewItem(stored.Content),
+		},
+		ContainerNames: &stored.Names,
+	}, nil
+}
+
+// appendToDisk appends the log to the disk store, leaving it in memory if it can't be
+func (lc *PageLogContainer) appendToDisk(log PageLog) {
+	record, err := encodeLog(log)
+	if err == nil {
+		err = lc.diskStore.Append(record, log.Log.OrderTime(), logSize(log))
+	}
+	if err != nil {
+		dev.Debug(fmt.Sprintf("error appending log to disk, keeping in memory: %v", err))
+		return
+	}
+	lc.inMemory = append(lc.inMemory, log.Log)
+}
+
+// moveOldToDisk removes the least recently added logs from memory while more than maxInMemory are in memory,
+// unless logs were loaded back from disk, as the logs in memory must follow on from those on disk
+func (lc *PageLogContainer) moveOldToDisk() {
+	if lc.diskStore == nil || lc.numLoaded > 0 {
+		return
+	}
+	for len(lc.inMemory) > lc.maxInMemory {
+		oldest := lc.inMemory[0]
+		lc.inMemory[0] = nil
+		lc.inMemory = lc.inMemory[1:]
+		lc.firstInMemory++
+		if oldest == nil {
+			continue
+		}
+		if i, ok := lc.indexOf(oldest); ok {
+			lc.remove(i)
+		}
+	}
+}
+
+// enforceDiskRetention drops logs in the order they were added to the disk store until the retention policy is
+// satisfied, whether they are in memory or only on disk
+func (lc *PageLogContainer) enforceDiskRetention(now time.Time) {
+	r := lc.retention
+	s := lc.diskStore
+	for s.First() < s.End() {
+		expired := r.MaxAge > 0 && s.Time(s.First()).Before(now.Add(-r.MaxAge))
+		overLines := r.MaxLines > 0 && lc.firstInMemory-s.First()+lc.logs.len() > r.MaxLines
+		overBytes := r.MaxBytes > 0 && lc.numBytes > r.MaxBytes
+		if !expired && !overLines && !overBytes {
+			return
+		}
+		lc.numBytes -= s.Size(s.First())
+		lc.numDropped++
+		if s.First() == lc.firstInMemory {
+			log := lc.inMemory[0]
+			lc.inMemory[0] = nil
+			lc.inMemory = lc.inMemory[1:]
+			lc.firstInMemory++
+			lc.numLoaded = max(lc.numLoaded-1, 0)
+			if log != nil {
+				if i, ok := lc.indexOf(log); ok {
+					lc.remove(i)
+				}
+			}
+		}
+		s.DropFirst()
+	}
+}
+
+// NumOlderOnDisk returns the number of logs older than those in memory that are only on disk
+func (lc PageLogContainer) NumOlderOnDisk() int {
+	if lc.diskStore == nil {
+		return 0
+	}
+	return lc.firstInMemory - lc.diskStore.First()
+}
+
+// LoadOlder loads up to n of the logs only on disk that were added just before those in memory back into memory.
+// Until UnloadOlder, no logs are moved to disk. Each log loaded is passed to prepare first. It returns the number loaded
+func (lc *PageLogContainer) LoadOlder(n int, prepare func(PageLog) PageLog) (int, error) {
+	if lc.NumOlderOnDisk() == 0 {
+		return 0, nil
+	}
+	return lc.load(max(lc.diskStore.First(), lc.firstInMemory-n), prepare)
+}
+
+// LoadSince loads the logs only on disk that were added from the first at or after t back into memory like LoadOlder
+func (lc *PageLogContainer) LoadSince(t time.Time, prepare func(PageLog) PageLog) (int, error) {
+	if lc.NumOlderOnDisk() == 0 {
+		return 0, nil
+	}
+	from := lc.diskStore.Search(t)
+	if from >= lc.firstInMemory {
+		return 0, nil
+	}
+	return lc.load(from, prepare)
+}
+
+// UnloadOlder moves the logs loaded back from disk, and any added since beyond maxInMemory, to disk again
+func (lc *PageLogContainer) UnloadOlder() {
+	lc.numLoaded = 0
+	lc.moveOldToDisk()
+}
+
+// NumLoaded returns the number of logs loaded back from disk that are kept in memory
+func (lc PageLogContainer) NumLoaded() int {
+	return lc.numLoaded
+}
+
+// load loads the logs on disk from position from up to those in memory into memory
+func (lc *PageLogContainer) load(from int, prepare func(PageLog) PageLog) (int, error) {
+	logs, err := lc.readFromDisk(from, lc.firstInMemory)
+	if err != nil {
+		return 0, err
+	}
+	loaded := make([]*k8s_log.Log, len(logs))
+	// the logs loaded are mostly older than those in memory, so adding the newest first adds each at the oldest end
+	for i := len(logs) - 1; i >= 0; i-- {
+		if logs[i].Log == nil {
+			continue
+		}
+		log := prepare(logs[i])
+		lc.logs.insert(log)
+		loaded[i] = log.Log
+	}
+	lc.inMemory = append(loaded, lc.inMemory...)
+	lc.numLoaded += lc.firstInMemory - from
+	lc.firstInMemory = from
+	return len(logs), nil
+}
+
+// readFromDisk returns the logs on disk from position from up to position to, leaving those of removed containers
+// empty so the rest stay at their positions
+func (lc PageLogContainer) readFromDisk(from, to int) ([]PageLog, error) {
+	records, err := lc.diskStore.Read(from, to)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]PageLog, len(records))
+	for i, record := range records {
+		log, err := decodeLog(record)
+		if err != nil {
+			return nil, err
+		}
+		if removedAt, ok := lc.removedContainers[log.Log.Container.ID()]; ok && from+i < removedAt {
+			continue
+		}
+		logs[i] = log
+	}
+	return logs, nil
+}
+
+// EachOlderOnDisk calls f with each log only on disk in order, oldest first, reading pageSize logs at a time
+func (lc PageLogContainer) EachOlderOnDisk(pageSize int, f func(PageLog)) error {
+	if lc.NumOlderOnDisk() == 0 {
+		return nil
+	}
+	for from := lc.diskStore.First(); from < lc.firstInMemory; from += pageSize {
+		logs, err := lc.readFromDisk(from, min(from+pageSize, lc.firstInMemory))
+		if err != nil {
+			return err
+		}
+		var kept []PageLog
+		for _, log := range logs {
+			if log.Log != nil {
+				kept = append(kept, log)
+			}
+		}
+		// logs are added to the disk store mostly, but not strictly, in order
+		sort.SliceStable(kept, func(i, j int) bool { return comparePageLogs(kept[i], kept[j]) < 0 })
+		for _, log := range kept {
+			f(log)
+		}
+	}
+	return nil
+}
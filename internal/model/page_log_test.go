@@ -1,13 +1,21 @@
 package model_test
 
 import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
+	"charm.land/lipgloss/v2"
+	"github.com/robinovitch61/kl/internal/k8s/container"
 	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
 	"github.com/robinovitch61/kl/internal/k8s/k8s_model"
+	"github.com/robinovitch61/kl/internal/logstore"
 	"github.com/robinovitch61/kl/internal/model"
 	"github.com/robinovitch61/kl/internal/style"
+	"github.com/robinovitch61/kl/internal/util"
 	"github.com/robinovitch61/viewport/viewport/item"
 )
 
@@ -15,20 +23,28 @@ func makePageLog(content string, timestamp string, name *k8s_model.ContainerName
 	log := &k8s_log.Log{
 		ContentItem: item.NewItem(content),
 	}
+	log.Timestamps.Short = timestamp
+	display := &model.PageLogDisplay{
+		TimestampFormat: model.FormatNone,
+		NameFormat:      model.FormatNone,
+		PrettyPrint:     prettyPrinted,
+	}
+	if timestamp != "" {
+		display.TimestampFormat = model.FormatShort
+	}
 	var containerNames *model.PageLogContainerNames
 	if name != nil {
 		containerNames = &model.PageLogContainerNames{
 			Short: *name,
 			Full:  *name,
 		}
+		display.NameFormat = model.FormatShort
 	}
 	return model.PageLog{
-		Log:              log,
-		ContainerNames:   containerNames,
-		CurrentName:      name,
-		CurrentTimestamp: timestamp,
-		Theme:            theme,
-		PrettyPrinted:    prettyPrinted,
+		Log:            log,
+		ContainerNames: containerNames,
+		Display:        display,
+		Theme:          theme,
 	}
 }
 
@@ -126,6 +142,35 @@ func TestContentForFile_PrettyPrintedMatchesGetItemWithoutAnsi(t *testing.T) {
 	}
 }
 
+func TestContentForFile_PrettyPrintedLogfmt(t *testing.T) {
+	theme := style.DefaultTheme()
+	pl := makePageLog(`level=info msg="request done" dur=3ms`, "12:00:00", nil, true, &theme)
+	pl.Display.JSONColors = &util.JSONColorStyles{Key: theme.JSONKey, Number: theme.JSONNumber}
+	expected := "12:00:00 level=info\nmsg=\"request done\"\ndur=3ms"
+	if got := pl.ContentForFile(); got != expected {
+		t.Errorf("expected one pair per line %q, got %q", expected, got)
+	}
+	if got := pl.GetItem(); !hasAnsi(got.Content()) || got.ContentNoAnsi() != expected {
+		t.Errorf("expected colorized pairs matching %q, got %q", expected, got.Content())
+	}
+}
+
+func TestGetItem_MultiLineRecord(t *testing.T) {
+	theme := style.DefaultTheme()
+	pl := makePageLog("java.lang.IllegalStateException: boom\n    at Handler.handle(Handler.java:42)", "12:00:00", nil, false, &theme)
+	got := pl.GetItem()
+	if got.NumWrappedLines(100) != 2 {
+		t.Errorf("expected record rendered on 2 lines, got %d", got.NumWrappedLines(100))
+	}
+	expected := "12:00:00 java.lang.IllegalStateException: boom\n    at Handler.handle(Handler.java:42)"
+	if got := pl.ContentForFile(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+	if got.ContentNoAnsi() != expected {
+		t.Errorf("expected GetItem to match %q, got %q", expected, got.ContentNoAnsi())
+	}
+}
+
 func TestContentForFile_NonPrettyMatchesGetItemWithoutAnsi(t *testing.T) {
 	theme := style.DefaultTheme()
 	name := &k8s_model.ContainerNameAndPrefix{Prefix: "my-pod", ContainerName: "web"}
@@ -136,3 +181,414 @@ func TestContentForFile_NonPrettyMatchesGetItemWithoutAnsi(t *testing.T) {
 		t.Errorf("ContentForFile() and GetItem().ContentNoAnsi() should match\nContentForFile:    %q\nGetItem NoAnsi:    %q", contentForFile, getItemNoAnsi)
 	}
 }
+
+func TestGetItem_ColorizesJSONWhenRendered(t *testing.T) {
+	content := `{"key":"value","num":1}`
+	pl := makePageLog(content, "", nil, false, nil)
+	if pl.Log.ContentItem.Content() != content {
+		t.Fatalf("expected raw content to be kept, got %q", pl.Log.ContentItem.Content())
+	}
+	if got := pl.GetItem().Content(); got != content {
+		t.Errorf("expected no colorization without colors, got %q", got)
+	}
+
+	colors := &util.JSONColorStyles{Key: lipgloss.NewStyle().Foreground(lipgloss.Red)}
+	pl.Display.JSONColors = colors
+	colorized := pl.GetItem().Content()
+	if !hasAnsi(colorized) {
+		t.Errorf("expected colorized content, got %q", colorized)
+	}
+	if pl.Log.ContentItem.Content() != content {
+		t.Errorf("expected raw content to be unchanged by rendering, got %q", pl.Log.ContentItem.Content())
+	}
+	if got := pl.ContentForFile(); got != content {
+		t.Errorf("expected raw content for file, got %q", got)
+	}
+
+	pl.Display.JSONColors = &util.JSONColorStyles{Key: lipgloss.NewStyle().Foreground(lipgloss.Blue)}
+	if recolored := pl.GetItem().Content(); recolored == colorized || !hasAnsi(recolored) {
+		t.Errorf("expected content to be recolored with new colors, got %q", recolored)
+	}
+}
+
+func makeTimestampedPageLog(content string, ts time.Time, sequence uint64, containerName string) model.PageLog {
+	return model.PageLog{
+		Log: &k8s_log.Log{
+			Timestamp:   ts,
+			Sequence:    sequence,
+			Container:   container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: containerName},
+			ContentItem: item.NewItem(content),
+		},
+	}
+}
+
+func orderedContents(lc *model.PageLogContainer) []string {
+	var res []string
+	for _, l := range lc.GetOrderedLogs() {
+		res = append(res, l.Log.Item().Content())
+	}
+	return res
+}
+
+func TestPageLogContainer_IdenticalTimestampsKeepAllLogs(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	logs := []model.PageLog{
+		makeTimestampedPageLog("b2", ts, 2, "b"),
+		makeTimestampedPageLog("a1", ts, 1, "a"),
+		makeTimestampedPageLog("a3", ts, 3, "a"),
+		makeTimestampedPageLog("b1", ts, 1, "b"),
+		makeTimestampedPageLog("a2", ts, 2, "a"),
+		makeTimestampedPageLog("earlier", ts.Add(-time.Second), 4, "a"),
+	}
+
+	tests := []struct {
+		name      string
+		ascending bool
+		expected  []string
+	}{
+		{"ascending", true, []string{"earlier", "a1", "b1", "a2", "b2", "a3"}},
+		{"descending", false, []string{"a3", "b2", "a2", "b1", "a1", "earlier"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lc := model.NewPageLogContainer(tt.ascending)
+			for _, l := range logs {
+				lc.AppendLog(l, nil)
+			}
+			if lc.Len() != len(logs) {
+				t.Fatalf("expected %d logs, got %d", len(logs), lc.Len())
+			}
+			got := orderedContents(lc)
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPageLogContainer_OutOfOrderInserts(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// insert at the ends and in the middle of the logs, enough times for the logs to be reallocated
+	var logs []model.PageLog
+	var expected []string
+	for i := 0; i < 100; i++ {
+		expected = append(expected, fmt.Sprintf("%03d", i))
+	}
+	for _, i := range rand.New(rand.NewPCG(1, 2)).Perm(100) {
+		logs = append(logs, makeTimestampedPageLog(fmt.Sprintf("%03d", i), ts.Add(time.Duration(i)*time.Second), 1, "a"))
+	}
+
+	for _, ascending := range []bool{true, false} {
+		lc := model.NewPageLogContainer(ascending)
+		for _, l := range logs {
+			lc.AppendLog(l, nil)
+		}
+		want := slices.Clone(expected)
+		if !ascending {
+			slices.Reverse(want)
+		}
+		if got := orderedContents(lc); !slices.Equal(got, want) {
+			t.Errorf("ascending=%v: expected %v, got %v", ascending, want, got)
+		}
+
+		lc.ToggleAscending()
+		slices.Reverse(want)
+		if got := orderedContents(lc); !slices.Equal(got, want) {
+			t.Errorf("ascending=%v toggled: expected %v, got %v", ascending, want, got)
+		}
+		lc.AppendLog(makeTimestampedPageLog("latest", ts.Add(time.Hour), 1, "a"), nil)
+		if !lc.Ascending() && orderedContents(lc)[0] != "latest" || lc.Ascending() && orderedContents(lc)[lc.Len()-1] != "latest" {
+			t.Errorf("ascending=%v toggled: expected latest log to be placed by the toggled order, got %v", ascending, orderedContents(lc))
+		}
+	}
+}
+
+func TestPageLogContainer_OrdersByAppTimestamp(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// flushed together by a buffering app, but logged a second apart
+	flushed := makeTimestampedPageLog("logged first", ts.Add(time.Second), 1, "a")
+	flushed.Log.AppTimestamp = ts.Add(-2 * time.Second)
+	flushedLater := makeTimestampedPageLog("logged second", ts.Add(time.Second), 2, "a")
+	flushedLater.Log.AppTimestamp = ts.Add(-time.Second)
+	// no app timestamp falls back to when kubernetes captured it
+	other := makeTimestampedPageLog("captured", ts.Add(-1500*time.Millisecond), 1, "b")
+
+	lc := model.NewPageLogContainer(true)
+	for _, l := range []model.PageLog{flushedLater, other, flushed} {
+		lc.AppendLog(l, nil)
+	}
+	expected := []string{"logged first", "captured", "logged second"}
+	if got := orderedContents(lc); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if earliest, latest, _ := lc.TimeRange(); !earliest.Equal(ts.Add(-2*time.Second)) || !latest.Equal(ts.Add(-time.Second)) {
+		t.Errorf("expected time range from app timestamps, got %v to %v", earliest, latest)
+	}
+}
+
+func TestPageLogContainer_RemoveLogsForContainer(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lc := model.NewPageLogContainer(true)
+	lc.SetRetentionPolicy(model.RetentionPolicy{MaxLinesPerContainer: 10})
+	for i, name := range []string{"a", "b", "a", "b", "a"} {
+		lc.AppendLog(makeTimestampedPageLog(fmt.Sprintf("%s%d", name, i), ts.Add(time.Duration(i)*time.Second), 1, name), nil)
+	}
+
+	lc.RemoveLogsForContainer(container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "b"})
+	if got, want := orderedContents(lc), []string{"a0", "a2", "a4"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if lc.NumDropped() != 0 {
+		t.Errorf("expected removed logs not to count as dropped, got %d", lc.NumDropped())
+	}
+}
+
+func TestPageLog_DisplayAppliedAtRender(t *testing.T) {
+	display := &model.PageLogDisplay{TimestampFormat: model.FormatNone, NameFormat: model.FormatNone}
+	names := &model.PageLogContainerNames{
+		Short: k8s_model.ContainerNameAndPrefix{ContainerName: "web"},
+		Full:  k8s_model.ContainerNameAndPrefix{Prefix: "my-pod", ContainerName: "web"},
+	}
+	pl := makePageLog("hello", "12:00:00", nil, false, nil)
+	pl.ContainerNames = names
+	pl.Display = display
+	pl.Log.Timestamps.Full = "2024-01-01T12:00:00Z"
+
+	tests := []struct {
+		name     string
+		update   func()
+		expected string
+	}{
+		{"none", func() {}, "hello"},
+		{"short timestamp", func() { display.TimestampFormat = model.FormatShort }, "12:00:00 hello"},
+		{"full timestamp", func() { display.TimestampFormat = model.FormatFull }, "2024-01-01T12:00:00Z hello"},
+		{"short name", func() { display.TimestampFormat = model.FormatNone; display.NameFormat = model.FormatShort }, "web hello"},
+		{"full name", func() { display.NameFormat = model.FormatFull }, "my-pod/web hello"},
+		{"terminated", func() { names.Terminated = true }, "my-pod/web [TERMINATED] hello"},
+		{"renamed", func() { names.Full.Prefix = "other-pod" }, "other-pod/web [TERMINATED] hello"},
+	}
+	for _, tt := range tests {
+		tt.update()
+		if got := pl.ContentForFile(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestPageLogContainer_SortsAfterLast(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lc := model.NewPageLogContainer(true)
+	if !lc.SortsAfterLast(makeTimestampedPageLog("first", ts, 1, "a")) {
+		t.Error("expected any log to sort after last of an empty container")
+	}
+	lc.AppendLog(makeTimestampedPageLog("a2", ts, 2, "a"), nil)
+
+	if !lc.SortsAfterLast(makeTimestampedPageLog("a3", ts, 3, "a")) {
+		t.Error("expected later sequence with same timestamp to sort after last")
+	}
+	if lc.SortsAfterLast(makeTimestampedPageLog("b1", ts, 1, "b")) {
+		t.Error("expected earlier sequence with same timestamp not to sort after last")
+	}
+	if lc.SortsAfterLast(makeTimestampedPageLog("earlier", ts.Add(-time.Second), 5, "a")) {
+		t.Error("expected earlier timestamp not to sort after last")
+	}
+}
+
+func TestPageLogContainer_Retention(t *testing.T) {
+	now := time.Now()
+	ts := func(secondsAgo int) time.Time {
+		return now.Add(-time.Duration(secondsAgo) * time.Second)
+	}
+	logs := []model.PageLog{
+		makeTimestampedPageLog("a-old", ts(50), 1, "a"),
+		makeTimestampedPageLog("b-old", ts(40), 1, "b"),
+		makeTimestampedPageLog("a-mid", ts(30), 2, "a"),
+		makeTimestampedPageLog("b-new", ts(20), 2, "b"),
+		makeTimestampedPageLog("a-new", ts(10), 3, "a"),
+	}
+
+	tests := []struct {
+		name      string
+		retention model.RetentionPolicy
+		ascending bool
+		expected  []string
+	}{
+		{"unlimited", model.RetentionPolicy{}, true, []string{"a-old", "b-old", "a-mid", "b-new", "a-new"}},
+		{"max lines", model.RetentionPolicy{MaxLines: 2}, true, []string{"b-new", "a-new"}},
+		{"max lines descending", model.RetentionPolicy{MaxLines: 2}, false, []string{"a-new", "b-new"}},
+		{"max bytes", model.RetentionPolicy{MaxBytes: 11}, true, []string{"b-new", "a-new"}},
+		{"max age", model.RetentionPolicy{MaxAge: 35 * time.Second}, true, []string{"a-mid", "b-new", "a-new"}},
+		{"max lines per container", model.RetentionPolicy{MaxLinesPerContainer: 1}, true, []string{"b-new", "a-new"}},
+		{"max bytes per container", model.RetentionPolicy{MaxBytesPerContainer: 10}, true, []string{"b-old", "a-mid", "b-new", "a-new"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lc := model.NewPageLogContainer(tt.ascending)
+			lc.SetRetentionPolicy(tt.retention)
+			for _, l := range logs {
+				lc.AppendLog(l, nil)
+			}
+			got := orderedContents(lc)
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+			if lc.NumDropped() != len(logs)-len(tt.expected) {
+				t.Errorf("expected %d dropped, got %d", len(logs)-len(tt.expected), lc.NumDropped())
+			}
+		})
+	}
+}
+
+func TestPageLogContainer_RetentionAppliedToExistingLogs(t *testing.T) {
+	now := time.Now()
+	lc := model.NewPageLogContainer(true)
+	for i := 0; i < 5; i++ {
+		lc.AppendLog(makeTimestampedPageLog("log", now.Add(time.Duration(i)*time.Second), uint64(i), "a"), nil)
+	}
+
+	lc.SetRetentionPolicy(model.RetentionPolicy{MaxLinesPerContainer: 3})
+	if lc.Len() != 3 || lc.NumDropped() != 2 {
+		t.Errorf("expected 3 logs and 2 dropped, got %d logs and %d dropped", lc.Len(), lc.NumDropped())
+	}
+
+	// removing all logs, e.g. to reinsert them, doesn't count as dropping
+	lc.RemoveAllLogs()
+	if lc.NumDropped() != 2 {
+		t.Errorf("expected dropped count to be kept, got %d", lc.NumDropped())
+	}
+}
+
+func TestPageLogContainer_DropExpired(t *testing.T) {
+	now := time.Now()
+	lc := model.NewPageLogContainer(true)
+	lc.SetRetentionPolicy(model.RetentionPolicy{MaxAge: time.Minute})
+	for i := 0; i < 5; i++ {
+		lc.AppendLog(makeTimestampedPageLog("log", now.Add(time.Duration(i)*time.Second), uint64(i), "a"), nil)
+	}
+
+	// nothing is added while the logs age
+	lc.DropExpired(now.Add(time.Minute + 2500*time.Millisecond))
+	if lc.Len() != 2 || lc.NumDropped() != 3 {
+		t.Errorf("expected 2 logs and 3 dropped, got %d logs and %d dropped", lc.Len(), lc.NumDropped())
+	}
+	if lc.OlderThanOldest(lc.GetOrderedLogs()[0]) {
+		t.Error("expected the oldest log kept not to be older than itself")
+	}
+}
+
+func newTestDiskStore(t *testing.T) *logstore.DiskStore {
+	t.Helper()
+	// small segments, so logs span several
+	store, err := logstore.NewDiskStore(t.TempDir(), 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func TestPageLogContainer_DiskStore(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lc := model.NewPageLogContainer(true)
+	lc.SetDiskStore(newTestDiskStore(t), 2)
+	lc.SetRetentionPolicy(model.RetentionPolicy{MaxLines: 4})
+	add := func(i int, content string) {
+		lc.AppendLog(makeTimestampedPageLog(content, ts.Add(time.Duration(i)*time.Second), uint64(i), "a"), nil)
+	}
+	prepare := func(l model.PageLog) model.PageLog {
+		l.Display = &model.PageLogDisplay{}
+		return l
+	}
+	onDisk := func() string {
+		var contents []string
+		if err := lc.EachOlderOnDisk(1, func(l model.PageLog) {
+			contents = append(contents, l.Log.Item().Content())
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(contents, ",")
+	}
+	for i, content := range []string{"one", "two", "three", "four", "five"} {
+		add(i, content)
+	}
+
+	// the first log is dropped, and only the most recent are kept in memory
+	if got := strings.Join(orderedContents(lc), ","); got != "four,five" {
+		t.Errorf("expected most recent logs in memory, got %v", got)
+	}
+	if got := onDisk(); got != "two,three" || lc.NumOlderOnDisk() != 2 {
+		t.Errorf("expected older logs on disk, got %v", got)
+	}
+	if lc.NumDropped() != 1 {
+		t.Errorf("expected 1 dropped, got %d", lc.NumDropped())
+	}
+
+	n, err := lc.LoadOlder(1, prepare)
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 log loaded, got %d (err %v)", n, err)
+	}
+	if got := strings.Join(orderedContents(lc), ","); got != "three,four,five" {
+		t.Errorf("expected loaded log in memory, got %v", got)
+	}
+	if lc.GetOrderedLogs()[0].Display == nil {
+		t.Error("expected loaded log to be prepared")
+	}
+
+	// logs loaded are kept in memory until dropped
+	add(5, "six")
+	if got := strings.Join(orderedContents(lc), ","); got != "three,four,five,six" || onDisk() != "" {
+		t.Errorf("expected logs to stay in memory while logs are loaded, got %v on disk and %v", onDisk(), got)
+	}
+	add(6, "seven")
+	if got := strings.Join(orderedContents(lc), ","); got != "six,seven" || onDisk() != "four,five" {
+		t.Errorf("expected logs moved to disk once the loaded logs were dropped, got %v on disk and %v", onDisk(), got)
+	}
+
+	if _, err := lc.LoadSince(ts.Add(4*time.Second), prepare); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(orderedContents(lc), ","); got != "five,six,seven" {
+		t.Errorf("expected logs since the time loaded, got %v", got)
+	}
+	lc.UnloadOlder()
+	if got := strings.Join(orderedContents(lc), ","); got != "six,seven" || onDisk() != "four,five" {
+		t.Errorf("expected loaded logs moved back to disk, got %v on disk and %v", onDisk(), got)
+	}
+}
+
+func TestPageLogContainer_DiskStoreSkipsRemovedContainers(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lc := model.NewPageLogContainer(false)
+	lc.SetDiskStore(newTestDiskStore(t), 1)
+	lc.AppendLog(makeTimestampedPageLog("a-old", ts, 0, "a"), nil)
+	lc.AppendLog(makeTimestampedPageLog("b-old", ts.Add(time.Second), 1, "b"), nil)
+	lc.AppendLog(makeTimestampedPageLog("a-new", ts.Add(2*time.Second), 2, "a"), nil)
+	lc.RemoveLogsForContainer(container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "a"})
+	lc.AppendLog(makeTimestampedPageLog("a-newest", ts.Add(3*time.Second), 3, "a"), nil)
+
+	if _, err := lc.LoadOlder(10, func(l model.PageLog) model.PageLog { return l }); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(orderedContents(lc), ","); got != "a-newest,b-old" {
+		t.Errorf("expected logs of the removed container not to be loaded back, got %v", got)
+	}
+}
+
+func TestContentForFile_LevelBadge(t *testing.T) {
+	theme := style.DefaultTheme()
+	name := &k8s_model.ContainerNameAndPrefix{ContainerName: "web"}
+	pl := makePageLog("ERROR failed", "12:00:00", name, false, &theme)
+	pl.Log.Level = k8s_log.LevelError
+	if got := pl.ContentForFile(); got != "12:00:00 web ERR ERROR failed" {
+		t.Errorf("expected level badge after the name, got %q", got)
+	}
+	if got := pl.GetItem().ContentNoAnsi(); got != pl.ContentForFile() {
+		t.Errorf("expected rendered content %q to match content for file %q", got, pl.ContentForFile())
+	}
+
+	pl = makePageLog("starting", "", nil, false, &theme)
+	pl.Log.Level = k8s_log.LevelInfo
+	if got := pl.ContentForFile(); got != "INF starting" {
+		t.Errorf("expected level badge alone as prefix, got %q", got)
+	}
+}
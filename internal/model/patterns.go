@@ -0,0 +1,184 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// PatternWildcard replaces the variable tokens of a pattern
+	PatternWildcard = "<*>"
+	// patternSimilarity is the fraction of tokens a log must share with a pattern to be grouped into it
+	patternSimilarity = 0.5
+	// maxPatternTokens limits the tokens considered per log, so very long lines don't make clustering slow
+	maxPatternTokens = 100
+)
+
+// PatternContainerStats are the occurrences of a pattern in the logs of a container
+type PatternContainerStats struct {
+	Container string
+	Count     int
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// LogPattern is a template of similar logs, with the tokens that vary between them replaced by PatternWildcard
+type LogPattern struct {
+	Tokens     []string
+	Count      int
+	FirstSeen  time.Time
+	LastSeen   time.Time
+	Containers []PatternContainerStats
+	containers map[string]int // container name -> index in Containers
+}
+
+// Template returns the pattern as text
+func (p *LogPattern) Template() string {
+	return strings.Join(p.Tokens, " ")
+}
+
+// Regex returns a regular expression matching the logs of the pattern
+func (p *LogPattern) Regex() string {
+	parts := make([]string, len(p.Tokens))
+	for i, token := range p.Tokens {
+		if token == PatternWildcard {
+			parts[i] = `\S+`
+		} else {
+			parts[i] = regexp.QuoteMeta(token)
+		}
+	}
+	return strings.Join(parts, `\s+`)
+}
+
+func (p *LogPattern) add(containerName string, ts time.Time) {
+	p.Count++
+	if p.FirstSeen.IsZero() || ts.Before(p.FirstSeen) {
+		p.FirstSeen = ts
+	}
+	if ts.After(p.LastSeen) {
+		p.LastSeen = ts
+	}
+
+	idx, ok := p.containers[containerName]
+	if !ok {
+		idx = len(p.Containers)
+		p.containers[containerName] = idx
+		p.Containers = append(p.Containers, PatternContainerStats{Container: containerName, FirstSeen: ts})
+	}
+	c := &p.Containers[idx]
+	c.Count++
+	if ts.Before(c.FirstSeen) {
+		c.FirstSeen = ts
+	}
+	if ts.After(c.LastSeen) {
+		c.LastSeen = ts
+	}
+}
+
+// similarity returns the fraction of the pattern's tokens that match tokens, counting wildcards as matches
+func (p *LogPattern) similarity(tokens []string) float64 {
+	same := 0
+	for i, token := range p.Tokens {
+		if token == tokens[i] || token == PatternWildcard {
+			same++
+		}
+	}
+	return float64(same) / float64(len(tokens))
+}
+
+// merge replaces the tokens that differ from tokens with PatternWildcard
+func (p *LogPattern) merge(tokens []string) {
+	for i, token := range p.Tokens {
+		if token != tokens[i] {
+			p.Tokens[i] = PatternWildcard
+		}
+	}
+}
+
+// PatternClusterer groups logs into patterns in the style of Drain: tokens that look variable, like ids, numbers
+// and IP addresses, are masked up front, then each log is grouped with the most similar pattern that has the same
+// number of tokens and the same first token, and tokens that differ within a group become wildcards
+type PatternClusterer struct {
+	groups   map[string][]*LogPattern
+	patterns []*LogPattern
+}
+
+func NewPatternClusterer() *PatternClusterer {
+	return &PatternClusterer{groups: make(map[string][]*LogPattern)}
+}
+
+// Add groups a log's content into a pattern
+func (c *PatternClusterer) Add(content, containerName string, ts time.Time) {
+	tokens := patternTokens(content)
+	if len(tokens) == 0 {
+		return
+	}
+	key := strconv.Itoa(len(tokens)) + " " + tokens[0]
+
+	var best *LogPattern
+	bestSimilarity := 0.
+	for _, pattern := range c.groups[key] {
+		if sim := pattern.similarity(tokens); sim > bestSimilarity {
+			best, bestSimilarity = pattern, sim
+		}
+	}
+	if best == nil || bestSimilarity < patternSimilarity {
+		best = &LogPattern{Tokens: tokens, containers: make(map[string]int)}
+		c.groups[key] = append(c.groups[key], best)
+		c.patterns = append(c.patterns, best)
+	} else {
+		best.merge(tokens)
+	}
+	best.add(containerName, ts)
+}
+
+// Patterns returns the patterns with the most frequent first, and their containers likewise
+func (c *PatternClusterer) Patterns() []*LogPattern {
+	patterns := make([]*LogPattern, len(c.patterns))
+	copy(patterns, c.patterns)
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].Count > patterns[j].Count
+	})
+	for _, pattern := range patterns {
+		sort.SliceStable(pattern.Containers, func(i, j int) bool {
+			return pattern.Containers[i].Count > pattern.Containers[j].Count
+		})
+		for i, ct := range pattern.Containers {
+			pattern.containers[ct.Container] = i
+		}
+	}
+	return patterns
+}
+
+// patternTokens splits content on whitespace, masking tokens that look variable
+func patternTokens(content string) []string {
+	tokens := strings.Fields(content)
+	if len(tokens) > maxPatternTokens {
+		tokens = tokens[:maxPatternTokens]
+	}
+	for i, token := range tokens {
+		if isVariableToken(token) {
+			tokens[i] = PatternWildcard
+		}
+	}
+	return tokens
+}
+
+// isVariableToken returns true for tokens that likely differ between otherwise identical logs: anything with a
+// digit, like numbers, durations, ids, IP addresses and timestamps, as well as long hex strings
+func isVariableToken(token string) bool {
+	hex := len(token) >= 8
+	for i := 0; i < len(token); i++ {
+		ch := token[i]
+		if ch >= '0' && ch <= '9' {
+			return true
+		}
+		if !(ch >= 'a' && ch <= 'f') && !(ch >= 'A' && ch <= 'F') && ch != '-' {
+			hex = false
+		}
+	}
+	return hex
+}
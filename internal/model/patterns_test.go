@@ -0,0 +1,88 @@
+package model_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/model"
+)
+
+func TestPatternClusterer(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	logs := []struct {
+		content   string
+		container string
+	}{
+		{"GET /health 200 in 3ms", "web"},
+		{"user alice logged in from 10.0.0.1", "auth"},
+		{"GET /health 200 in 5ms", "web"},
+		{"user bob logged in from 10.0.0.2", "auth"},
+		{"GET /health 200 in 4ms", "web-canary"},
+		{"request 3f2a9c1e-77b0-4c1a-9f3e-0c5d2b8a6e11 failed: timeout", "web"},
+		{"user carol logged in from 10.0.0.3", "auth"},
+		{"GET /health 200 in 9ms", "web"},
+	}
+
+	c := model.NewPatternClusterer()
+	for i, l := range logs {
+		c.Add(l.content, l.container, start.Add(time.Duration(i)*time.Second))
+	}
+	patterns := c.Patterns()
+
+	expected := []struct {
+		template   string
+		count      int
+		containers []string
+	}{
+		{"GET /health <*> in <*>", 4, []string{"web", "web-canary"}},
+		{"user <*> logged in from <*>", 3, []string{"auth"}},
+		{"request <*> failed: timeout", 1, []string{"web"}},
+	}
+	if len(patterns) != len(expected) {
+		for _, p := range patterns {
+			t.Logf("%d %s", p.Count, p.Template())
+		}
+		t.Fatalf("expected %d patterns, got %d", len(expected), len(patterns))
+	}
+	for i, e := range expected {
+		p := patterns[i]
+		if p.Template() != e.template || p.Count != e.count {
+			t.Errorf("expected pattern %d to be %q with count %d, got %q with count %d", i, e.template, e.count, p.Template(), p.Count)
+		}
+		if len(p.Containers) != len(e.containers) {
+			t.Errorf("expected pattern %q in %d containers, got %d", e.template, len(e.containers), len(p.Containers))
+			continue
+		}
+		for j, ct := range e.containers {
+			if p.Containers[j].Container != ct {
+				t.Errorf("expected container %d of pattern %q to be %s, got %s", j, e.template, ct, p.Containers[j].Container)
+			}
+		}
+	}
+
+	web := patterns[0].Containers[0]
+	if web.Count != 3 || !web.FirstSeen.Equal(start) || !web.LastSeen.Equal(start.Add(7*time.Second)) {
+		t.Errorf("unexpected stats for web container: %+v", web)
+	}
+	if !patterns[0].FirstSeen.Equal(start) || !patterns[0].LastSeen.Equal(start.Add(7*time.Second)) {
+		t.Errorf("unexpected first and last seen for pattern: %s, %s", patterns[0].FirstSeen, patterns[0].LastSeen)
+	}
+}
+
+func TestLogPattern_Regex(t *testing.T) {
+	c := model.NewPatternClusterer()
+	c.Add("retry (attempt 1) for job.sync", "worker", time.Now())
+	c.Add("retry (attempt 2) for job.sync", "worker", time.Now())
+	pattern := c.Patterns()[0]
+
+	re := regexp.MustCompile(pattern.Regex())
+	for _, line := range []string{"retry (attempt 7) for job.sync", "12:00:00 worker retry  (attempt 12)  for job.sync"} {
+		if !re.MatchString(line) {
+			t.Errorf("expected %q to match pattern regex %q", line, pattern.Regex())
+		}
+	}
+	if re.MatchString("retry (attempt 1) for jobXsync") {
+		t.Errorf("expected literal tokens to be matched exactly by %q", pattern.Regex())
+	}
+}
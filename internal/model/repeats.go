@@ -0,0 +1,141 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	CollapseNone    = "none"
+	CollapseExact   = "exact"
+	CollapseNumbers = "numbers"
+)
+
+// PageLogRepeats holds the logs collapsed into a row after the row's own log, in display order. It is shared by
+// pointer so logs can be collapsed into a row after it is handed to the viewport
+type PageLogRepeats struct {
+	Logs []PageLog
+}
+
+// NumRepeats returns the number of logs the row represents, 1 if nothing is collapsed into it
+func (l PageLog) NumRepeats() int {
+	if l.Repeats == nil {
+		return 1
+	}
+	return 1 + len(l.Repeats.Logs)
+}
+
+// CollapsedLogs returns the logs the row represents in display order, starting with the row's own log
+func (l PageLog) CollapsedLogs() []PageLog {
+	own := l
+	own.Repeats = nil
+	if l.Repeats == nil {
+		return []PageLog{own}
+	}
+	return append([]PageLog{own}, l.Repeats.Logs...)
+}
+
+// repeatsSuffix returns e.g. "×137, 10:02:01–10:02:45" for a row with collapsed logs, or an empty string
+func (l PageLog) repeatsSuffix() string {
+	if l.Repeats == nil || len(l.Repeats.Logs) == 0 {
+		return ""
+	}
+	first, last := l.Log, l.Repeats.Logs[len(l.Repeats.Logs)-1].Log
+	if last.Timestamp.Before(first.Timestamp) {
+		first, last = last, first
+	}
+	return fmt.Sprintf("×%d, %s–%s", l.NumRepeats(), first.Timestamps.Short, last.Timestamps.Short)
+}
+
+// RepeatCollapser collapses consecutive logs from the same container with identical content into single rows.
+// Logs are consecutive if no other log from the same container is between them in display order, so logs from
+// other containers don't break up repeats
+type RepeatCollapser struct {
+	maskNumbers bool
+	rows        []PageLog
+	// lastRows holds the index of the last row of each container, which the container's next log may collapse into
+	lastRows map[string]int
+}
+
+func NewRepeatCollapser(maskNumbers bool) *RepeatCollapser {
+	return &RepeatCollapser{maskNumbers: maskNumbers, lastRows: make(map[string]int)}
+}
+
+// UpdatedRow is a row Append collapsed more logs into after it was returned
+type UpdatedRow struct {
+	Idx int
+	// Before is the row as it was before, rendered with the logs collapsed into it then
+	Before PageLog
+}
+
+// Rebuild collapses logs given in display order, returning the rows
+func (c *RepeatCollapser) Rebuild(logs []PageLog) []PageLog {
+	// leave room to append to, as the rows are handed on and would otherwise all be copied by the next Append
+	c.rows = make([]PageLog, 0, len(logs)+len(logs)/4)
+	clear(c.lastRows)
+	c.collapse(logs, 0)
+	return c.rows
+}
+
+// Append collapses logs that come after all previous logs in display order, returning only the new rows.
+// Logs collapsed into existing rows update them in place, which are returned as updated
+func (c *RepeatCollapser) Append(logs []PageLog) ([]PageLog, []UpdatedRow) {
+	prevLen := len(c.rows)
+	updated := c.collapse(logs, prevLen)
+	return c.rows[prevLen:], updated
+}
+
+// TrimHead removes the leading rows whose logs were all dropped, given logs in ascending display order and dropped
+// true for the logs dropped from their start. It returns false without removing rows if a row holds both dropped and
+// kept logs, as only Rebuild moves such a row to where its first kept log is
+func (c *RepeatCollapser) TrimHead(dropped func(PageLog) bool) bool {
+	n := 0
+	for n < len(c.rows) && dropped(c.rows[n]) {
+		if repeats := c.rows[n].Repeats.Logs; len(repeats) > 0 && !dropped(repeats[len(repeats)-1]) {
+			return false
+		}
+		n++
+	}
+	if n == 0 {
+		return true
+	}
+	c.rows = c.rows[n:]
+	for id, idx := range c.lastRows {
+		if idx < n {
+			delete(c.lastRows, id)
+		} else {
+			c.lastRows[id] = idx - n
+		}
+	}
+	return true
+}
+
+// Rows returns the rows collapsed so far, in display order
+func (c *RepeatCollapser) Rows() []PageLog {
+	return c.rows
+}
+
+// collapse collapses logs into the rows, returning the rows before existing that were updated
+func (c *RepeatCollapser) collapse(logs []PageLog, existing int) []UpdatedRow {
+	var updated []UpdatedRow
+	for _, log := range logs {
+		id := log.Log.Container.ID()
+		if idx, ok := c.lastRows[id]; ok {
+			row := c.rows[idx]
+			if row.Log.ContentHash(c.maskNumbers) == log.Log.ContentHash(c.maskNumbers) {
+				if idx < existing && !slices.ContainsFunc(updated, func(u UpdatedRow) bool { return u.Idx == idx }) {
+					before := row
+					n := len(row.Repeats.Logs)
+					before.Repeats = &PageLogRepeats{Logs: row.Repeats.Logs[:n:n]}
+					updated = append(updated, UpdatedRow{Idx: idx, Before: before})
+				}
+				row.Repeats.Logs = append(row.Repeats.Logs, log)
+				continue
+			}
+		}
+		log.Repeats = &PageLogRepeats{}
+		c.lastRows[id] = len(c.rows)
+		c.rows = append(c.rows, log)
+	}
+	return updated
+}
@@ -0,0 +1,193 @@
+package model_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+	"github.com/robinovitch61/kl/internal/model"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+func makeRepeatLog(ct container.Container, ts time.Time, content string) model.PageLog {
+	return model.PageLog{
+		Log: &k8s_log.Log{
+			Timestamp:   ts,
+			Timestamps:  k8s_log.LogTimestamps{Short: ts.Format(time.TimeOnly), Full: ts.Format(time.RFC3339)},
+			Container:   ct,
+			ContentItem: item.NewItem(content),
+		},
+		Display: &model.PageLogDisplay{TimestampFormat: model.FormatNone, NameFormat: model.FormatNone},
+	}
+}
+
+func rowContents(rows []model.PageLog) []string {
+	var contents []string
+	for _, row := range rows {
+		contents = append(contents, row.GetItem().ContentNoAnsi())
+	}
+	return contents
+}
+
+func TestRepeatCollapser(t *testing.T) {
+	app := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	sidecar := container.Container{Namespace: "ns", Pod: "pod", Name: "sidecar"}
+	start := time.Date(2024, 1, 1, 10, 2, 1, 0, time.UTC)
+	logs := []model.PageLog{
+		makeRepeatLog(app, start, "retrying in 1s"),
+		makeRepeatLog(sidecar, start.Add(time.Second), "healthy"),
+		makeRepeatLog(app, start.Add(2*time.Second), "retrying in 1s"),
+		makeRepeatLog(app, start.Add(3*time.Second), "retrying in 2s"),
+		makeRepeatLog(app, start.Add(44*time.Second), "retrying in 1s"),
+		makeRepeatLog(app, start.Add(45*time.Second), "done"),
+	}
+
+	tests := []struct {
+		name        string
+		maskNumbers bool
+		expected    []string
+	}{
+		{
+			name: "exact",
+			expected: []string{
+				"retrying in 1s ×2, 10:02:01–10:02:03",
+				"healthy",
+				"retrying in 2s",
+				"retrying in 1s",
+				"done",
+			},
+		},
+		{
+			name:        "ignoring numbers",
+			maskNumbers: true,
+			expected: []string{
+				"retrying in 1s ×4, 10:02:01–10:02:45",
+				"healthy",
+				"done",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := model.NewRepeatCollapser(tt.maskNumbers).Rebuild(logs)
+			if got := rowContents(rows); strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected rows\n%s\ngot\n%s", strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+			}
+
+			// appending one log at a time collapses the same way
+			c := model.NewRepeatCollapser(tt.maskNumbers)
+			var appended []model.PageLog
+			for i := range logs {
+				rows, _ := c.Append(logs[i : i+1])
+				appended = append(appended, rows...)
+			}
+			if got := rowContents(appended); strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected appended rows\n%s\ngot\n%s", strings.Join(tt.expected, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestRepeatCollapser_KeepsUnderlyingLogs(t *testing.T) {
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	start := time.Date(2024, 1, 1, 10, 2, 1, 0, time.UTC)
+	var logs []model.PageLog
+	for i := range 3 {
+		logs = append(logs, makeRepeatLog(ct, start.Add(time.Duration(i)*time.Second), "connection refused"))
+	}
+
+	rows := model.NewRepeatCollapser(false).Rebuild(logs)
+	if len(rows) != 1 || rows[0].NumRepeats() != 3 {
+		t.Fatalf("expected a single row for 3 logs, got %d rows", len(rows))
+	}
+	collapsed := rows[0].CollapsedLogs()
+	for i := range logs {
+		if collapsed[i].Log != logs[i].Log || collapsed[i].Repeats != nil {
+			t.Errorf("expected collapsed log %d to be the original log", i)
+		}
+		if logs[i].Repeats != nil {
+			t.Errorf("expected original log %d not to be modified", i)
+		}
+	}
+	if content := logs[0].ContentForFile(); content != "connection refused" {
+		t.Errorf("expected exported content without repeat suffix, got %q", content)
+	}
+}
+
+func TestRepeatCollapser_AppendReturnsUpdatedRows(t *testing.T) {
+	app := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	sidecar := container.Container{Namespace: "ns", Pod: "pod", Name: "sidecar"}
+	start := time.Date(2024, 1, 1, 10, 2, 1, 0, time.UTC)
+
+	c := model.NewRepeatCollapser(false)
+	c.Rebuild([]model.PageLog{
+		makeRepeatLog(app, start, "retrying"),
+		makeRepeatLog(app, start.Add(time.Second), "retrying"),
+		makeRepeatLog(sidecar, start.Add(2*time.Second), "healthy"),
+	})
+	rows, updated := c.Append([]model.PageLog{
+		makeRepeatLog(app, start.Add(3*time.Second), "retrying"),
+		makeRepeatLog(app, start.Add(4*time.Second), "retrying"),
+		makeRepeatLog(sidecar, start.Add(5*time.Second), "degraded"),
+		makeRepeatLog(sidecar, start.Add(6*time.Second), "degraded"),
+	})
+	if got := rowContents(rows); strings.Join(got, "\n") != "degraded ×2, 10:02:06–10:02:07" {
+		t.Errorf("expected only the new row to be returned, got %q", got)
+	}
+	if len(updated) != 1 || updated[0].Idx != 0 {
+		t.Fatalf("expected only the first row to be updated, got %+v", updated)
+	}
+	if got := updated[0].Before.GetItem().ContentNoAnsi(); got != "retrying ×2, 10:02:01–10:02:02" {
+		t.Errorf("expected the row before the update, got %q", got)
+	}
+	if got := c.Rows()[0].GetItem().ContentNoAnsi(); got != "retrying ×4, 10:02:01–10:02:05" {
+		t.Errorf("expected the updated row, got %q", got)
+	}
+}
+
+func TestRepeatCollapser_TrimHead(t *testing.T) {
+	app := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	sidecar := container.Container{Namespace: "ns", Pod: "pod", Name: "sidecar"}
+	start := time.Date(2024, 1, 1, 10, 2, 1, 0, time.UTC)
+	droppedBefore := func(cutoff time.Time) func(model.PageLog) bool {
+		return func(l model.PageLog) bool { return l.Log.Timestamp.Before(cutoff) }
+	}
+
+	c := model.NewRepeatCollapser(false)
+	c.Rebuild([]model.PageLog{
+		makeRepeatLog(app, start, "starting"),
+		makeRepeatLog(sidecar, start.Add(time.Second), "healthy"),
+		makeRepeatLog(sidecar, start.Add(2*time.Second), "healthy"),
+		makeRepeatLog(app, start.Add(3*time.Second), "retrying"),
+		makeRepeatLog(app, start.Add(4*time.Second), "retrying"),
+	})
+
+	// the sidecar's row would have to start at its kept log
+	if c.TrimHead(droppedBefore(start.Add(2 * time.Second))) {
+		t.Fatal("expected a row holding both dropped and kept logs not to be trimmed")
+	}
+	if len(c.Rows()) != 3 {
+		t.Fatalf("expected no rows to be removed, got %q", rowContents(c.Rows()))
+	}
+
+	if !c.TrimHead(droppedBefore(start.Add(3 * time.Second))) {
+		t.Fatal("expected rows of only dropped logs to be trimmed")
+	}
+	if got := rowContents(c.Rows()); strings.Join(got, "\n") != "retrying ×2, 10:02:04–10:02:05" {
+		t.Errorf("expected only the kept row, got %q", got)
+	}
+
+	// the removed sidecar row is no longer collapsed into, and the kept row still is
+	rows, updated := c.Append([]model.PageLog{
+		makeRepeatLog(sidecar, start.Add(5*time.Second), "healthy"),
+		makeRepeatLog(app, start.Add(6*time.Second), "retrying"),
+	})
+	if got := rowContents(rows); strings.Join(got, "\n") != "healthy" {
+		t.Errorf("expected a new sidecar row, got %q", got)
+	}
+	if len(updated) != 1 || updated[0].Idx != 0 {
+		t.Errorf("expected the kept row to be updated, got %+v", updated)
+	}
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"time"
+
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+)
+
+// ReplayFilter skips the logs a restarted log stream replays. A stream is restarted from just after the last log
+// received, but Kubernetes rounds the time a stream starts from down to the second, so the restarted stream replays
+// the logs of that second. Replayed logs are recognized by their timestamp and content, and restarted streams get new
+// sequence numbers, so they would otherwise be kept twice
+type ReplayFilter struct {
+	containers map[string]*containerReplay
+}
+
+type containerReplay struct {
+	// second is the second of log timestamps whose logs are counted in received, that of the last log received
+	second int64
+	// received counts the logs received in second by timestamp and content
+	received map[replayKey]int
+	// last is the latest timestamp received
+	last time.Time
+	// replaying counts the logs the restarted stream is yet to replay, nil unless the stream may still replay logs
+	replaying map[replayKey]int
+	// replayUntil is the timestamp of the last log received before the stream restarted
+	replayUntil time.Time
+}
+
+type replayKey struct {
+	timestamp int64
+	content   string
+}
+
+func NewReplayFilter() *ReplayFilter {
+	return &ReplayFilter{containers: make(map[string]*containerReplay)}
+}
+
+// IsReplay returns true if the log was already received before its container's log stream restarted, otherwise
+// counting it as received
+func (f *ReplayFilter) IsReplay(log *k8s_log.Log) bool {
+	id := log.Container.ID()
+	c, ok := f.containers[id]
+	if !ok {
+		c = &containerReplay{received: make(map[replayKey]int)}
+		f.containers[id] = c
+	}
+	key := replayKey{timestamp: log.Timestamp.UnixNano(), content: log.ContentItem.Content()}
+
+	if c.replaying != nil {
+		if log.Timestamp.After(c.replayUntil) {
+			c.replaying = nil
+		} else if c.replaying[key] > 0 {
+			c.replaying[key]--
+			return true
+		}
+	}
+
+	// logs of the same second as the last log received may be replayed after a restart
+	second := log.Timestamp.Unix()
+	if second > c.second {
+		c.second = second
+		clear(c.received)
+	}
+	if second == c.second {
+		c.received[key]++
+	}
+	if log.Timestamp.After(c.last) {
+		c.last = log.Timestamp
+	}
+	return false
+}
+
+// Restart notes that the log stream of a container restarted from just after the last log received, replaying the
+// logs of that log's second
+func (f *ReplayFilter) Restart(ct container.Container) {
+	c, ok := f.containers[ct.ID()]
+	if !ok || len(c.received) == 0 {
+		return
+	}
+	c.replaying = make(map[replayKey]int, len(c.received))
+	for key, n := range c.received {
+		c.replaying[key] = n
+	}
+	c.replayUntil = c.last
+}
+
+// RemoveContainer forgets the logs received from a container, e.g. when its log stream starts over
+func (f *ReplayFilter) RemoveContainer(ct container.Container) {
+	delete(f.containers, ct.ID())
+}
@@ -0,0 +1,86 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+	"github.com/robinovitch61/kl/internal/model"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+func replayLog(ct container.Container, ts time.Time, content string) *k8s_log.Log {
+	return &k8s_log.Log{Timestamp: ts, Container: ct, ContentItem: item.NewItem(content)}
+}
+
+func TestReplayFilter_KeepsIdenticalLogsWithoutRestart(t *testing.T) {
+	f := model.NewReplayFilter()
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	ts := time.Unix(100, 0)
+	for i := 0; i < 3; i++ {
+		if f.IsReplay(replayLog(ct, ts, "same")) {
+			t.Fatalf("expected log %d not to be a replay", i)
+		}
+	}
+}
+
+func TestReplayFilter_SkipsReplayedSecond(t *testing.T) {
+	f := model.NewReplayFilter()
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	other := container.Container{Namespace: "ns", Pod: "pod", Name: "sidecar"}
+	second := time.Unix(100, 0)
+
+	f.IsReplay(replayLog(ct, second.Add(-time.Second), "previous second"))
+	f.IsReplay(replayLog(ct, second.Add(100*time.Millisecond), "a"))
+	f.IsReplay(replayLog(ct, second.Add(500*time.Millisecond), "b"))
+	f.IsReplay(replayLog(ct, second.Add(500*time.Millisecond), "b"))
+	f.Restart(ct)
+
+	tests := []struct {
+		log    *k8s_log.Log
+		replay bool
+	}{
+		{replayLog(other, second.Add(100*time.Millisecond), "a"), false},
+		{replayLog(ct, second.Add(100*time.Millisecond), "a"), true},
+		{replayLog(ct, second.Add(500*time.Millisecond), "b"), true},
+		{replayLog(ct, second.Add(500*time.Millisecond), "b"), true},
+		{replayLog(ct, second.Add(500*time.Millisecond), "b"), false},
+		{replayLog(ct, second.Add(700*time.Millisecond), "a"), false},
+		{replayLog(ct, second.Add(700*time.Millisecond), "a"), false},
+	}
+	for i, tt := range tests {
+		if got := f.IsReplay(tt.log); got != tt.replay {
+			t.Errorf("log %d: expected replay %v, got %v", i, tt.replay, got)
+		}
+	}
+}
+
+func TestReplayFilter_RestartsTwiceWithinSecond(t *testing.T) {
+	f := model.NewReplayFilter()
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	ts := time.Unix(100, int64(200*time.Millisecond))
+
+	f.IsReplay(replayLog(ct, ts, "a"))
+	f.Restart(ct)
+	if !f.IsReplay(replayLog(ct, ts, "a")) {
+		t.Fatal("expected first replay to be skipped")
+	}
+	f.Restart(ct)
+	if !f.IsReplay(replayLog(ct, ts, "a")) {
+		t.Fatal("expected second replay to be skipped")
+	}
+}
+
+func TestReplayFilter_RemoveContainer(t *testing.T) {
+	f := model.NewReplayFilter()
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	ts := time.Unix(100, 0)
+
+	f.IsReplay(replayLog(ct, ts, "a"))
+	f.RemoveContainer(ct)
+	f.Restart(ct)
+	if f.IsReplay(replayLog(ct, ts, "a")) {
+		t.Error("expected log not to be a replay after its container was removed")
+	}
+}
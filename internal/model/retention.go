@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// RetentionPolicy bounds the logs kept in memory. Once a limit is exceeded, the oldest logs are dropped.
+// Zero values mean no limit
+type RetentionPolicy struct {
+	// MaxLines is the maximum number of logs kept across all containers
+	MaxLines int
+	// MaxBytes is the maximum total size of log content kept across all containers
+	MaxBytes int
+	// MaxAge is the maximum age of kept logs, checked as logs are added and periodically while none are
+	MaxAge time.Duration
+	// MaxLinesPerContainer is the maximum number of logs kept for each container
+	MaxLinesPerContainer int
+	// MaxBytesPerContainer is the maximum total size of log content kept for each container
+	MaxBytesPerContainer int
+}
+
+func (p RetentionPolicy) hasPerContainerLimit() bool {
+	return p.MaxLinesPerContainer > 0 || p.MaxBytesPerContainer > 0
+}
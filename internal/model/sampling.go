@@ -0,0 +1,120 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+// SamplingPolicy limits the logs kept from each container so a single noisy container doesn't drown out the others.
+// Zero values mean no limit
+type SamplingPolicy struct {
+	// MaxLinesPerSecond is the maximum number of logs kept per container for each second of log timestamps
+	MaxLinesPerSecond int
+	// KeepOneIn keeps only every Nth log from each container
+	KeepOneIn int
+}
+
+func (p SamplingPolicy) Enabled() bool {
+	return p.MaxLinesPerSecond > 0 || p.KeepOneIn > 1
+}
+
+// LogSampler applies a SamplingPolicy to the logs of each container as they arrive, counting the logs it suppresses
+type LogSampler struct {
+	policy     SamplingPolicy
+	containers map[string]*containerSampling
+	// unlimited holds the IDs of containers the policy has been lifted for
+	unlimited map[string]bool
+}
+
+type containerSampling struct {
+	// window is the second of log timestamps currently being counted towards MaxLinesPerSecond
+	window   time.Time
+	inWindow int
+	// seen is the number of logs seen, for KeepOneIn
+	seen int
+}
+
+func NewLogSampler(policy SamplingPolicy) *LogSampler {
+	return &LogSampler{
+		policy:     policy,
+		containers: make(map[string]*containerSampling),
+		unlimited:  make(map[string]bool),
+	}
+}
+
+func (s *LogSampler) Enabled() bool {
+	return s.policy.Enabled()
+}
+
+// Keep returns whether the log should be kept under the policy
+func (s *LogSampler) Keep(log *k8s_log.Log) bool {
+	if !s.policy.Enabled() {
+		return true
+	}
+	id := log.Container.ID()
+	if s.unlimited[id] {
+		return true
+	}
+	c, ok := s.containers[id]
+	if !ok {
+		c = &containerSampling{}
+		s.containers[id] = c
+	}
+
+	c.seen++
+	if s.policy.KeepOneIn > 1 && (c.seen-1)%s.policy.KeepOneIn != 0 {
+		return false
+	}
+
+	if s.policy.MaxLinesPerSecond > 0 {
+		window := log.Timestamp.Truncate(time.Second)
+		if !window.Equal(c.window) {
+			c.window = window
+			c.inWindow = 0
+		}
+		if c.inWindow >= s.policy.MaxLinesPerSecond {
+			return false
+		}
+		c.inWindow++
+	}
+	return true
+}
+
+// ToggleLimit lifts the policy for a container, or restores it if already lifted. Returns true if the policy is now
+// lifted for the container
+func (s *LogSampler) ToggleLimit(ct container.Container) bool {
+	id := ct.ID()
+	if s.unlimited[id] {
+		delete(s.unlimited, id)
+		return false
+	}
+	s.unlimited[id] = true
+	delete(s.containers, id)
+	return true
+}
+
+// RemoveContainer forgets the sampling state of a container, e.g. when its log scanner stops
+func (s *LogSampler) RemoveContainer(ct container.Container) {
+	delete(s.containers, ct.ID())
+}
+
+// NewSuppressedLog returns a marker log taking the place of the last of n suppressed logs, so it is ordered where the
+// logs were dropped
+func NewSuppressedLog(lastSuppressed k8s_log.Log, n int) k8s_log.Log {
+	noun := "lines"
+	if n == 1 {
+		noun = "line"
+	}
+	return k8s_log.Log{
+		Timestamp:    lastSuppressed.Timestamp,
+		AppTimestamp: lastSuppressed.AppTimestamp,
+		Timestamps:   lastSuppressed.Timestamps,
+		Sequence:     lastSuppressed.Sequence,
+		Container:    lastSuppressed.Container,
+		ContentItem:  item.NewItem(fmt.Sprintf("<%d %s suppressed by rate limit>", n, noun)),
+	}
+}
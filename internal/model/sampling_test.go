@@ -0,0 +1,108 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+	"github.com/robinovitch61/kl/internal/model"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+func sampleLogs(ct container.Container, start time.Time, n int, gap time.Duration) []k8s_log.Log {
+	logs := make([]k8s_log.Log, n)
+	for i := range logs {
+		logs[i] = k8s_log.Log{
+			Timestamp:   start.Add(time.Duration(i) * gap),
+			Sequence:    uint64(i),
+			Container:   ct,
+			ContentItem: item.NewItem("log"),
+		}
+	}
+	return logs
+}
+
+func numKept(s *model.LogSampler, logs []k8s_log.Log) int {
+	kept := 0
+	for i := range logs {
+		if s.Keep(&logs[i]) {
+			kept++
+		}
+	}
+	return kept
+}
+
+func TestLogSampler_Disabled(t *testing.T) {
+	s := model.NewLogSampler(model.SamplingPolicy{})
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	if kept := numKept(s, sampleLogs(ct, time.Now(), 100, time.Millisecond)); kept != 100 {
+		t.Errorf("expected all logs kept, got %d", kept)
+	}
+	if s.Enabled() {
+		t.Error("expected sampler with empty policy to be disabled")
+	}
+}
+
+func TestLogSampler_MaxLinesPerSecond(t *testing.T) {
+	s := model.NewLogSampler(model.SamplingPolicy{MaxLinesPerSecond: 10})
+	busy := container.Container{Namespace: "ns", Pod: "pod", Name: "busy"}
+	quiet := container.Container{Namespace: "ns", Pod: "pod", Name: "quiet"}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 100 logs per second over 3 seconds
+	if kept := numKept(s, sampleLogs(busy, start, 300, 10*time.Millisecond)); kept != 30 {
+		t.Errorf("expected 10 logs kept per second, got %d", kept)
+	}
+	// limits are per container
+	if kept := numKept(s, sampleLogs(quiet, start, 5, 10*time.Millisecond)); kept != 5 {
+		t.Errorf("expected all logs from quiet container kept, got %d", kept)
+	}
+}
+
+func TestLogSampler_KeepOneIn(t *testing.T) {
+	s := model.NewLogSampler(model.SamplingPolicy{KeepOneIn: 4})
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	logs := sampleLogs(ct, time.Now(), 10, time.Millisecond)
+	var kept []uint64
+	for i := range logs {
+		if s.Keep(&logs[i]) {
+			kept = append(kept, logs[i].Sequence)
+		}
+	}
+	if len(kept) != 3 || kept[0] != 0 || kept[1] != 4 || kept[2] != 8 {
+		t.Errorf("expected logs 0, 4 and 8 kept, got %v", kept)
+	}
+}
+
+func TestLogSampler_ToggleLimit(t *testing.T) {
+	s := model.NewLogSampler(model.SamplingPolicy{MaxLinesPerSecond: 1})
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if !s.ToggleLimit(ct) {
+		t.Fatal("expected limit to be lifted")
+	}
+	if kept := numKept(s, sampleLogs(ct, start, 10, time.Millisecond)); kept != 10 {
+		t.Errorf("expected all logs kept with limit lifted, got %d", kept)
+	}
+	if s.ToggleLimit(ct) {
+		t.Fatal("expected limit to be restored")
+	}
+	if kept := numKept(s, sampleLogs(ct, start.Add(time.Second), 10, time.Millisecond)); kept != 1 {
+		t.Errorf("expected 1 log kept with limit restored, got %d", kept)
+	}
+}
+
+func TestNewSuppressedLog(t *testing.T) {
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	last := sampleLogs(ct, time.Now(), 1, 0)[0]
+	last.Sequence = 42
+	marker := model.NewSuppressedLog(last, 7)
+	if !marker.Timestamp.Equal(last.Timestamp) || marker.Sequence != 42 || !marker.Container.Equals(ct) {
+		t.Errorf("expected marker to take the place of the last suppressed log, got %+v", marker)
+	}
+	if content := marker.ContentItem.Content(); content != "<7 lines suppressed by rate limit>" {
+		t.Errorf("unexpected marker content %q", content)
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// Volume is the number of logs in each of a number of equal time ranges, from the earliest to the latest log
+type Volume struct {
+	Start  time.Time
+	End    time.Time
+	Counts []int
+}
+
+// BucketRange returns the time range of a bucket, including from and excluding to
+func (v Volume) BucketRange(i int) (from, to time.Time) {
+	return v.bucketStart(i), v.bucketStart(i + 1)
+}
+
+// BucketAt returns the bucket that includes t, clamped to the buckets
+func (v Volume) BucketAt(t time.Time) int {
+	if len(v.Counts) == 0 {
+		return 0
+	}
+	span := v.End.Sub(v.Start) + 1
+	i := int(float64(t.Sub(v.Start)) / float64(span) * float64(len(v.Counts)))
+	return min(max(i, 0), len(v.Counts)-1)
+}
+
+// bucketStart returns the start of a bucket. The end of the last bucket is just after the latest log, so it is included
+func (v Volume) bucketStart(i int) time.Time {
+	if i >= len(v.Counts) {
+		return v.End.Add(1)
+	}
+	span := v.End.Sub(v.Start) + 1
+	return v.Start.Add(time.Duration(float64(span) * float64(i) / float64(len(v.Counts))))
+}
+
+// Volume counts the logs in each of n equal time ranges from the earliest to the latest log. Logs are counted by
+// searching the ordered logs for the bounds of each range, so the cost doesn't grow with the number of logs
+func (lc PageLogContainer) Volume(n int) Volume {
+	start, end, ok := lc.TimeRange()
+	if !ok || n <= 0 {
+		return Volume{}
+	}
+	logs := lc.logs.logs()
+	v := Volume{Start: start, End: end, Counts: make([]int, n)}
+
+	// countBefore returns the number of logs with a timestamp before t
+	countBefore := func(t time.Time) int {
+		if lc.ascending {
+			return sort.Search(len(logs), func(i int) bool { return !logs[i].Log.OrderTime().Before(t) })
+		}
+		return len(logs) - sort.Search(len(logs), func(i int) bool { return logs[i].Log.OrderTime().Before(t) })
+	}
+	prev := 0
+	for i := range v.Counts {
+		next := countBefore(v.bucketStart(i + 1))
+		v.Counts[i] = next - prev
+		prev = next
+	}
+	return v
+}
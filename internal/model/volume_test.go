@@ -0,0 +1,48 @@
+package model_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/model"
+)
+
+func TestPageLogContainer_Volume(t *testing.T) {
+	ct := container.Container{Namespace: "ns", Pod: "pod", Name: "app"}
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	offsets := []time.Duration{0, time.Second, 2 * time.Second, 2 * time.Second, 7 * time.Second, 9 * time.Second}
+
+	for _, ascending := range []bool{true, false} {
+		lc := model.NewPageLogContainer(ascending)
+		for i, offset := range offsets {
+			log := makeRepeatLog(ct, start.Add(offset), "log")
+			log.Log.Sequence = uint64(i)
+			lc.AppendLog(log, nil)
+		}
+
+		v := lc.Volume(5)
+		if !v.Start.Equal(start) || !v.End.Equal(start.Add(9*time.Second)) {
+			t.Errorf("ascending=%t: expected volume from %v to %v, got %v to %v", ascending, start, start.Add(9*time.Second), v.Start, v.End)
+		}
+		if expected := []int{2, 2, 0, 1, 1}; !slices.Equal(v.Counts, expected) {
+			t.Errorf("ascending=%t: expected counts %v, got %v", ascending, expected, v.Counts)
+		}
+
+		// every log falls in the bucket found for its timestamp
+		for _, offset := range offsets {
+			from, to := v.BucketRange(v.BucketAt(start.Add(offset)))
+			if ts := start.Add(offset); ts.Before(from) || !ts.Before(to) {
+				t.Errorf("ascending=%t: expected %v in bucket %v to %v", ascending, ts, from, to)
+			}
+		}
+	}
+}
+
+func TestPageLogContainer_VolumeEmpty(t *testing.T) {
+	v := model.NewPageLogContainer(true).Volume(5)
+	if len(v.Counts) != 0 {
+		t.Errorf("expected no buckets without logs, got %v", v.Counts)
+	}
+}
@@ -66,10 +66,13 @@ func NewEntitiesPage(
 
 	exactMode := filterableviewport.ExactFilterMode(keyMap.Filter)
 	fuzzyMode := filterableviewport.FuzzyFilterMode(keyMap.FilterFuzzy)
-	filterModes := []filterableviewport.FilterMode{exactMode, fuzzyMode}
+	// entities are matched against their fields by the entity tree, so the viewport only highlights content terms
+	exprMode := filter.ExprFilterMode(keyMap.FilterExpr, nil)
+	filterModes := []filterableviewport.FilterMode{exactMode, fuzzyMode, exprMode}
 	filterModesByName := map[filterableviewport.FilterModeName]filterableviewport.FilterMode{
 		exactMode.Name: exactMode,
 		fuzzyMode.Name: fuzzyMode,
+		exprMode.Name:  exprMode,
 	}
 
 	fvp := filterableviewport.New(vp,
@@ -85,7 +88,7 @@ func NewEntitiesPage(
 		filterableviewport.WithFilterModes[entity.Entity](filterModes),
 		filterableviewport.WithMatchingItemsOnly[entity.Entity](false),
 		filterableviewport.WithCanToggleMatchingItemsOnly[entity.Entity](false),
-		filterableviewport.WithEmptyText[entity.Entity]("'/' or 'z' to filter"),
+		filterableviewport.WithEmptyText[entity.Entity]("'/', 'z', or 'e' to filter"),
 		filterableviewport.WithFilterLinePosition[entity.Entity](filterableviewport.FilterLineTop),
 		filterableviewport.WithFilterLinePrefix[entity.Entity]("(S)election"),
 		filterableviewport.WithStyles[entity.Entity](filterableviewport.Styles{
@@ -246,6 +249,11 @@ func (p EntityPage) GetSelectionActions() (entity.Entity, map[entity.Entity]bool
 	return *selectedEntity, p.entityTree.GetSelectionActions(*selectedEntity, p.getCurrentFilter())
 }
 
+// GetSelectedEntity returns the entity under the cursor, or nil if there is none
+func (p EntityPage) GetSelectedEntity() *entity.Entity {
+	return p.filterableViewport.GetSelectedItem()
+}
+
 func (p EntityPage) getVisibleEntities() []entity.Entity {
 	return p.entityTree.GetVisibleEntities(p.getCurrentFilter())
 }
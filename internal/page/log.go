@@ -218,8 +218,9 @@ func (p *SingleLogPage) updateStyles() {
 func (p SingleLogPage) WithLog(log model.PageLog) SingleLogPage {
 	needsUpdate := true
 
-	if log.Log != nil && p.log.Log != nil {
-		needsUpdate = log.Log.ContentItem.Content() != p.log.Log.ContentItem.Content()
+	// collapsed rows may have had more logs collapsed into them since last shown
+	if log.Log != nil && p.log.Log != nil && log.NumRepeats() == 1 {
+		needsUpdate = log.Log.Item().Content() != p.log.Log.Item().Content()
 	}
 
 	if !needsUpdate {
@@ -237,10 +238,33 @@ func (p SingleLogPage) WithLog(log model.PageLog) SingleLogPage {
 }
 
 func veryNicelyFormatThisLog(log model.PageLog, includeStyle bool) (string, []string) {
-	header := fmt.Sprintf("%s | %s", log.Log.Timestamps.Full, log.RenderName(log.ContainerNames.Full, includeStyle))
-	var colorize func(string) string
+	name := log.RenderName(log.ContainerNames.Full, includeStyle)
+	header := fmt.Sprintf("%s | %s", log.Log.Timestamps.Full, name)
+	if log.Log.Timestamps.App != "" {
+		// logs are ordered by the application's timestamp, so show both
+		header = fmt.Sprintf("k8s %s | app %s | %s", log.Log.Timestamps.Full, log.Log.Timestamps.App, name)
+	}
+	var colors *util.JSONColorStyles
+	if includeStyle {
+		colors = log.JSONColors()
+	}
+	content := util.PrettyPrintStructured(log.Log.Item().ContentNoAnsi(), colors)
+	if len(content) == 1 {
+		// multi-line records like stack traces
+		content = strings.Split(content[0], "\n")
+	}
+
+	// expand collapsed repeated lines
+	if n := log.NumRepeats(); n > 1 {
+		content = append(content, "", fmt.Sprintf("Repeated %d times:", n))
+		for _, repeat := range log.CollapsedLogs() {
+			content = append(content, fmt.Sprintf("%s  %s", repeat.Log.Timestamps.Full, repeat.Log.Item().ContentNoAnsi()))
+		}
+	}
 	if includeStyle {
-		colorize = log.Log.Colorize()
+		for i := range content {
+			content[i] = log.Highlight(content[i])
+		}
 	}
-	return header, util.PrettyPrintJSON(log.Log.ContentItem.ContentNoAnsi(), colorize)
+	return header, content
 }
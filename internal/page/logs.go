@@ -2,36 +2,210 @@ package page
 
 import (
 	"fmt"
+	"hash/maphash"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
+	"charm.land/bubbles/v2/cursor"
 	"charm.land/bubbles/v2/key"
 	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/robinovitch61/kl/internal/constants"
 	"github.com/robinovitch61/kl/internal/dev"
 	"github.com/robinovitch61/kl/internal/filter"
 	"github.com/robinovitch61/kl/internal/help"
 	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
 	"github.com/robinovitch61/kl/internal/k8s/k8s_model"
 	"github.com/robinovitch61/kl/internal/keymap"
+	"github.com/robinovitch61/kl/internal/logstore"
 	"github.com/robinovitch61/kl/internal/model"
 	"github.com/robinovitch61/kl/internal/style"
+	"github.com/robinovitch61/kl/internal/textinput"
+	"github.com/robinovitch61/kl/internal/util"
 	"github.com/robinovitch61/viewport/filterableviewport"
 	"github.com/robinovitch61/viewport/viewport"
+	"github.com/robinovitch61/viewport/viewport/item"
 )
 
 var (
-	timestampFormats = []string{"none", "short", "full"}
-	nameFormats      = []string{"short", "none", "full"}
+	timestampFormats = []string{model.FormatNone, model.FormatShort, model.FormatFull}
+	nameFormats      = []string{model.FormatShort, model.FormatNone, model.FormatFull}
+	collapseModes    = []string{model.CollapseNone, model.CollapseExact, model.CollapseNumbers}
+	levelThresholds  = []k8s_log.Level{k8s_log.LevelDebug, k8s_log.LevelInfo, k8s_log.LevelWarn, k8s_log.LevelError}
+	sparklineBars    = []rune("▁▂▃▄▅▆▇█")
+)
+
+const (
+	// minVolumeBuckets is the fewest buckets the volume histogram is shown with alongside its time labels
+	minVolumeBuckets = 10
+	// timeStep is how far the selection moves in time when stepping back or forward
+	timeStep = time.Minute
 )
 
 type LogsPage struct {
 	filterableViewport *filterableviewport.Model[model.PageLog]
 	keyMap             keymap.KeyMap
 	logContainer       *model.PageLogContainer
+	// display is shared by all logs in the page, so display changes only require the viewport to re-render
+	display            *model.PageLogDisplay
+	containerNames     map[string]*containerNames
 	timestampFormatIdx int
 	nameFormatIdx      int
-	prettyPrint        bool
-	theme              style.Theme
-	focused            bool
-	viewWhenEmpty      string
+	collapseModeIdx    int
+	// levelThresholdIdx is the minimum level of the logs shown and exported
+	levelThresholdIdx int
+	// collapser collapses repeated lines into single rows, nil if repeated lines are shown as is
+	collapser *model.RepeatCollapser
+	// viewportLogs are the logs handed to the viewport, shared with the filter modes matching logs by more than content
+	viewportLogs *viewportLogs
+	// showVolume shows a histogram of log volume over time above the logs
+	showVolume bool
+	// volumeCursor is a time in the selected bucket of the volume histogram, zero if no bucket is selected
+	volumeCursor time.Time
+	// jumpInput is the time to jump to, focused while it is being entered
+	jumpInput textinput.Model
+	// jumpErr is why the time entered couldn't be jumped to
+	jumpErr error
+	// columnsInput is the comma-separated fields to show as columns, focused while they are being entered
+	columnsInput textinput.Model
+	// excludes hide the logs they match from view and export, in the order they were added
+	excludes []model.LogFilter
+	// excludeInput is the text or /regex/ of logs to exclude, focused while it is being entered
+	excludeInput textinput.Model
+	// excludeErr is why the exclusion entered is invalid
+	excludeErr error
+	// highlightInput is the text or /regex/ of a highlight rule to add or remove, focused while the rules are managed
+	highlightInput textinput.Model
+	// highlightIdx is the index of the rule chosen in the list of highlight rules, -1 if none is chosen
+	highlightIdx int
+	// highlightErr is why the highlight rule entered is invalid
+	highlightErr  error
+	theme         style.Theme
+	focused       bool
+	viewWhenEmpty string
+}
+
+// containerNames are the names shared by the logs of a container, one per run of the container,
+// as the logs of a terminated run stay marked as terminated after the container restarts
+type containerNames struct {
+	container container.Container
+	runs      []*model.PageLogContainerNames
+}
+
+// viewportLogs mirror the logs handed to the viewport, so filter modes that only see the content they match can
+// resolve the log it belongs to
+type viewportLogs struct {
+	logs []model.PageLog
+	// index locates logs by the hash of the content the viewport matches them by. It is only built while a filter
+	// mode resolves logs, and covers the first len(hashes) logs
+	index  map[uint64][]int
+	hashes []uint64
+	seed   maphash.Seed
+	// appendedFrom is the index of the first log being appended while the viewport matches appended logs, telling
+	// identically rendered logs apart
+	appendedFrom int
+}
+
+func newViewportLogs(logs []model.PageLog) *viewportLogs {
+	return &viewportLogs{logs: logs, seed: maphash.MakeSeed()}
+}
+
+// set replaces the logs, dropping the index
+func (v *viewportLogs) set(logs []model.PageLog) {
+	v.logs = logs
+	v.index = nil
+	v.hashes = nil
+}
+
+// scanFields returns a function resolving the fields of the log whose content is matched
+func (v *viewportLogs) scanFields() func(string) filter.Fields {
+	resolve := v.resolver()
+	return func(content string) filter.Fields {
+		if l, ok := resolve(content); ok {
+			return l.FilterFields()
+		}
+		return nil
+	}
+}
+
+// scanContents returns a function resolving the content of the log whose content is matched, without any prefix or
+// columns it is rendered with
+func (v *viewportLogs) scanContents() func(string) string {
+	resolve := v.resolver()
+	return func(content string) string {
+		if l, ok := resolve(content); ok {
+			return l.Log.Item().ContentNoAnsi()
+		}
+		return content
+	}
+}
+
+// resolver returns a function resolving the log the viewport renders as the given content. Logs rendered
+// identically are resolved in turn, as the viewport matches logs in order
+func (v *viewportLogs) resolver() func(string) (model.PageLog, bool) {
+	v.indexLogs()
+	from := v.appendedFrom
+	turns := make(map[uint64]int)
+	reindexed := false
+	return func(content string) (model.PageLog, bool) {
+		h := maphash.String(v.seed, content)
+		idxs, ok := v.index[h]
+		if !ok && !reindexed {
+			// a log's rendering changed since it was indexed
+			reindexed = true
+			v.index, v.hashes = nil, nil
+			v.indexLogs()
+			idxs, ok = v.index[h]
+		}
+		if !ok {
+			return model.PageLog{}, false
+		}
+		if len(idxs) > 1 {
+			if j := sort.SearchInts(idxs, from); j < len(idxs) {
+				idxs = idxs[j:]
+			}
+			i := turns[h] % len(idxs)
+			turns[h]++
+			return v.logs[idxs[i]], true
+		}
+		return v.logs[idxs[0]], true
+	}
+}
+
+// update re-indexes the log at index i after its rendering changed
+func (v *viewportLogs) update(i int) {
+	if i >= len(v.hashes) {
+		return
+	}
+	prev := v.hashes[i]
+	idxs := v.index[prev]
+	if j := sort.SearchInts(idxs, i); j < len(idxs) && idxs[j] == i {
+		idxs = slices.Delete(idxs, j, j+1)
+	}
+	if len(idxs) == 0 {
+		delete(v.index, prev)
+	} else {
+		v.index[prev] = idxs
+	}
+	h := maphash.String(v.seed, v.logs[i].GetItem().ContentNoAnsi())
+	idxs = v.index[h]
+	v.index[h] = slices.Insert(idxs, sort.SearchInts(idxs, i), i)
+	v.hashes[i] = h
+}
+
+// indexLogs indexes the logs appended since the index was last built
+func (v *viewportLogs) indexLogs() {
+	if v.index == nil {
+		v.index = make(map[uint64][]int, len(v.logs))
+	}
+	for i := len(v.hashes); i < len(v.logs); i++ {
+		h := maphash.String(v.seed, v.logs[i].GetItem().ContentNoAnsi())
+		v.index[h] = append(v.index[h], i)
+		v.hashes = append(v.hashes, h)
+	}
 }
 
 // assert LogsPage implements GenericPage
@@ -44,6 +218,13 @@ func NewLogsPage(
 	theme style.Theme,
 ) LogsPage {
 	lc := model.NewPageLogContainer(!descending)
+	display := &model.PageLogDisplay{
+		TimestampFormat: timestampFormats[0],
+		NameFormat:      nameFormats[0],
+		JSONColors:      jsonColors(theme),
+	}
+	names := make(map[string]*containerNames)
+	vpLogs := newViewportLogs(lc.GetOrderedLogs())
 
 	vp := viewport.New[model.PageLog](width, height,
 		viewport.WithKeyMap[model.PageLog](viewport.KeyMap{
@@ -76,12 +257,15 @@ func NewLogsPage(
 		}),
 		filterableviewport.WithFilterModes[model.PageLog]([]filterableviewport.FilterMode{
 			filterableviewport.ExactFilterMode(keyMap.Filter),
+			filterableviewport.FuzzyFilterMode(keyMap.FilterFuzzy),
 			filterableviewport.RegexFilterMode(keyMap.FilterRegex),
 			filterableviewport.CaseInsensitiveFilterMode(keyMap.FilterCaseInsensitive),
+			filter.JQFilterMode(keyMap.FilterJQ, vpLogs.scanContents),
+			filter.ExprFilterMode(keyMap.FilterExpr, vpLogs.scanFields),
 		}),
 		filterableviewport.WithMatchingItemsOnly[model.PageLog](false), // ShowContext=true equivalent
 		filterableviewport.WithCanToggleMatchingItemsOnly[model.PageLog](true),
-		filterableviewport.WithEmptyText[model.PageLog]("'/', 'r', or 'i' to filter"),
+		filterableviewport.WithEmptyText[model.PageLog]("'/', 'z', 'r', 'i', 'J', or 'e' to filter"),
 		filterableviewport.WithFilterLinePosition[model.PageLog](filterableviewport.FilterLineTop),
 		filterableviewport.WithItemDescriptor[model.PageLog]("logs"),
 		filterableviewport.WithFilterLinePrefix[model.PageLog](fmt.Sprintf("(L)ogs, %s", getOrder(!descending))),
@@ -96,17 +280,45 @@ func NewLogsPage(
 	)
 
 	// Set initial logs
-	fvp.SetObjects(lc.GetOrderedLogs())
+	fvp.SetObjects(vpLogs.logs)
 
 	// Set selection comparator for maintaining selection
 	fvp.SetSelectionComparator(func(a, b model.PageLog) bool {
 		return a.Equals(b)
 	})
 
+	jumpInput := textinput.New()
+	jumpInput.Prompt = "Go to time: "
+	jumpInput.Placeholder = "-5m, 10:32:15 or 2024-01-02 10:32:15"
+	jumpInput.Cursor.SetMode(cursor.CursorStatic)
+
+	columnsInput := textinput.New()
+	columnsInput.Prompt = "Columns: "
+	columnsInput.Placeholder = "level,msg,http.status or empty for none"
+	columnsInput.Cursor.SetMode(cursor.CursorStatic)
+
+	excludeInput := textinput.New()
+	excludeInput.Prompt = "Exclude: "
+	excludeInput.Placeholder = "text or /regex/ to hide, an excluded one to restore, or empty to restore all"
+	excludeInput.Cursor.SetMode(cursor.CursorStatic)
+
+	highlightInput := textinput.New()
+	highlightInput.Prompt = "Highlight: "
+	highlightInput.Placeholder = "text or /regex/ to add, a highlighted one to remove, ↑/↓ to choose one"
+	highlightInput.Cursor.SetMode(cursor.CursorStatic)
+
 	page := LogsPage{
 		filterableViewport: fvp,
+		jumpInput:          jumpInput,
+		columnsInput:       columnsInput,
+		excludeInput:       excludeInput,
+		highlightInput:     highlightInput,
+		highlightIdx:       -1,
 		keyMap:             keyMap,
 		logContainer:       lc,
+		display:            display,
+		containerNames:     names,
+		viewportLogs:       vpLogs,
 		timestampFormatIdx: 0,
 		nameFormatIdx:      0,
 		theme:              theme,
@@ -127,23 +339,88 @@ func (p LogsPage) Update(msg tea.Msg) (GenericPage, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if p.jumpInput.Focused() {
+			return p.updateJumpInput(msg)
+		}
+		if p.columnsInput.Focused() {
+			return p.updateColumnsInput(msg)
+		}
+		if p.excludeInput.Focused() {
+			return p.updateExcludeInput(msg)
+		}
+		if p.highlightInput.Focused() {
+			return p.updateHighlightInput(msg)
+		}
 		if p.HighjackingInput() {
 			p.filterableViewport, cmd = p.filterableViewport.Update(msg)
 			cmds = append(cmds, cmd)
+			// the filter text or mode may have changed, so show or clear its error
+			p.updateFilterLabel()
 			return p, tea.Batch(cmds...)
 		}
 		if key.Matches(msg, p.keyMap.Wrap) {
 			p.filterableViewport.SetWrapText(!p.filterableViewport.GetWrapText())
-			if !p.filterableViewport.GetWrapText() && p.prettyPrint {
-				p.prettyPrint = false
-				p.updatePrettyPrintOnLogs()
+			if !p.filterableViewport.GetWrapText() && p.display.PrettyPrint {
+				p.display.PrettyPrint = false
+				p.refreshLogs()
 			}
 			return p, nil
 		}
+		if key.Matches(msg, p.keyMap.Volume) {
+			p.showVolume = !p.showVolume
+			p.volumeCursor = time.Time{}
+			p.updateHeader()
+			return p, nil
+		}
+		if p.showVolume && key.Matches(msg, p.keyMap.VolumePrev) {
+			p.moveVolumeCursor(-1)
+			return p, nil
+		}
+		if p.showVolume && key.Matches(msg, p.keyMap.VolumeNext) {
+			p.moveVolumeCursor(1)
+			return p, nil
+		}
+		if key.Matches(msg, p.keyMap.JumpToTime) && p.logContainer.Len() > 0 {
+			p.jumpInput.Reset()
+			p.jumpErr = nil
+			cmd = p.jumpInput.Focus()
+			p.updateHeader()
+			return p, cmd
+		}
+		if key.Matches(msg, p.keyMap.Columns) {
+			p.columnsInput.SetValue(strings.Join(p.display.Columns, ","))
+			p.columnsInput.CursorEnd()
+			cmd = p.columnsInput.Focus()
+			p.updateHeader()
+			return p, cmd
+		}
+		if key.Matches(msg, p.keyMap.Highlights) {
+			p.highlightInput.Reset()
+			p.highlightIdx = -1
+			p.highlightErr = nil
+			cmd = p.highlightInput.Focus()
+			p.updateHeader()
+			return p, cmd
+		}
+		if key.Matches(msg, p.keyMap.Exclude) {
+			p.excludeInput.Reset()
+			p.excludeErr = nil
+			cmd = p.excludeInput.Focus()
+			p.updateHeader()
+			return p, cmd
+		}
+		if key.Matches(msg, p.keyMap.TimeBack) {
+			p.stepTime(-timeStep)
+			return p, nil
+		}
+		if key.Matches(msg, p.keyMap.TimeForward) {
+			p.stepTime(timeStep)
+			return p, nil
+		}
 		if key.Matches(msg, p.keyMap.PrettyPrint) {
-			p.prettyPrint = !p.prettyPrint
-			p.updatePrettyPrintOnLogs()
-			if p.prettyPrint && !p.filterableViewport.GetWrapText() {
+			p.display.PrettyPrint = !p.display.PrettyPrint
+			p.refreshLogs()
+			if p.display.PrettyPrint && !p.filterableViewport.GetWrapText() {
 				p.filterableViewport.SetWrapText(true)
 			}
 			return p, nil
@@ -152,11 +429,12 @@ func (p LogsPage) Update(msg tea.Msg) (GenericPage, tea.Cmd) {
 
 	p.filterableViewport, cmd = p.filterableViewport.Update(msg)
 	cmds = append(cmds, cmd)
+	p.loadOlderIfOldestSelected()
 	return p, tea.Batch(cmds...)
 }
 
 func (p LogsPage) View() string {
-	if len(p.logContainer.GetOrderedLogs()) == 0 {
+	if p.logContainer.Len() == 0 {
 		if p.focused {
 			return p.theme.FilterPrefixFocused.Render(p.viewWhenEmpty)
 		}
@@ -166,7 +444,8 @@ func (p LogsPage) View() string {
 }
 
 func (p LogsPage) HighjackingInput() bool {
-	return p.filterableViewport.IsCapturingInput()
+	return p.filterableViewport.IsCapturingInput() || p.jumpInput.Focused() || p.columnsInput.Focused() ||
+		p.excludeInput.Focused() || p.highlightInput.Focused()
 }
 
 func (p LogsPage) ContentForFile() []string {
@@ -182,15 +461,47 @@ func (p LogsPage) ContentForFile() []string {
 		}
 	}
 
-	for _, l := range p.logContainer.GetOrderedLogs() {
+	if p.display.ColumnsTSV && len(p.display.Columns) > 0 {
+		content = append(content, p.display.TSVHeader())
+	}
+	isShown := p.isShownFunc()
+	lineFor := func(l model.PageLog) (string, bool) {
+		if !isShown(l) {
+			return "", false
+		}
 		line := l.ContentForFile()
-		if !matchingOnly || filterText == "" {
-			content = append(content, line)
-		} else if f.Matches(line) {
-			content = append(content, line)
+		if matchingOnly && filterText != "" && !f.MatchesLog(line, l.FilterFields(), l.Log.Item().ContentNoAnsi()) {
+			return "", false
+		}
+		return line, true
+	}
+	lines := func(logs []model.PageLog) []string {
+		var res []string
+		for _, l := range logs {
+			if line, ok := lineFor(l); ok {
+				res = append(res, line)
+			}
+		}
+		return res
+	}
+
+	// the logs only on disk are older than those in memory, read back a page at a time
+	var older []string
+	err := p.logContainer.EachOlderOnDisk(constants.DiskStorePageLines, func(l model.PageLog) {
+		if line, ok := lineFor(p.prepareLoaded(l)); ok {
+			older = append(older, line)
 		}
+	})
+	if err != nil {
+		dev.Debug(fmt.Sprintf("error reading logs from disk: %v", err))
 	}
-	return content
+	if p.logContainer.Ascending() {
+		content = append(content, older...)
+		return append(content, lines(p.logContainer.GetOrderedLogs())...)
+	}
+	slices.Reverse(older)
+	content = append(content, lines(p.logContainer.GetOrderedLogs())...)
+	return append(content, older...)
 }
 
 func (p LogsPage) HasAppliedFilter() bool {
@@ -206,6 +517,7 @@ func (p LogsPage) ToggleShowContext() GenericPage {
 func (p LogsPage) WithDimensions(width, height int) GenericPage {
 	p.filterableViewport.SetWidth(width)
 	p.filterableViewport.SetHeight(height)
+	p.updateHeader()
 	return p
 }
 
@@ -223,6 +535,9 @@ func (p LogsPage) WithBlur() GenericPage {
 
 func (p LogsPage) WithTheme(theme style.Theme) GenericPage {
 	p.theme = theme
+	// logs are recolorized with the new colors as they are rendered
+	p.display.JSONColors = jsonColors(theme)
+	p.refreshLogs()
 	p.updateStyles()
 	p.filterableViewport.SetFilterableViewportStyles(filterableviewport.Styles{
 		Match: filterableviewport.MatchStyles{
@@ -238,11 +553,53 @@ func (p LogsPage) Help() string {
 	return help.MakeHelp(p.keyMap, p.theme.HelpKeyColumn)
 }
 
+func (p LogsPage) WithDiskStore(store *logstore.DiskStore, maxInMemory int) LogsPage {
+	p.logContainer.SetDiskStore(store, maxInMemory)
+	return p
+}
+
+func (p LogsPage) WithRetentionPolicy(retention model.RetentionPolicy) LogsPage {
+	p.logContainer.SetRetentionPolicy(retention)
+	p.refreshLogs()
+	p.updateFilterLabel()
+	return p
+}
+
+// WithLogFilter applies the filter if it has a value, and hides the logs matching its exclusions along with those
+// already hidden
 func (p LogsPage) WithLogFilter(lf model.LogFilter) LogsPage {
-	p.filterableViewport.SetFilter(lf.Value, lf.Mode)
+	if lf.Value != "" {
+		p.filterableViewport.SetFilter(lf.Value, lf.Mode)
+	}
+	if len(lf.Excludes) == 0 {
+		return p
+	}
+	for _, e := range lf.Excludes {
+		if p.excludeIdx(e) < 0 {
+			p.excludes = append(p.excludes, e)
+		}
+	}
+	p.updateFilterLabel()
+	p.refreshLogs()
+	return p
+}
+
+// WithHighlights highlights the text matched by each rule in its own style, regardless of the filter
+func (p LogsPage) WithHighlights(rules []model.HighlightRule) LogsPage {
+	p.setHighlights(rules)
 	return p
 }
 
+// Patterns groups the logs in the page into patterns, returning them along with the number of logs grouped
+func (p LogsPage) Patterns() ([]*model.LogPattern, int) {
+	clusterer := model.NewPatternClusterer()
+	logs := p.logContainer.GetOrderedLogs()
+	for _, l := range logs {
+		clusterer.Add(l.Log.Item().ContentNoAnsi(), l.Log.Container.HumanReadable(), l.Log.Timestamp)
+	}
+	return clusterer.Patterns(), len(logs)
+}
+
 func (p LogsPage) GetSelectedLog() *model.PageLog {
 	return p.filterableViewport.GetSelectedItem()
 }
@@ -264,105 +621,247 @@ func (p LogsPage) WithAppendedLogs(logs []model.PageLog) LogsPage {
 	defer dev.Debug("Done appending logs")
 
 	prevLen := p.logContainer.Len()
+	prevDropped := p.logContainer.NumDropped()
+	prevRemoved, prevNotOldest := p.logContainer.NumRemoved()
+	if p.logContainer.NumLoaded() > 0 && p.isFollowing() {
+		// back at the most recent logs, so the older logs loaded back from disk aren't needed in memory
+		p.logContainer.UnloadOlder()
+	}
 
-	// Check if all new logs are >= the current last timestamp.
+	// Check if all new logs sort after the current last log.
 	// If ascending and this holds, new logs land at the end of the ordered
 	// list and we can use the more efficient AppendObjects path.
 	canAppend := p.logContainer.Ascending() && prevLen > 0 && len(logs) > 0
 	if canAppend {
-		if lastTS, ok := p.logContainer.LastTimestamp(); ok {
-			for i := range logs {
-				if logs[i].Log.Timestamp.Before(lastTS) {
-					canAppend = false
-					break
-				}
+		for i := range logs {
+			if !p.logContainer.SortsAfterLast(logs[i]) {
+				canAppend = false
+				break
 			}
 		}
 	}
 
 	for i := range logs {
-		logs[i].CurrentTimestamp = getLogTimestamp(logs[i], timestampFormats[p.timestampFormatIdx])
-		logs[i].CurrentName = getContainerName(logs[i], nameFormats[p.nameFormatIdx])
-		logs[i].PrettyPrinted = p.prettyPrint
+		logs[i].Display = p.display
+		logs[i].ContainerNames = p.sharedContainerNames(logs[i])
 		p.logContainer.AppendLog(logs[i], nil)
 	}
+	p.display.FitColumns(logs)
 
+	// the ordered logs are not copied, and appending them to the viewport's logs only writes the same logs to the
+	// same positions, so the cost is proportional to the number of new logs
 	orderedLogs := p.logContainer.GetOrderedLogs()
 
-	if canAppend && len(orderedLogs) == prevLen+len(logs) {
-		p.filterableViewport.AppendObjects(orderedLogs[prevLen:])
-	} else {
-		p.filterableViewport.SetObjects(orderedLogs)
+	// logs dropped by the retention policy or moved to disk must also be removed from the viewport. As all new logs
+	// sort after the previous ones, they are only removed once all previous ones are, and otherwise are all at the end
+	removed, notOldest := p.logContainer.NumRemoved()
+	numRemoved := removed - prevRemoved
+	appended := canAppend && numRemoved <= prevLen && len(orderedLogs) == prevLen+len(logs)-numRemoved
+	switch {
+	case appended && numRemoved == 0:
+		newLogs := p.shown(orderedLogs[prevLen:])
+		if p.collapser != nil {
+			rows, updated := p.collapser.Append(newLogs)
+			if p.updateCollapsedRows(updated) {
+				p.setViewportLogs(p.collapser.Rows())
+			} else {
+				p.appendViewportLogs(rows)
+			}
+		} else {
+			p.appendViewportLogs(newLogs)
+		}
+	case appended && notOldest == prevNotOldest && p.trimViewportLogs(orderedLogs[len(orderedLogs)-len(logs):]):
+	default:
+		p.refreshLogs()
 	}
+	if numRemoved > 0 || p.logContainer.NumDropped() != prevDropped {
+		p.updateFilterLabel()
+	}
+	p.updateHeader()
 
 	return p
 }
 
+// WithExpiredLogsDropped drops the logs older than the retention policy's MaxAge, which logs otherwise only reach as
+// more logs are appended
+func (p LogsPage) WithExpiredLogsDropped(now time.Time) LogsPage {
+	prevDropped := p.logContainer.NumDropped()
+	prevRemoved, prevNotOldest := p.logContainer.NumRemoved()
+	p.logContainer.DropExpired(now)
+	if p.logContainer.NumDropped() == prevDropped {
+		return p
+	}
+	// logs dropped from disk alone aren't in the viewport
+	if removed, notOldest := p.logContainer.NumRemoved(); removed != prevRemoved &&
+		(notOldest != prevNotOldest || !p.trimViewportLogs(nil)) {
+		p.refreshLogs()
+	}
+	p.updateFilterLabel()
+	p.updateHeader()
+	return p
+}
+
 func (p LogsPage) WithUpdatedShortNames(f func(container.Container) (k8s_model.ContainerNameAndPrefix, error)) (LogsPage, error) {
-	allLogs := p.logContainer.GetOrderedLogs()
-	for i := range allLogs {
-		shortName, err := f(allLogs[i].Log.Container)
+	for _, names := range p.containerNames {
+		shortName, err := f(names.container)
 		if err != nil {
 			return p, err
 		}
-		allLogs[i].ContainerNames.Short = shortName
-		allLogs[i].CurrentName = getContainerName(allLogs[i], nameFormats[p.nameFormatIdx])
+		for _, run := range names.runs {
+			run.Short = shortName
+		}
 	}
-	p.setLogs(allLogs)
+	p.refreshLogs()
 	return p, nil
 }
 
 func (p LogsPage) WithLogsRemovedForContainer(containerSpec container.Container) LogsPage {
-	allLogs := p.logContainer.GetOrderedLogs()
-	var newLogs []model.PageLog
-	for _, log := range allLogs {
-		if !log.Log.Container.Equals(containerSpec) {
-			newLogs = append(newLogs, log)
-		}
-	}
-	p.setLogs(newLogs)
+	p.logContainer.RemoveLogsForContainer(containerSpec)
+	delete(p.containerNames, containerSpec.ID())
+	p.refreshLogs()
 	return p
 }
 
 func (p LogsPage) WithLogsTerminatedForContainer(containerSpec container.Container) LogsPage {
-	allLogs := p.logContainer.GetOrderedLogs()
-	for i := range allLogs {
-		if allLogs[i].Log.Container.Equals(containerSpec) {
-			allLogs[i].Terminated = true
+	if names, ok := p.containerNames[containerSpec.ID()]; ok {
+		for _, run := range names.runs {
+			run.Terminated = true
 		}
 	}
-	p.setLogs(allLogs)
+	p.refreshLogs()
+	return p
+}
+
+// WithColumns shows the given fields of JSON and logfmt logs as aligned columns, or the logs' content if columns is
+// empty. If tsv is true, columns are saved to file separated by tabs
+func (p LogsPage) WithColumns(columns []string, tsv bool) LogsPage {
+	p.display.ColumnsTSV = tsv
+	p.setColumns(columns)
 	return p
 }
 
 func (p LogsPage) WithNewTimestampFormat() LogsPage {
 	p.timestampFormatIdx = (p.timestampFormatIdx + 1) % len(timestampFormats)
-	allLogs := p.logContainer.GetOrderedLogs()
-	for i := range allLogs {
-		allLogs[i].CurrentTimestamp = getLogTimestamp(allLogs[i], timestampFormats[p.timestampFormatIdx])
-	}
-	p.setLogs(allLogs)
+	p.display.TimestampFormat = timestampFormats[p.timestampFormatIdx]
+	p.refreshLogs()
 	return p
 }
 
 func (p LogsPage) WithNewNameFormat() LogsPage {
 	p.nameFormatIdx = (p.nameFormatIdx + 1) % len(nameFormats)
-	allLogs := p.logContainer.GetOrderedLogs()
-	for i := range allLogs {
-		allLogs[i].CurrentName = getContainerName(allLogs[i], nameFormats[p.nameFormatIdx])
+	p.display.NameFormat = nameFormats[p.nameFormatIdx]
+	p.refreshLogs()
+	return p
+}
+
+// WithNextCollapseMode cycles between showing repeated lines as is, collapsing identical consecutive lines from a
+// container, and collapsing lines that are identical ignoring numbers
+func (p LogsPage) WithNextCollapseMode() LogsPage {
+	p.collapseModeIdx = (p.collapseModeIdx + 1) % len(collapseModes)
+	switch collapseModes[p.collapseModeIdx] {
+	case model.CollapseExact:
+		p.collapser = model.NewRepeatCollapser(false)
+	case model.CollapseNumbers:
+		p.collapser = model.NewRepeatCollapser(true)
+	default:
+		p.collapser = nil
 	}
-	p.setLogs(allLogs)
+	p.updateFilterLabel()
+	p.refreshLogs()
 	return p
 }
 
+// WithNextLevelThreshold cycles the minimum level of the logs shown and exported
+func (p LogsPage) WithNextLevelThreshold() LogsPage {
+	p.levelThresholdIdx = (p.levelThresholdIdx + 1) % len(levelThresholds)
+	p.updateFilterLabel()
+	p.refreshLogs()
+	return p
+}
+
+// hidesLogs returns true if a minimum level or exclusions hide logs
+func (p LogsPage) hidesLogs() bool {
+	return p.levelThresholdIdx > 0 || len(p.excludes) > 0
+}
+
+// shown returns the logs at or above the minimum level that no exclusion matches. Without a minimum level or
+// exclusions, the logs are returned as is rather than copied
+func (p LogsPage) shown(logs []model.PageLog) []model.PageLog {
+	if !p.hidesLogs() {
+		return logs
+	}
+	isShown := p.isShownFunc()
+	var shown []model.PageLog
+	for _, l := range logs {
+		if isShown(l) {
+			shown = append(shown, l)
+		}
+	}
+	return shown
+}
+
+// isShownFunc returns a func reporting if a log is at or above the minimum level and matched by no exclusion
+func (p LogsPage) isShownFunc() func(model.PageLog) bool {
+	threshold := levelThresholds[p.levelThresholdIdx]
+	excludes := p.excludeMatchFuncs()
+	return func(l model.PageLog) bool {
+		if p.levelThresholdIdx > 0 && !l.Log.Level.AtLeast(threshold) {
+			return false
+		}
+		return len(excludes) == 0 || !matchesAny(excludes, l.Log.Item().ContentNoAnsi())
+	}
+}
+
+// excludeMatchFuncs returns a MatchFunc per exclusion, compiled once rather than for every log matched
+func (p LogsPage) excludeMatchFuncs() []filterableviewport.MatchFunc {
+	var matchFuncs []filterableviewport.MatchFunc
+	for _, e := range p.excludes {
+		for _, mode := range p.filterableViewport.FilterModes() {
+			if mode.Name != e.Mode {
+				continue
+			}
+			if matchFunc, err := mode.GetMatchFunc(e.Value); err == nil {
+				matchFuncs = append(matchFuncs, matchFunc)
+			}
+		}
+	}
+	return matchFuncs
+}
+
+func matchesAny(matchFuncs []filterableviewport.MatchFunc, content string) bool {
+	for _, matchFunc := range matchFuncs {
+		if len(matchFunc(content)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeIdx returns the index of the exclusion with the same value and mode as e, or -1 if there is none
+func (p LogsPage) excludeIdx(e model.LogFilter) int {
+	return slices.IndexFunc(p.excludes, func(other model.LogFilter) bool {
+		return other.Value == e.Value && other.Mode == e.Mode
+	})
+}
+
+// toggleExclude hides the logs matching e, or shows them again if they are already hidden
+func (p *LogsPage) toggleExclude(e model.LogFilter) {
+	if i := p.excludeIdx(e); i >= 0 {
+		// copied, as earlier copies of the page share the exclusions
+		p.excludes = slices.Delete(slices.Clone(p.excludes), i, i+1)
+	} else {
+		p.excludes = append(slices.Clip(p.excludes), e)
+	}
+	p.updateFilterLabel()
+	p.refreshLogs()
+}
+
 func (p LogsPage) WithReversedLogOrder() LogsPage {
 	// switch the log order
 	p.logContainer.ToggleAscending()
 	p.setStickynessBasedOnOrder()
 	p.updateFilterLabel()
-
-	// reinsert the logs with the updated comparator and update the viewport
-	p.setLogs(p.logContainer.GetOrderedLogs())
+	p.refreshLogs()
 	return p
 }
 
@@ -377,12 +876,475 @@ func (p LogsPage) WithNoStickyness() LogsPage {
 	return p
 }
 
-func (p *LogsPage) setLogs(newLogs []model.PageLog) {
-	p.logContainer.RemoveAllLogs()
-	for i := range newLogs {
-		p.logContainer.AppendLog(newLogs[i], nil)
+// refreshLogs hands the current logs to the viewport so it re-renders and re-filters them, without copying them
+// unless repeated lines are collapsed
+func (p *LogsPage) refreshLogs() {
+	logs := p.shown(p.logContainer.GetOrderedLogs())
+	if p.collapser != nil {
+		p.setViewportLogs(p.collapser.Rebuild(logs))
+	} else {
+		p.setViewportLogs(logs)
 	}
-	p.filterableViewport.SetObjects(p.logContainer.GetOrderedLogs())
+	p.updateHeader()
+}
+
+// trimViewportLogs removes the oldest logs removed from memory from the start of the viewport's logs and appends
+// newLogs, rather than filtering and collapsing all logs kept again. It returns false if the logs must be refreshed
+// instead, as they are in descending order or a collapsed row holds both removed and kept logs
+func (p *LogsPage) trimViewportLogs(newLogs []model.PageLog) bool {
+	if !p.logContainer.Ascending() {
+		return false
+	}
+	if p.collapser != nil {
+		if !p.collapser.TrimHead(p.logContainer.OlderThanOldest) {
+			return false
+		}
+		p.collapser.Append(p.shown(newLogs))
+		p.setViewportLogs(p.collapser.Rows())
+		return true
+	}
+	if !p.hidesLogs() {
+		// the viewport's logs are the ordered logs, which are already trimmed
+		p.setViewportLogs(p.logContainer.GetOrderedLogs())
+		return true
+	}
+	rows := p.viewportLogs.logs
+	numDropped := sort.Search(len(rows), func(i int) bool {
+		return !p.logContainer.OlderThanOldest(rows[i])
+	})
+	p.setViewportLogs(append(rows[numDropped:], p.shown(newLogs)...))
+	return true
+}
+
+// setViewportLogs hands logs to the viewport, replacing its logs
+func (p *LogsPage) setViewportLogs(logs []model.PageLog) {
+	p.viewportLogs.set(logs)
+	p.filterableViewport.SetObjects(logs)
+}
+
+// appendViewportLogs appends logs to the viewport's logs, only scanning the logs appended for matches
+func (p *LogsPage) appendViewportLogs(logs []model.PageLog) {
+	if len(logs) == 0 {
+		// the viewport updates all its highlights when appended to, even if nothing is appended
+		return
+	}
+	p.viewportLogs.appendedFrom = len(p.viewportLogs.logs)
+	p.viewportLogs.logs = append(p.viewportLogs.logs, logs...)
+	p.filterableViewport.AppendObjects(logs)
+	p.viewportLogs.appendedFrom = 0
+}
+
+// updateCollapsedRows re-indexes the rows more logs were collapsed into in place, returning true if they match the
+// filter differently than before, as the viewport only matches rows again when they are handed to it again
+func (p *LogsPage) updateCollapsedRows(updated []model.UpdatedRow) bool {
+	if len(updated) == 0 {
+		return false
+	}
+	var matchFunc filterableviewport.MatchFunc
+	filterText := p.filterableViewport.GetFilterText()
+	if mode := p.filterableViewport.GetActiveFilterMode(); mode != nil && filterText != "" {
+		// a filter that is invalid matches nothing, however the rows change
+		matchFunc, _ = mode.GetMatchFunc(filterText)
+	}
+	var before [][]item.ByteRange
+	if matchFunc != nil {
+		for _, u := range updated {
+			before = append(before, matchFunc(u.Before.GetItem().ContentNoAnsi()))
+		}
+	}
+	for _, u := range updated {
+		p.viewportLogs.update(u.Idx)
+	}
+	if matchFunc == nil {
+		return false
+	}
+	for i, u := range updated {
+		if !slices.Equal(before[i], matchFunc(p.viewportLogs.logs[u.Idx].GetItem().ContentNoAnsi())) {
+			return true
+		}
+	}
+	return false
+}
+
+// volumeBuckets returns the number of buckets in the volume histogram and whether there is room for time labels
+func (p *LogsPage) volumeBuckets() (int, bool) {
+	width := p.filterableViewport.GetWidth()
+	labelsWidth := 2 * (len(time.TimeOnly) + 1)
+	if width-labelsWidth < minVolumeBuckets {
+		return max(width, 1), false
+	}
+	return width - labelsWidth, true
+}
+
+// updateHeader shows the time being jumped to, the columns or exclusion being entered, the highlight rules being
+// managed and the volume histogram above the logs, if they are visible
+func (p *LogsPage) updateHeader() {
+	var header []string
+	if p.jumpInput.Focused() {
+		line := p.jumpInput.View()
+		if p.jumpErr != nil {
+			line += "  " + p.theme.Error.Render(p.jumpErr.Error())
+		}
+		header = append(header, line)
+	}
+	if p.columnsInput.Focused() {
+		header = append(header, p.columnsInput.View())
+	}
+	if p.excludeInput.Focused() {
+		line := p.excludeInput.View()
+		if p.excludeErr != nil {
+			line += "  " + p.theme.Error.Render(p.excludeErr.Error())
+		}
+		header = append(header, line)
+	}
+	if p.highlightInput.Focused() {
+		header = append(header, p.highlightLines()...)
+	}
+	if p.showVolume && p.logContainer.Len() > 0 {
+		header = append(header, p.volumeLines()...)
+	}
+	p.filterableViewport.SetHeader(header)
+}
+
+// volumeLines renders the volume histogram and a description of it or of its selected bucket
+func (p *LogsPage) volumeLines() []string {
+	numBuckets, labeled := p.volumeBuckets()
+	v := p.logContainer.Volume(numBuckets)
+	cursor := -1
+	if !p.volumeCursor.IsZero() {
+		cursor = v.BucketAt(p.volumeCursor)
+	}
+
+	graph := sparkline(v.Counts, cursor, p.theme.SelectedItem)
+	if labeled {
+		graph = fmt.Sprintf("%s %s %s",
+			p.theme.TimestampPrefix.Render(v.Start.Local().Format(time.TimeOnly)),
+			graph,
+			p.theme.TimestampPrefix.Render(v.End.Local().Format(time.TimeOnly)),
+		)
+	}
+	var info string
+	if cursor >= 0 {
+		from, to := v.BucketRange(cursor)
+		info = fmt.Sprintf("%s–%s: %d logs", from.Local().Format(time.TimeOnly), to.Local().Format(time.TimeOnly), v.Counts[cursor])
+	} else {
+		from, to := v.BucketRange(0)
+		info = fmt.Sprintf("max %d logs per %s, %s/%s to jump", slices.Max(v.Counts), to.Sub(from).Round(time.Millisecond),
+			p.keyMap.VolumePrev.Help().Key, p.keyMap.VolumeNext.Help().Key)
+	}
+	return []string{graph, info}
+}
+
+// moveVolumeCursor selects the next bucket of the volume histogram with logs in the given direction, and selects
+// the first log displayed in it
+func (p *LogsPage) moveVolumeCursor(delta int) {
+	numBuckets, _ := p.volumeBuckets()
+	v := p.logContainer.Volume(numBuckets)
+	if len(v.Counts) == 0 {
+		return
+	}
+	cursor := len(v.Counts)
+	if delta > 0 {
+		cursor = -1
+	}
+	if !p.volumeCursor.IsZero() {
+		cursor = v.BucketAt(p.volumeCursor)
+	}
+	for next := cursor + delta; next >= 0 && next < len(v.Counts); next += delta {
+		if v.Counts[next] > 0 {
+			cursor = next
+			break
+		}
+	}
+	if cursor < 0 || cursor >= len(v.Counts) {
+		return
+	}
+
+	from, to := v.BucketRange(cursor)
+	p.volumeCursor = from.Add(to.Sub(from) / 2)
+	p.selectTime(from)
+	p.updateHeader()
+}
+
+// setColumns shows the given fields as columns, fitting the column widths to the current logs
+func (p *LogsPage) setColumns(columns []string) {
+	p.display.SetColumns(columns)
+	p.display.FitColumns(p.logContainer.GetOrderedLogs())
+	p.updateFilterLabel()
+	p.refreshLogs()
+}
+
+// updateColumnsInput updates the fields being entered, showing them as columns when they are submitted
+func (p LogsPage) updateColumnsInput(msg tea.KeyMsg) (GenericPage, tea.Cmd) {
+	var cmd tea.Cmd
+	switch {
+	case key.Matches(msg, p.keyMap.Clear):
+		p.columnsInput.Blur()
+	case key.Matches(msg, p.keyMap.Enter):
+		p.columnsInput.Blur()
+		p.setColumns(model.ParseColumns(p.columnsInput.Value()))
+	default:
+		p.columnsInput, cmd = p.columnsInput.Update(msg)
+	}
+	p.updateHeader()
+	return p, cmd
+}
+
+// updateExcludeInput updates the exclusion being entered, hiding or restoring the logs it matches when it is
+// submitted, or restoring all hidden logs if it is empty
+func (p LogsPage) updateExcludeInput(msg tea.KeyMsg) (GenericPage, tea.Cmd) {
+	var cmd tea.Cmd
+	switch {
+	case key.Matches(msg, p.keyMap.Clear):
+		p.excludeInput.Blur()
+	case key.Matches(msg, p.keyMap.Enter):
+		if strings.TrimSpace(p.excludeInput.Value()) == "" {
+			p.excludeInput.Blur()
+			p.excludes = nil
+			p.updateFilterLabel()
+			p.refreshLogs()
+			break
+		}
+		e, err := model.ParseLogExclusion(p.excludeInput.Value())
+		p.excludeErr = err
+		if err == nil {
+			p.excludeInput.Blur()
+			p.toggleExclude(e)
+		}
+	default:
+		p.excludeInput, cmd = p.excludeInput.Update(msg)
+	}
+	p.updateHeader()
+	return p, cmd
+}
+
+// setHighlights highlights the text matched by each rule, re-rendering the logs
+func (p *LogsPage) setHighlights(rules []model.HighlightRule) {
+	p.display.Highlights = rules
+	p.updateFilterLabel()
+	p.refreshLogs()
+}
+
+// highlightLines renders the list of highlight rules, each in its style, marking the chosen rule, followed by the
+// rule being entered
+func (p *LogsPage) highlightLines() []string {
+	var lines []string
+	for i, rule := range p.display.Highlights {
+		marker := " "
+		if i == p.highlightIdx {
+			marker = p.theme.SelectionPrefix
+		}
+		lines = append(lines, fmt.Sprintf("%s %d %s", marker, i+1, p.theme.HighlightStyle(i).Render(rule.Text)))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "  No highlights")
+	}
+	line := p.highlightInput.View()
+	if p.highlightErr != nil {
+		line += "  " + p.theme.Error.Render(p.highlightErr.Error())
+	}
+	return append(lines, line)
+}
+
+// updateHighlightInput updates the highlight rule being entered, adding it when it is submitted, or removing it if
+// it is already a rule. The rules can be chosen to remove with the search history keys, and an empty rule closes
+// the list
+func (p LogsPage) updateHighlightInput(msg tea.KeyMsg) (GenericPage, tea.Cmd) {
+	var cmd tea.Cmd
+	rules := p.display.Highlights
+	switch {
+	case key.Matches(msg, p.keyMap.Clear):
+		p.highlightInput.Blur()
+	case key.Matches(msg, p.keyMap.SearchHistoryPrev), key.Matches(msg, p.keyMap.SearchHistoryNext):
+		if len(rules) == 0 {
+			break
+		}
+		delta := 1
+		if key.Matches(msg, p.keyMap.SearchHistoryPrev) {
+			delta = -1
+		}
+		// cycle through the rules and choosing none, which is index -1
+		n := len(rules) + 1
+		p.highlightIdx = (p.highlightIdx+1+delta+n)%n - 1
+		p.highlightInput.Reset()
+		if p.highlightIdx >= 0 {
+			p.highlightInput.SetValue(rules[p.highlightIdx].Text)
+			p.highlightInput.CursorEnd()
+		}
+	case key.Matches(msg, p.keyMap.Enter):
+		text := p.highlightInput.Value()
+		if strings.TrimSpace(text) == "" {
+			p.highlightInput.Blur()
+			break
+		}
+		rule, err := model.NewHighlightRule(text)
+		p.highlightErr = err
+		if err != nil {
+			break
+		}
+		if i := slices.IndexFunc(rules, func(r model.HighlightRule) bool { return r.Text == rule.Text }); i >= 0 {
+			// copied, as the logs of earlier copies of the page share the display
+			p.setHighlights(slices.Delete(slices.Clone(rules), i, i+1))
+		} else {
+			p.setHighlights(append(slices.Clip(rules), rule))
+		}
+		p.highlightInput.Reset()
+		p.highlightIdx = -1
+	default:
+		p.highlightInput, cmd = p.highlightInput.Update(msg)
+	}
+	p.updateHeader()
+	return p, cmd
+}
+
+// updateJumpInput updates the time being entered, jumping to it when it is submitted
+func (p LogsPage) updateJumpInput(msg tea.KeyMsg) (GenericPage, tea.Cmd) {
+	var cmd tea.Cmd
+	switch {
+	case key.Matches(msg, p.keyMap.Clear):
+		p.jumpInput.Blur()
+	case key.Matches(msg, p.keyMap.Enter):
+		_, latest, _ := p.logContainer.TimeRange()
+		t, err := model.ParseJumpTime(p.jumpInput.Value(), time.Now(), latest)
+		p.jumpErr = err
+		if err == nil {
+			p.jumpInput.Blur()
+			p.selectTime(t)
+		}
+	default:
+		p.jumpInput, cmd = p.jumpInput.Update(msg)
+	}
+	p.updateHeader()
+	return p, cmd
+}
+
+// stepTime selects the first log at or after the given duration from the selected log
+func (p *LogsPage) stepTime(d time.Duration) {
+	selected := p.filterableViewport.GetSelectedItem()
+	if selected == nil {
+		return
+	}
+	p.selectTime(selected.Log.OrderTime().Add(d))
+}
+
+// selectTime selects the earliest displayed log at or after t, or the latest displayed log if all are before t
+func (p *LogsPage) selectTime(t time.Time) {
+	if p.logContainer.NumOlderOnDisk() > 0 {
+		p.loadFromDisk(func() (int, error) { return p.logContainer.LoadSince(t, p.prepareLoaded) })
+	}
+	logs := p.displayedLogs()
+	if len(logs) == 0 {
+		return
+	}
+	if p.logContainer.Ascending() {
+		idx := sort.Search(len(logs), func(i int) bool { return !logs[i].Log.OrderTime().Before(t) })
+		p.filterableViewport.SetSelectedItemIdx(min(idx, len(logs)-1))
+		return
+	}
+	idx := sort.Search(len(logs), func(i int) bool { return logs[i].Log.OrderTime().Before(t) })
+	p.filterableViewport.SetSelectedItemIdx(max(idx-1, 0))
+}
+
+// isFollowing returns true if the newest row is selected, or nothing is, so the selection follows new logs
+func (p LogsPage) isFollowing() bool {
+	rows := p.viewportLogs.logs
+	selected := p.filterableViewport.GetSelectedItem()
+	if selected == nil || len(rows) == 0 {
+		return true
+	}
+	newest := rows[0]
+	if p.logContainer.Ascending() {
+		newest = rows[len(rows)-1]
+	}
+	return selected.Log == newest.Log
+}
+
+// loadOlderIfOldestSelected loads the logs added just before those in memory back from disk once the oldest row is
+// selected, so scrolling continues into them
+func (p *LogsPage) loadOlderIfOldestSelected() {
+	if p.logContainer.NumOlderOnDisk() == 0 {
+		return
+	}
+	oldestIdx := 0
+	if !p.logContainer.Ascending() {
+		oldestIdx = len(p.displayedLogs()) - 1
+	}
+	if p.filterableViewport.GetSelectedItemIdx() != oldestIdx {
+		return
+	}
+	p.loadFromDisk(func() (int, error) {
+		return p.logContainer.LoadOlder(constants.DiskStorePageLines, p.prepareLoaded)
+	})
+}
+
+// loadFromDisk loads logs back from disk with load, keeping the selected log selected
+func (p *LogsPage) loadFromDisk(load func() (int, error)) {
+	n, err := load()
+	if err != nil {
+		dev.Debug(fmt.Sprintf("error loading logs from disk: %v", err))
+		return
+	}
+	if n == 0 {
+		return
+	}
+	p.refreshLogs()
+	p.updateFilterLabel()
+}
+
+// prepareLoaded gives a log loaded back from disk the page's display, and the names shared by the earliest run of its
+// container kept, as the logs on disk are older than those in memory
+func (p *LogsPage) prepareLoaded(l model.PageLog) model.PageLog {
+	l.Display = p.display
+	if names, ok := p.containerNames[l.Log.Container.ID()]; ok && len(names.runs) > 0 {
+		l.ContainerNames = names.runs[0]
+	}
+	if logs := p.logContainer.GetOrderedLogs(); len(logs) > 0 {
+		l.Theme = logs[0].Theme
+	}
+	return l
+}
+
+// displayedLogs returns the rows the viewport displays, in the order of the ordered logs they come from: the logs
+// handed to it, and only those matching the filter if only matches are shown
+func (p LogsPage) displayedLogs() []model.PageLog {
+	rows := p.viewportLogs.logs
+	filterText := p.filterableViewport.GetFilterText()
+	mode := p.filterableViewport.GetActiveFilterMode()
+	if !p.filterableViewport.GetMatchingItemsOnly() || filterText == "" || mode == nil {
+		return rows
+	}
+	matchFunc, err := mode.GetMatchFunc(filterText)
+	if err != nil {
+		return nil
+	}
+	var matching []model.PageLog
+	for _, row := range rows {
+		if len(matchFunc(row.GetItem().ContentNoAnsi())) > 0 {
+			matching = append(matching, row)
+		}
+	}
+	return matching
+}
+
+// sharedContainerNames returns the names shared by the logs of the log's run of its container
+func (p *LogsPage) sharedContainerNames(log model.PageLog) *model.PageLogContainerNames {
+	id := log.Log.Container.ID()
+	names, ok := p.containerNames[id]
+	if !ok {
+		names = &containerNames{container: log.Log.Container}
+		p.containerNames[id] = names
+	}
+	terminated := log.ContainerNames != nil && log.ContainerNames.Terminated
+	if n := len(names.runs); n > 0 && names.runs[n-1].Terminated == terminated {
+		return names.runs[n-1]
+	}
+	// the first log of the container, or the first of a new run after the container restarted
+	var run model.PageLogContainerNames
+	if log.ContainerNames != nil {
+		run = *log.ContainerNames
+	}
+	names.runs = append(names.runs, &run)
+	return &run
 }
 
 // setStickynessBasedOnOrder sets viewport stickyness so selection stays at most recent log
@@ -398,19 +1360,53 @@ func (p *LogsPage) setStickynessBasedOnOrder() {
 
 func (p *LogsPage) updateFilterLabel() {
 	prefix := fmt.Sprintf("(L)ogs, %s", getOrder(p.logContainer.Ascending()))
+	if numDropped := p.logContainer.NumDropped(); numDropped > 0 {
+		prefix += fmt.Sprintf(", %d dropped", numDropped)
+	}
+	if numOnDisk := p.logContainer.NumOlderOnDisk(); numOnDisk > 0 {
+		prefix += fmt.Sprintf(", %d older on disk", numOnDisk)
+	}
+	switch collapseModes[p.collapseModeIdx] {
+	case model.CollapseExact:
+		prefix += ", repeats collapsed"
+	case model.CollapseNumbers:
+		prefix += ", repeats collapsed ignoring numbers"
+	}
+	if p.levelThresholdIdx > 0 {
+		prefix += fmt.Sprintf(", %s and above", levelThresholds[p.levelThresholdIdx])
+	}
+	if len(p.display.Columns) > 0 {
+		prefix += fmt.Sprintf(", columns %s", strings.Join(p.display.Columns, ","))
+	}
+	if n := len(p.display.Highlights); n > 0 {
+		prefix += fmt.Sprintf(", %d highlighted", n)
+	}
+	if len(p.excludes) > 0 {
+		prefix += ", excluding"
+		for _, e := range p.excludes {
+			prefix += fmt.Sprintf(" [%s]", e.ExclusionText())
+		}
+	}
 	if p.focused {
 		prefix += " [(w)rap, (p)rettify]"
 		prefix = p.theme.FilterPrefixFocused.Render(prefix)
 	}
+	if err := p.filterErr(); err != nil {
+		prefix += " " + p.theme.Error.Render(err.Error())
+	}
 	p.filterableViewport.SetFilterLinePrefix(prefix)
 }
 
-func (p *LogsPage) updatePrettyPrintOnLogs() {
-	allLogs := p.logContainer.GetOrderedLogs()
-	for i := range allLogs {
-		allLogs[i].PrettyPrinted = p.prettyPrint
+// filterErr returns why the filter text can't be used with the active filter mode, e.g. a jq query's or expression's
+// syntax error
+func (p LogsPage) filterErr() error {
+	filterText := p.filterableViewport.GetFilterText()
+	activeMode := p.filterableViewport.GetActiveFilterMode()
+	if filterText == "" || activeMode == nil || activeMode.Name != filter.FilterJQ && activeMode.Name != filter.FilterExpr {
+		return nil
 	}
-	p.setLogs(allLogs)
+	_, err := activeMode.GetMatchFunc(filterText)
+	return err
 }
 
 func (p *LogsPage) updateStyles() {
@@ -418,33 +1414,37 @@ func (p *LogsPage) updateStyles() {
 	p.updateFilterLabel()
 }
 
-func getOrder(ascending bool) string {
-	if ascending {
-		return "Ascending"
+func jsonColors(theme style.Theme) *util.JSONColorStyles {
+	return &util.JSONColorStyles{
+		Key:    theme.JSONKey,
+		String: theme.JSONString,
+		Number: theme.JSONNumber,
+		Bool:   theme.JSONBool,
+		Null:   theme.JSONNull,
 	}
-	return "Descending"
 }
 
-func getLogTimestamp(log model.PageLog, format string) string {
-	if format == "short" {
-		return log.Log.Timestamps.Short
-	}
-	if format == "full" {
-		return log.Log.Timestamps.Full
+// sparkline renders counts as bars of increasing height, highlighting the bar at cursor if non-negative
+func sparkline(counts []int, cursor int, cursorStyle lipgloss.Style) string {
+	maxCount := slices.Max(counts)
+	var b strings.Builder
+	for i, count := range counts {
+		bar := " "
+		if count > 0 {
+			level := (count*len(sparklineBars) - 1) / maxCount
+			bar = string(sparklineBars[level])
+		}
+		if i == cursor {
+			bar = cursorStyle.Render(bar)
+		}
+		b.WriteString(bar)
 	}
-	return ""
+	return b.String()
 }
 
-func getContainerName(log model.PageLog, format string) *k8s_model.ContainerNameAndPrefix {
-	var name k8s_model.ContainerNameAndPrefix
-	if format == "short" {
-		name = log.ContainerNames.Short
-	}
-	if format == "full" {
-		name = log.ContainerNames.Full
-	}
-	if log.Terminated && len(name.ContainerName) > 0 {
-		name.ContainerName += " [TERMINATED]"
+func getOrder(ascending bool) string {
+	if ascending {
+		return "Ascending"
 	}
-	return &name
+	return "Descending"
 }
@@ -0,0 +1,194 @@
+package page
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_log"
+	"github.com/robinovitch61/kl/internal/k8s/k8s_model"
+	"github.com/robinovitch61/kl/internal/keymap"
+	"github.com/robinovitch61/kl/internal/model"
+	"github.com/robinovitch61/kl/internal/style"
+	"github.com/robinovitch61/viewport/filterableviewport"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+// the cost of each operation should not grow with the number of logs already in the page
+var benchmarkSizes = []int{10_000, 100_000, 500_000}
+
+const benchmarkBatchSize = 100
+
+var benchmarkStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func makeBenchmarkLogs(from, n int, ct container.Container) []model.PageLog {
+	name := k8s_model.ContainerNameAndPrefix{Prefix: ct.IDWithoutContainerName(), ContainerName: ct.Name}
+	logs := make([]model.PageLog, n)
+	for i := range logs {
+		ts := benchmarkStart.Add(time.Duration(from+i) * time.Millisecond)
+		logs[i] = model.PageLog{
+			Log: &k8s_log.Log{
+				Timestamp:   ts,
+				Timestamps:  k8s_log.LogTimestamps{Short: ts.Format(time.TimeOnly), Full: ts.Format(time.RFC3339Nano)},
+				Sequence:    uint64(from + i),
+				Container:   ct,
+				ContentItem: item.NewItem(fmt.Sprintf("log line %d", from+i)),
+			},
+			ContainerNames: &model.PageLogContainerNames{Short: name, Full: name},
+		}
+	}
+	return logs
+}
+
+func newBenchmarkLogsPage(b *testing.B, descending bool, n int) (LogsPage, container.Container) {
+	b.Helper()
+	theme := style.DefaultTheme()
+	ct := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "web"}
+	p := NewLogsPage(keymap.DefaultKeyMap(), 200, 50, descending, theme)
+	p = p.WithAppendedLogs(makeBenchmarkLogs(0, n, ct))
+	return p, ct
+}
+
+func BenchmarkLogsPage_AppendBatch(b *testing.B) {
+	for _, descending := range []bool{false, true} {
+		for _, n := range benchmarkSizes {
+			b.Run(fmt.Sprintf("descending=%v/logs=%d", descending, n), func(b *testing.B) {
+				p, ct := newBenchmarkLogsPage(b, descending, n)
+				next := n
+				b.ResetTimer()
+				for range b.N {
+					b.StopTimer()
+					batch := makeBenchmarkLogs(next, benchmarkBatchSize, ct)
+					next += benchmarkBatchSize
+					b.StartTimer()
+					p = p.WithAppendedLogs(batch)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkLogsPage_AppendBatchOutOfOrder(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("logs=%d", n), func(b *testing.B) {
+			p, ct := newBenchmarkLogsPage(b, false, n)
+			other := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "sidecar"}
+			next := n
+			b.ResetTimer()
+			for range b.N {
+				b.StopTimer()
+				// logs from another container arrive slightly late, landing just before the most recent logs
+				batch := makeBenchmarkLogs(next, benchmarkBatchSize, ct)
+				batch = append(batch, makeBenchmarkLogs(next-benchmarkBatchSize/2, benchmarkBatchSize/10, other)...)
+				next += benchmarkBatchSize
+				b.StartTimer()
+				p = p.WithAppendedLogs(batch)
+			}
+		})
+	}
+}
+
+func BenchmarkLogsPage_AppendBatchWithRetention(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("logs=%d", n), func(b *testing.B) {
+			p, ct := newBenchmarkLogsPage(b, false, n)
+			p = p.WithRetentionPolicy(model.RetentionPolicy{MaxLines: n})
+			next := n
+			b.ResetTimer()
+			for range b.N {
+				b.StopTimer()
+				batch := makeBenchmarkLogs(next, benchmarkBatchSize, ct)
+				next += benchmarkBatchSize
+				b.StartTimer()
+				p = p.WithAppendedLogs(batch)
+			}
+		})
+	}
+}
+
+func BenchmarkLogsPage_AppendBatchCollapsedWithRetention(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("logs=%d", n), func(b *testing.B) {
+			p, ct := newBenchmarkLogsPage(b, false, n)
+			p = p.WithRetentionPolicy(model.RetentionPolicy{MaxLines: n})
+			p = p.WithNextCollapseMode()
+			next := n
+			b.ResetTimer()
+			for range b.N {
+				b.StopTimer()
+				batch := makeBenchmarkLogs(next, benchmarkBatchSize, ct)
+				next += benchmarkBatchSize
+				b.StartTimer()
+				p = p.WithAppendedLogs(batch)
+			}
+		})
+	}
+}
+
+func BenchmarkLogsPage_AppendBatchCollapsed(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("logs=%d", n), func(b *testing.B) {
+			p, ct := newBenchmarkLogsPage(b, false, n)
+			p = p.WithNextCollapseMode()
+			next := n
+			b.ResetTimer()
+			for range b.N {
+				b.StopTimer()
+				batch := makeBenchmarkLogs(next, benchmarkBatchSize, ct)
+				next += benchmarkBatchSize
+				b.StartTimer()
+				p = p.WithAppendedLogs(batch)
+			}
+		})
+	}
+}
+
+func BenchmarkLogsPage_AppendBatchCollapsedRepeatsFiltered(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("logs=%d", n), func(b *testing.B) {
+			p, ct := newBenchmarkLogsPage(b, false, n)
+			p = p.WithNextCollapseMode()
+			p.filterableViewport.SetFilter("log line", filterableviewport.FilterExact)
+			next := n
+			b.ResetTimer()
+			for range b.N {
+				b.StopTimer()
+				// every log collapses into the last row
+				batch := makeBenchmarkLogs(next, benchmarkBatchSize, ct)
+				for i := range batch {
+					batch[i].Log.ContentItem = item.NewItem(fmt.Sprintf("log line %d", n-1))
+				}
+				next += benchmarkBatchSize
+				b.StartTimer()
+				p = p.WithAppendedLogs(batch)
+			}
+		})
+	}
+}
+
+func BenchmarkLogsPage_DisplayChanges(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("timestamp-format/logs=%d", n), func(b *testing.B) {
+			p, _ := newBenchmarkLogsPage(b, false, n)
+			b.ResetTimer()
+			for range b.N {
+				p = p.WithNewTimestampFormat()
+			}
+		})
+		b.Run(fmt.Sprintf("name-format/logs=%d", n), func(b *testing.B) {
+			p, _ := newBenchmarkLogsPage(b, false, n)
+			b.ResetTimer()
+			for range b.N {
+				p = p.WithNewNameFormat()
+			}
+		})
+		b.Run(fmt.Sprintf("terminated/logs=%d", n), func(b *testing.B) {
+			p, ct := newBenchmarkLogsPage(b, false, n)
+			b.ResetTimer()
+			for range b.N {
+				p = p.WithLogsTerminatedForContainer(ct)
+			}
+		})
+	}
+}
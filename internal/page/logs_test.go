@@ -0,0 +1,223 @@
+package page
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robinovitch61/kl/internal/filter"
+	"github.com/robinovitch61/kl/internal/k8s/container"
+	"github.com/robinovitch61/kl/internal/keymap"
+	"github.com/robinovitch61/kl/internal/logstore"
+	"github.com/robinovitch61/kl/internal/model"
+	"github.com/robinovitch61/kl/internal/style"
+	"github.com/robinovitch61/viewport/filterableviewport"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+func TestViewportLogs_ResolvesFieldsRegardlessOfScanOrder(t *testing.T) {
+	web := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "web"}
+	worker := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "worker"}
+	logs := append(makeBenchmarkLogs(0, 3, web), makeBenchmarkLogs(3, 3, worker)...)
+	p := NewLogsPage(keymap.DefaultKeyMap(), 200, 50, false, style.DefaultTheme())
+	p = p.WithAppendedLogs(logs)
+
+	rows := p.viewportLogs.logs
+	fields := p.viewportLogs.scanFields()
+	contents := p.viewportLogs.scanContents()
+	// matched backwards and skipping logs, as a viewport may
+	for i := len(rows) - 1; i >= 0; i -= 2 {
+		content := rows[i].GetItem().ContentNoAnsi()
+		if got, want := fields(content)[filter.FieldContainer], rows[i].Log.Container.Name; got != want {
+			t.Errorf("row %d: expected container %q, got %q", i, want, got)
+		}
+		if got, want := contents(content), rows[i].Log.Item().ContentNoAnsi(); got != want {
+			t.Errorf("row %d: expected content %q, got %q", i, want, got)
+		}
+	}
+}
+
+func TestViewportLogs_ResolvesIdenticallyRenderedLogsInTurn(t *testing.T) {
+	web := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "web"}
+	worker := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "worker"}
+	p := NewLogsPage(keymap.DefaultKeyMap(), 200, 50, false, style.DefaultTheme())
+	// without timestamps or names, logs with the same content render identically
+	p = p.WithNewNameFormat()
+	sameContent := func(from int, ct container.Container) model.PageLog {
+		l := makeBenchmarkLogs(from, 1, ct)[0]
+		l.Log.ContentItem = item.NewItem("request timeout")
+		return l
+	}
+	p = p.WithAppendedLogs([]model.PageLog{sameContent(0, web), sameContent(1, worker)})
+
+	fields := p.viewportLogs.scanFields()
+	content := p.viewportLogs.logs[0].GetItem().ContentNoAnsi()
+	for _, want := range []string{"web", "worker"} {
+		if got := fields(content)[filter.FieldContainer]; got != want {
+			t.Errorf("expected container %q, got %q", want, got)
+		}
+	}
+
+	// logs appended later resolve to themselves rather than the earlier logs rendered identically
+	p = p.WithAppendedLogs([]model.PageLog{sameContent(2, worker)})
+	p.viewportLogs.appendedFrom = 2
+	if got := p.viewportLogs.scanFields()(content)[filter.FieldContainer]; got != "worker" {
+		t.Errorf("expected appended log's container, got %q", got)
+	}
+}
+
+func TestLogsPage_CollapsedRowsMatchFilterAsTheyUpdate(t *testing.T) {
+	ct := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "web"}
+	repeat := func(from int) model.PageLog {
+		l := makeBenchmarkLogs(from, 1, ct)[0]
+		l.Log.ContentItem = item.NewItem("connection refused")
+		return l
+	}
+	p := NewLogsPage(keymap.DefaultKeyMap(), 200, 50, false, style.DefaultTheme())
+	p = p.WithNextCollapseMode()
+	p = p.WithAppendedLogs([]model.PageLog{repeat(0), repeat(1)})
+	p.filterableViewport.SetFilter("×3", filterableviewport.FilterExact)
+	p.filterableViewport.SetMatchingItemsOnly(true)
+	if view := p.View(); strings.Contains(view, "connection refused") {
+		t.Fatalf("expected no row to match yet, got:\n%s", view)
+	}
+
+	p = p.WithAppendedLogs([]model.PageLog{repeat(2)})
+	if view := p.View(); !strings.Contains(view, "connection refused") || !strings.Contains(view, "1/1 matches") {
+		t.Errorf("expected the updated row to match, got:\n%s", view)
+	}
+}
+
+func TestLogsPage_RetentionTrimsViewportLogs(t *testing.T) {
+	ct := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "web"}
+	hideFives, err := model.ParseLogExclusion(`/5$/`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		setup func(LogsPage) LogsPage
+	}{
+		{"all logs shown", func(p LogsPage) LogsPage { return p }},
+		{"logs excluded", func(p LogsPage) LogsPage {
+			return p.WithLogFilter(model.LogFilter{Excludes: []model.LogFilter{hideFives}})
+		}},
+		{"repeats collapsed", func(p LogsPage) LogsPage { return p.WithNextCollapseMode() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewLogsPage(keymap.DefaultKeyMap(), 200, 50, false, style.DefaultTheme())
+			p = tt.setup(p.WithRetentionPolicy(model.RetentionPolicy{MaxLines: 20}))
+			for from := 0; from < 100; from += 7 {
+				p = p.WithAppendedLogs(makeBenchmarkLogs(from, 7, ct))
+			}
+
+			kept := p.logContainer.GetOrderedLogs()
+			if len(kept) != 20 || kept[0].Log.Sequence != 85 {
+				t.Fatalf("expected the newest 20 logs to be kept, got %d from %d", len(kept), kept[0].Log.Sequence)
+			}
+			var expected []string
+			for _, l := range p.shown(kept) {
+				expected = append(expected, l.GetItem().ContentNoAnsi())
+			}
+			var got []string
+			for _, l := range p.viewportLogs.logs {
+				got = append(got, l.GetItem().ContentNoAnsi())
+			}
+			if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+				t.Errorf("expected the viewport to show\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestLogsPage_WithExpiredLogsDropped(t *testing.T) {
+	ct := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "web"}
+	// old enough for the logs not to expire as they are added
+	maxAge := time.Since(benchmarkStart) + time.Hour
+	for _, descending := range []bool{false, true} {
+		p := NewLogsPage(keymap.DefaultKeyMap(), 200, 50, descending, style.DefaultTheme())
+		p = p.WithRetentionPolicy(model.RetentionPolicy{MaxAge: maxAge})
+		p = p.WithAppendedLogs(makeBenchmarkLogs(0, 10, ct))
+
+		p = p.WithExpiredLogsDropped(benchmarkStart.Add(maxAge + 5*time.Millisecond))
+		if len(p.viewportLogs.logs) != 5 {
+			t.Fatalf("descending=%v: expected 5 logs left, got %d", descending, len(p.viewportLogs.logs))
+		}
+		for _, l := range p.viewportLogs.logs {
+			if l.Log.Sequence < 5 {
+				t.Errorf("descending=%v: expected log %d to be dropped", descending, l.Log.Sequence)
+			}
+		}
+	}
+}
+
+func TestLogsPage_DiskStore(t *testing.T) {
+	ct := container.Container{Cluster: "cluster", Namespace: "ns", Pod: "pod", Name: "web"}
+	sequences := func(logs []model.PageLog) []uint64 {
+		var res []uint64
+		for _, l := range logs {
+			res = append(res, l.Log.Sequence)
+		}
+		return res
+	}
+	for _, descending := range []bool{false, true} {
+		store, err := logstore.NewDiskStore(t.TempDir(), 256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = store.Close() }()
+		p := NewLogsPage(keymap.DefaultKeyMap(), 200, 50, descending, style.DefaultTheme())
+		p = p.WithDiskStore(store, 10)
+		for from := 0; from < 30; from += 6 {
+			p = p.WithAppendedLogs(makeBenchmarkLogs(from, 6, ct))
+		}
+		if got := sequences(p.viewportLogs.logs); len(got) != 10 || slices.Min(got) != 20 {
+			t.Fatalf("descending=%v: expected the newest 10 logs in the viewport, got %v", descending, got)
+		}
+
+		content := p.ContentForFile()
+		if len(content) != 30 {
+			t.Fatalf("descending=%v: expected logs on disk to be saved too, got %d lines", descending, len(content))
+		}
+		oldest, newest := content[0], content[len(content)-1]
+		if descending {
+			oldest, newest = newest, oldest
+		}
+		if !strings.HasSuffix(oldest, "log line 0") || !strings.HasSuffix(newest, "log line 29") {
+			t.Errorf("descending=%v: expected logs in order, got %q to %q", descending, content[0], content[len(content)-1])
+		}
+
+		// selecting the oldest row loads the older logs back
+		oldestIdx := 0
+		if descending {
+			oldestIdx = len(p.viewportLogs.logs) - 1
+		}
+		p.filterableViewport.SetSelectedItemIdx(oldestIdx)
+		p.loadOlderIfOldestSelected()
+		if got := sequences(p.viewportLogs.logs); len(got) != 30 || slices.Min(got) != 0 {
+			t.Fatalf("descending=%v: expected all logs in the viewport once loaded, got %v", descending, got)
+		}
+		if selected := p.GetSelectedLog(); selected == nil || selected.Log.Sequence != 20 {
+			t.Errorf("descending=%v: expected the selection to stay on log 20, got %v", descending, selected)
+		}
+
+		// following the newest logs again moves the older ones back to disk
+		newestIdx := len(p.viewportLogs.logs) - 1
+		if descending {
+			newestIdx = 0
+		}
+		p.filterableViewport.SetSelectedItemIdx(newestIdx)
+		p = p.WithAppendedLogs(makeBenchmarkLogs(30, 1, ct))
+		if got := sequences(p.viewportLogs.logs); len(got) != 10 || slices.Min(got) != 21 {
+			t.Fatalf("descending=%v: expected the newest 10 logs in the viewport, got %v", descending, got)
+		}
+
+		// jumping to a time before the logs in memory loads the logs since then
+		p.selectTime(benchmarkStart.Add(5 * time.Millisecond))
+		if selected := p.GetSelectedLog(); selected == nil || selected.Log.Sequence != 5 {
+			t.Errorf("descending=%v: expected log 5 to be selected, got %v", descending, selected)
+		}
+	}
+}
@@ -26,6 +26,7 @@ const (
 	EntitiesPageType Type = iota
 	LogsPageType
 	SingleLogPageType
+	PatternsPageType
 )
 
 // viewportStylesForFocus returns the viewport styles for a page based on whether it is focused.
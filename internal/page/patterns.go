@@ -0,0 +1,218 @@
+package page
+
+import (
+	"fmt"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/robinovitch61/kl/internal/dev"
+	"github.com/robinovitch61/kl/internal/help"
+	"github.com/robinovitch61/kl/internal/keymap"
+	"github.com/robinovitch61/kl/internal/model"
+	"github.com/robinovitch61/kl/internal/style"
+	"github.com/robinovitch61/viewport/filterableviewport"
+	"github.com/robinovitch61/viewport/viewport"
+	"github.com/robinovitch61/viewport/viewport/item"
+)
+
+// PatternRow is a row of the patterns page: a pattern, or the occurrences of a pattern in one of its containers
+type PatternRow struct {
+	Pattern   *model.LogPattern
+	container *model.PatternContainerStats
+	theme     *style.Theme
+}
+
+func (r PatternRow) GetItem() item.Item {
+	if r.container != nil {
+		return item.NewItem(fmt.Sprintf("%8d  %s      %s", r.container.Count, seenRange(r.container.FirstSeen, r.container.LastSeen), r.container.Container))
+	}
+	stats := fmt.Sprintf("%8d  %s  ", r.Pattern.Count, seenRange(r.Pattern.FirstSeen, r.Pattern.LastSeen))
+	if r.theme != nil {
+		stats = r.theme.TimestampPrefix.Render(stats)
+	}
+	return item.NewConcat(item.NewItem(stats), item.NewItem(r.Pattern.Template()))
+}
+
+func seenRange(first, last time.Time) string {
+	return fmt.Sprintf("%s–%s", first.Local().Format(time.TimeOnly), last.Local().Format(time.TimeOnly))
+}
+
+type PatternsPage struct {
+	filterableViewport *filterableviewport.Model[PatternRow]
+	keyMap             keymap.KeyMap
+	theme              style.Theme
+	focused            bool
+	rows               []PatternRow
+	numLogs            int
+}
+
+// assert PatternsPage implements GenericPage
+var _ GenericPage = PatternsPage{}
+
+func NewPatternsPage(
+	keyMap keymap.KeyMap,
+	width, height int,
+	theme style.Theme,
+) PatternsPage {
+	vp := viewport.New[PatternRow](width, height,
+		viewport.WithKeyMap[PatternRow](viewport.KeyMap{
+			PageDown:     keyMap.PageDown,
+			PageUp:       keyMap.PageUp,
+			HalfPageUp:   keyMap.HalfPageUp,
+			HalfPageDown: keyMap.HalfPageDown,
+			Up:           keyMap.Up,
+			Down:         keyMap.Down,
+			Left:         keyMap.Left,
+			Right:        keyMap.Right,
+			Top:          keyMap.Top,
+			Bottom:       keyMap.Bottom,
+		}),
+		viewport.WithSelectionStyleOverridesItemStyle[PatternRow](false),
+		viewport.WithSelectionEnabled[PatternRow](true),
+		viewport.WithWrapText[PatternRow](false),
+	)
+
+	fvp := filterableviewport.New(vp,
+		filterableviewport.WithKeyMap[PatternRow](filterableviewport.KeyMap{
+			ApplyFilterKey:             keyMap.Enter,
+			CancelFilterKey:            keyMap.Clear,
+			ToggleMatchingItemsOnlyKey: keyMap.Context,
+			NextMatchKey:               keyMap.FilterNextRow,
+			PrevMatchKey:               keyMap.FilterPrevRow,
+			SearchHistoryPrevKey:       keyMap.SearchHistoryPrev,
+			SearchHistoryNextKey:       keyMap.SearchHistoryNext,
+		}),
+		filterableviewport.WithFilterModes[PatternRow]([]filterableviewport.FilterMode{
+			filterableviewport.ExactFilterMode(keyMap.Filter),
+			filterableviewport.RegexFilterMode(keyMap.FilterRegex),
+			filterableviewport.CaseInsensitiveFilterMode(keyMap.FilterCaseInsensitive),
+		}),
+		filterableviewport.WithMatchingItemsOnly[PatternRow](false),
+		filterableviewport.WithCanToggleMatchingItemsOnly[PatternRow](true),
+		filterableviewport.WithEmptyText[PatternRow]("'/', 'r', or 'i' to filter"),
+		filterableviewport.WithFilterLinePosition[PatternRow](filterableviewport.FilterLineTop),
+		filterableviewport.WithItemDescriptor[PatternRow]("rows"),
+		filterableviewport.WithFilterLinePrefix[PatternRow]("Log Patterns"),
+		filterableviewport.WithStyles[PatternRow](filterableviewport.Styles{
+			Match: filterableviewport.MatchStyles{
+				Focused:           theme.MatchFocused,
+				FocusedIfSelected: theme.MatchFocusedIfSelected,
+				Unfocused:         theme.MatchUnfocused,
+			},
+		}),
+	)
+
+	p := PatternsPage{
+		filterableViewport: fvp,
+		keyMap:             keyMap,
+		theme:              theme,
+	}
+	p.updateStyles()
+
+	return p
+}
+
+func (p PatternsPage) Update(msg tea.Msg) (GenericPage, tea.Cmd) {
+	dev.DebugUpdateMsg("PatternsPage", msg)
+	var cmd tea.Cmd
+	p.filterableViewport, cmd = p.filterableViewport.Update(msg)
+	return p, cmd
+}
+
+func (p PatternsPage) View() string {
+	return p.filterableViewport.View()
+}
+
+func (p PatternsPage) HighjackingInput() bool {
+	return p.filterableViewport.IsCapturingInput()
+}
+
+func (p PatternsPage) ContentForFile() []string {
+	var content []string
+	for _, row := range p.rows {
+		content = append(content, row.GetItem().ContentNoAnsi())
+	}
+	return content
+}
+
+func (p PatternsPage) ToggleShowContext() GenericPage {
+	currentValue := p.filterableViewport.GetMatchingItemsOnly()
+	p.filterableViewport.SetMatchingItemsOnly(!currentValue)
+	return p
+}
+
+func (p PatternsPage) HasAppliedFilter() bool {
+	return p.filterableViewport.GetFilterText() != ""
+}
+
+func (p PatternsPage) WithDimensions(width, height int) GenericPage {
+	p.filterableViewport.SetWidth(width)
+	p.filterableViewport.SetHeight(height)
+	return p
+}
+
+func (p PatternsPage) WithFocus() GenericPage {
+	p.focused = true
+	p.updateStyles()
+	return p
+}
+
+func (p PatternsPage) WithBlur() GenericPage {
+	p.focused = false
+	p.updateStyles()
+	return p
+}
+
+func (p PatternsPage) WithTheme(theme style.Theme) GenericPage {
+	p.theme = theme
+	p.updateStyles()
+	p.filterableViewport.SetFilterableViewportStyles(filterableviewport.Styles{
+		Match: filterableviewport.MatchStyles{
+			Focused:           theme.MatchFocused,
+			FocusedIfSelected: theme.MatchFocusedIfSelected,
+			Unfocused:         theme.MatchUnfocused,
+		},
+	})
+	return p
+}
+
+func (p PatternsPage) Help() string {
+	return help.MakeHelp(p.keyMap, p.theme.HelpKeyColumn)
+}
+
+// WithPatterns shows patterns found in numLogs logs, each followed by its occurrences per container
+func (p PatternsPage) WithPatterns(patterns []*model.LogPattern, numLogs int) PatternsPage {
+	p.numLogs = numLogs
+	theme := p.theme
+	p.rows = nil
+	for _, pattern := range patterns {
+		p.rows = append(p.rows, PatternRow{Pattern: pattern, theme: &theme})
+		for i := range pattern.Containers {
+			p.rows = append(p.rows, PatternRow{Pattern: pattern, container: &pattern.Containers[i], theme: &theme})
+		}
+	}
+	p.filterableViewport.SetObjects(p.rows)
+	p.filterableViewport.SetSelectedItemIdx(0)
+	p.updateStyles()
+	return p
+}
+
+// GetSelectedPattern returns the pattern of the selected row, or nil if there is none
+func (p PatternsPage) GetSelectedPattern() *model.LogPattern {
+	row := p.filterableViewport.GetSelectedItem()
+	if row == nil {
+		return nil
+	}
+	return row.Pattern
+}
+
+func (p *PatternsPage) updateStyles() {
+	p.filterableViewport.SetViewportStyles(viewportStylesForFocus(p.focused, p.theme))
+
+	prefix := fmt.Sprintf("Log Patterns in %d logs", p.numLogs)
+	if p.focused {
+		prefix += " [enter to filter logs]"
+		prefix = p.theme.FilterPrefixFocused.Render(prefix)
+	}
+	p.filterableViewport.SetFilterLinePrefix(prefix)
+}
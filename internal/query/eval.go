@@ -0,0 +1,461 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// node is an expression evaluated against an input value. Values are those decoded by encoding/json into an
+// interface{}: nil, bool, float64, string, []interface{} and map[string]interface{}
+type node interface {
+	eval(input interface{}) (interface{}, error)
+}
+
+type identityNode struct{}
+
+func (identityNode) eval(input interface{}) (interface{}, error) {
+	return input, nil
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+// indexNode is a field of an object or an element of an array. Like jq, indexing null gives null
+type indexNode struct {
+	term, index node
+}
+
+func (n indexNode) eval(input interface{}) (interface{}, error) {
+	term, err := n.term.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	switch t := term.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot index object with %s", typeName(index))
+		}
+		return t[key], nil
+	case []interface{}:
+		i, ok := index.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot index array with %s", typeName(index))
+		}
+		idx := int(math.Floor(i))
+		if idx < 0 {
+			idx += len(t)
+		}
+		if idx < 0 || idx >= len(t) {
+			return nil, nil
+		}
+		return t[idx], nil
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(term))
+}
+
+// pipeNode evaluates right with the result of left as its input
+type pipeNode struct {
+	left, right node
+}
+
+func (n pipeNode) eval(input interface{}) (interface{}, error) {
+	left, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	return n.right.eval(left)
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n andNode) eval(input interface{}) (interface{}, error) {
+	left, err := n.left.eval(input)
+	if err != nil || !truthy(left) {
+		return false, err
+	}
+	right, err := n.right.eval(input)
+	return truthy(right), err
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n orNode) eval(input interface{}) (interface{}, error) {
+	left, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	if truthy(left) {
+		return true, nil
+	}
+	right, err := n.right.eval(input)
+	return truthy(right), err
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(input interface{}) (interface{}, error) {
+	left, err := n.left.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	c := compare(left, right)
+	switch n.op {
+	case "==":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+type callNode struct {
+	name string
+	pos  int
+	fn   function
+	args []node
+}
+
+func (n callNode) eval(input interface{}) (interface{}, error) {
+	// like jq, arguments are evaluated against the same input as the call
+	args := make([]interface{}, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(input)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := n.fn.call(input, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+type function struct {
+	minArgs, maxArgs int
+	call             func(input interface{}, args []interface{}) (interface{}, error)
+}
+
+func (f function) arity() string {
+	switch {
+	case f.minArgs == f.maxArgs && f.maxArgs == 0:
+		return "no arguments"
+	case f.minArgs == f.maxArgs && f.maxArgs == 1:
+		return "1 argument"
+	case f.minArgs == f.maxArgs:
+		return fmt.Sprintf("%d arguments", f.maxArgs)
+	}
+	return fmt.Sprintf("%d to %d arguments", f.minArgs, f.maxArgs)
+}
+
+var functions = map[string]function{
+	"ascii_downcase": {0, 0, stringFunc(strings.ToLower)},
+	"ascii_upcase":   {0, 0, stringFunc(strings.ToUpper)},
+	"contains":       {1, 1, containsFunc},
+	"endswith":       {1, 1, stringTestFunc(strings.HasSuffix)},
+	"has":            {1, 1, hasFunc},
+	"keys":           {0, 0, keysFunc},
+	"length":         {0, 0, lengthFunc},
+	"not":            {0, 0, func(input interface{}, _ []interface{}) (interface{}, error) { return !truthy(input), nil }},
+	"select":         {1, 1, selectFunc},
+	"startswith":     {1, 1, stringTestFunc(strings.HasPrefix)},
+	"test":           {1, 2, testFunc},
+	"tonumber":       {0, 0, toNumberFunc},
+	"tostring":       {0, 0, toStringFunc},
+	"type":           {0, 0, func(input interface{}, _ []interface{}) (interface{}, error) { return typeName(input), nil }},
+}
+
+// booleanFunctions always result in a boolean
+var booleanFunctions = map[string]bool{
+	"contains": true, "endswith": true, "has": true, "not": true, "startswith": true, "test": true,
+}
+
+// booleanInputFunctions don't fail on a boolean input
+var booleanInputFunctions = map[string]bool{"not": true, "select": true, "tostring": true, "type": true}
+
+func stringFunc(f func(string) string) func(interface{}, []interface{}) (interface{}, error) {
+	return func(input interface{}, _ []interface{}) (interface{}, error) {
+		s, ok := input.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a string", typeName(input))
+		}
+		return f(s), nil
+	}
+}
+
+func stringTestFunc(f func(s, arg string) bool) func(interface{}, []interface{}) (interface{}, error) {
+	return func(input interface{}, args []interface{}) (interface{}, error) {
+		s, ok := input.(string)
+		arg, argOk := args[0].(string)
+		if !ok || !argOk {
+			return nil, fmt.Errorf("%s and %s are not both strings", typeName(input), typeName(args[0]))
+		}
+		return f(s, arg), nil
+	}
+}
+
+func containsFunc(input interface{}, args []interface{}) (interface{}, error) {
+	switch in := input.(type) {
+	case string:
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("string cannot contain %s", typeName(args[0]))
+		}
+		return strings.Contains(in, s), nil
+	case []interface{}:
+		for _, v := range in {
+			if compare(v, args[0]) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return nil, fmt.Errorf("%s cannot contain values", typeName(input))
+}
+
+func hasFunc(input interface{}, args []interface{}) (interface{}, error) {
+	switch in := input.(type) {
+	case map[string]interface{}:
+		key, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("object keys are strings, not %s", typeName(args[0]))
+		}
+		_, has := in[key]
+		return has, nil
+	case []interface{}:
+		i, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("array indexes are numbers, not %s", typeName(args[0]))
+		}
+		return i >= 0 && int(i) < len(in), nil
+	}
+	return nil, fmt.Errorf("%s has no keys", typeName(input))
+}
+
+func keysFunc(input interface{}, _ []interface{}) (interface{}, error) {
+	obj, ok := input.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s has no keys", typeName(input))
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	res := make([]interface{}, len(keys))
+	for i, k := range keys {
+		res[i] = k
+	}
+	return res, nil
+}
+
+func lengthFunc(input interface{}, _ []interface{}) (interface{}, error) {
+	switch in := input.(type) {
+	case nil:
+		return float64(0), nil
+	case bool:
+		return nil, fmt.Errorf("boolean has no length")
+	case float64:
+		return math.Abs(in), nil
+	case string:
+		return float64(utf8.RuneCountInString(in)), nil
+	case []interface{}:
+		return float64(len(in)), nil
+	case map[string]interface{}:
+		return float64(len(in)), nil
+	}
+	return nil, fmt.Errorf("%s has no length", typeName(input))
+}
+
+func selectFunc(input interface{}, args []interface{}) (interface{}, error) {
+	if truthy(args[0]) {
+		return input, nil
+	}
+	// jq outputs nothing, which for a filter is the same as not matching
+	return false, nil
+}
+
+func testFunc(input interface{}, args []interface{}) (interface{}, error) {
+	s, ok := input.(string)
+	pattern, patternOk := args[0].(string)
+	if !ok || !patternOk {
+		return nil, fmt.Errorf("%s cannot be matched against %s", typeName(input), typeName(args[0]))
+	}
+	if len(args) > 1 {
+		flags, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("flags must be a string")
+		}
+		if strings.Contains(flags, "i") {
+			pattern = "(?i)" + pattern
+		}
+	}
+	re, err := compileRegex(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString(s), nil
+}
+
+func toNumberFunc(input interface{}, _ []interface{}) (interface{}, error) {
+	switch in := input.(type) {
+	case float64:
+		return in, nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(in), 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %q as a number", in)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("%s cannot be parsed as a number", typeName(input))
+}
+
+func toStringFunc(input interface{}, _ []interface{}) (interface{}, error) {
+	if s, ok := input.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// regexCache holds compiled regexes, as the same pattern is usually tested against every log
+var regexCache sync.Map
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// truthy returns false for false and null, like jq
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	}
+	return true
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// typeOrder orders values of different types like jq: null, false, true, numbers, strings, arrays, objects
+func typeOrder(v interface{}) int {
+	switch t := v.(type) {
+	case nil:
+		return 0
+	case bool:
+		if t {
+			return 2
+		}
+		return 1
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	}
+	return 6
+}
+
+// compare returns a negative number, zero or a positive number as a is less than, equal to or greater than b
+func compare(a, b interface{}) int {
+	if ta, tb := typeOrder(a), typeOrder(b); ta != tb {
+		return ta - tb
+	}
+	switch av := a.(type) {
+	case float64:
+		bv := b.(float64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(av, b.(string))
+	case []interface{}:
+		bv := b.([]interface{})
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if c := compare(av[i], bv[i]); c != 0 {
+				return c
+			}
+		}
+		return len(av) - len(bv)
+	case map[string]interface{}:
+		if reflect.DeepEqual(a, b) {
+			return 0
+		}
+		// objects are only compared for equality
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,126 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenDot
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	// text is the token as written, or the unquoted value of a string
+	text string
+	pos  int
+	// number is the value of a number token
+	number float64
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// twoCharPuncts are the operators made of two characters, checked before single characters
+var twoCharPuncts = []string{"==", "!=", "<=", ">="}
+
+const singleCharPuncts = "|()[],;<>-"
+
+// lex splits a query into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '.':
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: i})
+			i++
+		case isIdentStart(c):
+			start := i
+			for i < len(input) && isIdentChar(input[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: input[start:i], pos: start})
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(input) && (input[i] >= '0' && input[i] <= '9' || input[i] == '.' || input[i] == 'e' || input[i] == 'E' ||
+				(input[i] == '-' || input[i] == '+') && (input[i-1] == 'e' || input[i-1] == 'E')) {
+				i++
+			}
+			n, err := strconv.ParseFloat(input[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at %d", input[start:i], start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[start:i], pos: start, number: n})
+		case c == '"':
+			end, ok := stringEnd(input, i)
+			if !ok {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			s, err := strconv.Unquote(input[i:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: s, pos: i})
+			i = end
+		default:
+			matched := false
+			for _, p := range twoCharPuncts {
+				if strings.HasPrefix(input[i:], p) {
+					tokens = append(tokens, token{kind: tokenPunct, text: p, pos: i})
+					i += len(p)
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			if strings.IndexByte(singleCharPuncts, c) < 0 {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c), pos: i})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// stringEnd returns the index just past the closing quote of the string starting at start
+func stringEnd(s string, start int) (int, bool) {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
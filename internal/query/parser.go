@@ -0,0 +1,289 @@
+package query
+
+import (
+	"fmt"
+)
+
+// parser builds the expression tree of a query by recursive descent, one function per precedence level from
+// lowest to highest as in jq: pipe, or, and, comparison, postfix and primary
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunct(text string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.text == text
+}
+
+func (p *parser) isKeyword(text string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && t.text == text
+}
+
+func (p *parser) expectPunct(text string) error {
+	if !p.isPunct(text) {
+		return unexpected(p.peek(), fmt.Sprintf("expected %q", text))
+	}
+	p.next()
+	return nil
+}
+
+func unexpected(t token, hint string) error {
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of query, %s", hint)
+	}
+	return fmt.Errorf("unexpected %s at %d, %s", t, t.pos, hint)
+}
+
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	for p.isPunct("|") {
+		p.next()
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if call, ok := booleanInputCall(left, right); ok {
+			// most likely meant to be piped only the last operand of and/or or the comparison, which would otherwise
+			// silently fail to match every input
+			return nil, fmt.Errorf("%s at %d is piped a boolean as | binds loosest, use parentheses like "+
+				".a and (.b | %s)", call.name, call.pos, call.name)
+		}
+		left = pipeNode{left, right}
+	}
+	return left, nil
+}
+
+// booleanInputCall returns the call right pipes its input into first if it fails on the boolean left always results in
+func booleanInputCall(left, right node) (callNode, bool) {
+	if !isBoolean(left) {
+		return callNode{}, false
+	}
+	for {
+		switch n := right.(type) {
+		case compareNode:
+			right = n.left
+		case andNode:
+			right = n.left
+		case orNode:
+			right = n.left
+		case callNode:
+			return n, !booleanInputFunctions[n.name]
+		default:
+			return callNode{}, false
+		}
+	}
+}
+
+// isBoolean returns true if n always results in a boolean
+func isBoolean(n node) bool {
+	switch n := n.(type) {
+	case compareNode, andNode, orNode:
+		return true
+	case literalNode:
+		_, ok := n.value.(bool)
+		return ok
+	case pipeNode:
+		return isBoolean(n.right)
+	case callNode:
+		return booleanFunctions[n.name]
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.kind != tokenPunct {
+		return left, nil
+	}
+	switch t.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.next()
+		right, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: t.text, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+// parsePostfix parses a term followed by any number of field accesses and indexes, e.g. .a.b[0]."c"
+func (p *parser) parsePostfix() (node, error) {
+	term, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.peek().kind == tokenDot:
+			dot := p.next()
+			if term, err = p.parseAccess(term, dot); err != nil {
+				return nil, err
+			}
+		case p.isPunct("["):
+			if term, err = p.parseIndex(term); err != nil {
+				return nil, err
+			}
+		default:
+			return term, nil
+		}
+	}
+}
+
+// parseAccess parses what follows a dot after a term: a field name, a quoted field name or an index
+func (p *parser) parseAccess(term node, dot token) (node, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokenIdent && t.pos == dot.pos+1:
+		p.next()
+		return indexNode{term, literalNode{t.text}}, nil
+	case t.kind == tokenString:
+		p.next()
+		return indexNode{term, literalNode{t.text}}, nil
+	case t.kind == tokenPunct && t.text == "[":
+		return p.parseIndex(term)
+	}
+	return nil, unexpected(t, "expected a field name after '.'")
+}
+
+func (p *parser) parseIndex(term node) (node, error) {
+	if err := p.expectPunct("["); err != nil {
+		return nil, err
+	}
+	index, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct("]"); err != nil {
+		return nil, err
+	}
+	return indexNode{term, index}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenDot:
+		// the input itself, or a field or index of it
+		next := p.peek()
+		if next.kind == tokenIdent && next.pos == t.pos+1 || next.kind == tokenString || next.kind == tokenPunct && next.text == "[" {
+			return p.parseAccess(identityNode{}, t)
+		}
+		return identityNode{}, nil
+	case tokenNumber:
+		return literalNode{t.number}, nil
+	case tokenString:
+		return literalNode{t.text}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		case "null":
+			return literalNode{nil}, nil
+		}
+		return p.parseCall(t)
+	case tokenPunct:
+		switch t.text {
+		case "(":
+			inner, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "-":
+			if n := p.peek(); n.kind == tokenNumber {
+				p.next()
+				return literalNode{-n.number}, nil
+			}
+		}
+	}
+	return nil, unexpected(t, "expected a value")
+}
+
+// parseCall parses a function call like length or startswith("/api"), with arguments separated by ';' as in jq
+func (p *parser) parseCall(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at %d", name.text, name.pos)
+	}
+	var args []node
+	if p.isPunct("(") {
+		p.next()
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.isPunct(";") {
+				break
+			}
+			p.next()
+		}
+		if err := p.expectPunct(")"); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) < fn.minArgs || len(args) > fn.maxArgs {
+		return nil, fmt.Errorf("%s at %d takes %s, got %d", name.text, name.pos, fn.arity(), len(args))
+	}
+	return callNode{name: name.text, pos: name.pos, fn: fn, args: args}, nil
+}
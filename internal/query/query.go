@@ -0,0 +1,43 @@
+// Package query evaluates jq-like expressions against JSON values, e.g.
+// `.status >= 500 and (.path | startswith("/api"))`.
+//
+// It supports a subset of jq for filtering: paths like .a.b[0] and ."key", literals, comparisons, and, or, pipes and
+// functions like startswith, endswith, contains, test, has, length, not and select. Every expression has a single
+// result. As in jq, | binds looser than comparisons and and/or, so `.a | length > 3 and .b` reads as
+// `.a | ((length > 3) and .b)`. Piping the boolean result of and/or or a comparison into a function that fails on it,
+// as in `.a and .b | startswith("x")`, is rejected rather than failing to match every input.
+package query
+
+// Query is a parsed jq-like expression
+type Query struct {
+	root node
+}
+
+// Parse parses a jq-like expression, returning an error describing where it is invalid
+func Parse(expr string) (*Query, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, unexpected(t, "expected and, or, | or a comparison")
+	}
+	return &Query{root: root}, nil
+}
+
+// Eval returns the result of the query for input, a value decoded by encoding/json
+func (q *Query) Eval(input interface{}) (interface{}, error) {
+	return q.root.eval(input)
+}
+
+// Matches returns true if the query's result for input is neither false nor null, like jq's select. Inputs the query
+// fails on, e.g. by calling startswith on a number, don't match
+func (q *Query) Matches(input interface{}) bool {
+	v, err := q.Eval(input)
+	return err == nil && truthy(v)
+}
@@ -0,0 +1,128 @@
+package query_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/robinovitch61/kl/internal/query"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid test JSON %q: %v", s, err)
+	}
+	return v
+}
+
+func TestMatches(t *testing.T) {
+	log := `{"status":503,"path":"/api/users","level":"ERROR","tags":["a","b"],"req":{"ms":120,"ok":false},"user":null}`
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`.status >= 500`, true},
+		{`.status < 500`, false},
+		{`.status >= 500 and (.path | startswith("/api"))`, true},
+		{`.status >= 500 and (.path | startswith("/web"))`, false},
+		// | binds looser than comparisons and and/or, as in jq
+		{`.status >= 500 and .path | not`, false},
+		{`.status >= 500 and .path | tostring == "true"`, true},
+		{`.status | . > 500 and . < 600`, true},
+		{`.req | .ms > 100 and (.ok | not)`, true},
+		{`.tags | length == 2 or length == 3`, true},
+		{`.status == 200 or .level == "ERROR"`, true},
+		{`.req.ms > 100`, true},
+		{`.req["ms"] == 120`, true},
+		{`."status" == 503`, true},
+		{`.req.ok`, false},
+		{`.req.ok | not`, true},
+		{`.user == null`, true},
+		{`.missing`, false},
+		{`.missing.deeper == null`, true},
+		{`.tags[0] == "a"`, true},
+		{`.tags[-1] == "b"`, true},
+		{`.tags | length == 2`, true},
+		{`.tags | contains("b")`, true},
+		{`.level | ascii_downcase == "error"`, true},
+		{`.path | test("^/API/"; "i")`, true},
+		{`.path | endswith("users")`, true},
+		{`has("req")`, true},
+		{`.req | has("nope")`, false},
+		{`keys | length == 6`, true},
+		{`.status | tostring | startswith("5")`, true},
+		{`.status | type == "number"`, true},
+		{`select(.status > 500)`, true},
+		{`select(.status > 600)`, false},
+		{`(.status == 200 or .status == 503) and .level != "INFO"`, true},
+		{`.status > -1`, true},
+		{`.path > "/api"`, true},
+		{`.`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := query.Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := q.Matches(decode(t, log)); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches_EvalErrorDoesNotMatch(t *testing.T) {
+	q, err := query.Parse(`.status | startswith("5")`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Matches(decode(t, `{"status":503}`)) {
+		t.Error("expected a number to not match startswith")
+	}
+}
+
+func TestMatches_StringToNumber(t *testing.T) {
+	q, err := query.Parse(`.status | tonumber >= 500`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !q.Matches(decode(t, `{"status":"503"}`)) {
+		t.Error("expected string status to match")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{`.status >=`, "unexpected end of query"},
+		{`.status >= 500 and`, "unexpected end of query"},
+		{`.status 500`, `unexpected "500" at 8`},
+		{`.path | startswith("/api"`, `expected ")"`},
+		{`.path == "unterminated`, "unterminated string at 9"},
+		{`.status # 500`, `unexpected '#' at 8`},
+		{`.path | nope`, `unknown function "nope" at 8`},
+		{`.path | startswith`, "startswith at 8 takes 1 argument, got 0"},
+		{`. status`, `unexpected "status" at 2`},
+		{`.a.`, "expected a field name after '.'"},
+		// | binds loosest, so startswith would be piped the boolean result of and rather than .path
+		{`.status >= 500 and .path | startswith("/api")`, "startswith at 27 is piped a boolean"},
+		{`.status == 1 | length > 2 or . == 3`, "length at 15 is piped a boolean"},
+		{`.path | startswith("/api") | ascii_downcase`, "ascii_downcase at 29 is piped a boolean"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := query.Parse(tt.expr)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %q, want it to contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package stats
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/muesli/reflow/truncate"
+)
+
+// SampleInterval controls how often rates are computed and the overlay is refreshed while visible
+const SampleInterval = time.Second
+
+// maxContainerRows limits the number of containers shown, busiest first
+const maxContainerRows = 10
+
+// Stats collects throughput and backpressure counters for the stats overlay. Recording is cheap so it happens
+// whether or not the overlay is visible. It is shared by pointer so rendering, which can't modify the app model,
+// can record its own duration
+type Stats struct {
+	Visible bool
+
+	// lines received per container since the last sample, and the last sampled rates
+	containerLines map[string]int
+	containerRates map[string]float64
+	// chanDepths are the number of logs waiting in each container's log channel when its logs were last collected
+	chanDepths map[string]int
+	lastSample time.Time
+	// sampleID identifies the current sampling loop, so a loop from before the overlay was last hidden stops
+	sampleID int
+
+	bufferSize       int
+	batchInterval    time.Duration
+	lastBatchSize    int
+	lastBatch        time.Duration
+	maxBatch         time.Duration
+	lastRender       time.Duration
+	maxRender        time.Duration
+	heapBytes        uint64
+	totalMemoryBytes uint64
+}
+
+type SampleMsg struct {
+	ID int
+}
+
+func New() *Stats {
+	return &Stats{
+		containerLines: make(map[string]int),
+		containerRates: make(map[string]float64),
+		chanDepths:     make(map[string]int),
+	}
+}
+
+// Toggle shows or hides the overlay, returning the command that starts sampling when shown
+func (s *Stats) Toggle(now time.Time) tea.Cmd {
+	s.Visible = !s.Visible
+	if !s.Visible {
+		return nil
+	}
+	// start a fresh window so rates reflect current throughput
+	clear(s.containerLines)
+	s.maxBatch, s.maxRender = 0, 0
+	s.lastSample = now
+	s.sampleMemory()
+	s.sampleID++
+	return s.sampleCmd()
+}
+
+// Update samples the counters while the overlay is visible
+func (s *Stats) Update(msg SampleMsg, now time.Time) tea.Cmd {
+	if !s.Visible || msg.ID != s.sampleID {
+		return nil
+	}
+	s.sample(now)
+	return s.sampleCmd()
+}
+
+// RecordLogs records logs collected from a container's log scanner along with the number still waiting in its channel
+func (s *Stats) RecordLogs(containerName string, numLogs, chanDepth int) {
+	s.containerLines[containerName] += numLogs
+	s.chanDepths[containerName] = chanDepth
+}
+
+// RemoveContainer stops showing a container, e.g. when its log scanner stops
+func (s *Stats) RemoveContainer(containerName string) {
+	delete(s.containerLines, containerName)
+	delete(s.containerRates, containerName)
+	delete(s.chanDepths, containerName)
+}
+
+// RecordBufferSize records the number of logs waiting to be added to the logs page
+func (s *Stats) RecordBufferSize(n int) {
+	s.bufferSize = n
+}
+
+// RecordBatch records the time taken to add a batch of logs to the logs page
+func (s *Stats) RecordBatch(numLogs int, d time.Duration) {
+	s.lastBatchSize = numLogs
+	s.lastBatch = d
+	s.maxBatch = max(s.maxBatch, d)
+}
+
+// RecordBatchInterval records the current interval between batches
+func (s *Stats) RecordBatchInterval(d time.Duration) {
+	s.batchInterval = d
+}
+
+// RecordRender records the time taken to render the app
+func (s *Stats) RecordRender(d time.Duration) {
+	s.lastRender = d
+	s.maxRender = max(s.maxRender, d)
+}
+
+func (s *Stats) sample(now time.Time) {
+	elapsed := now.Sub(s.lastSample).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	for name, lines := range s.containerLines {
+		s.containerRates[name] = float64(lines) / elapsed
+	}
+	for name := range s.containerRates {
+		if _, ok := s.containerLines[name]; !ok {
+			s.containerRates[name] = 0
+		}
+	}
+	clear(s.containerLines)
+	s.lastSample = now
+	s.sampleMemory()
+}
+
+func (s *Stats) sampleMemory() {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	s.heapBytes = mem.HeapAlloc
+	s.totalMemoryBytes = mem.Sys
+}
+
+// View renders the overlay with each line fit to width, or an empty string if not visible
+func (s *Stats) View(width int, style lipgloss.Style) string {
+	if !s.Visible {
+		return ""
+	}
+
+	names := make([]string, 0, len(s.containerRates))
+	var totalRate float64
+	for name, rate := range s.containerRates {
+		names = append(names, name)
+		totalRate += rate
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if s.containerRates[names[i]] != s.containerRates[names[j]] {
+			return s.containerRates[names[i]] > s.containerRates[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	lines := []string{
+		fmt.Sprintf("Stats (every %s)", SampleInterval),
+		fmt.Sprintf("  %.1f lines/s across %d containers", totalRate, len(names)),
+		fmt.Sprintf("  buffered logs: %d", s.bufferSize),
+		fmt.Sprintf("  last batch: %d logs in %s (max %s), every %s", s.lastBatchSize, formatDuration(s.lastBatch), formatDuration(s.maxBatch), formatDuration(s.batchInterval)),
+		fmt.Sprintf("  render: %s (max %s)", formatDuration(s.lastRender), formatDuration(s.maxRender)),
+		fmt.Sprintf("  memory: %s heap, %s total", formatBytes(s.heapBytes), formatBytes(s.totalMemoryBytes)),
+	}
+	for i, name := range names {
+		if i == maxContainerRows {
+			lines = append(lines, fmt.Sprintf("  ...and %d more", len(names)-maxContainerRows))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("  %8.1f lines/s  chan %d  %s", s.containerRates[name], s.chanDepths[name], name))
+	}
+	for i := range lines {
+		lines[i] = truncate.String(lines[i], uint(max(width, 0)))
+		lines[i] = style.Render(lines[i] + strings.Repeat(" ", max(width-lipgloss.Width(lines[i]), 0)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Stats) sampleCmd() tea.Cmd {
+	id := s.sampleID
+	return tea.Tick(SampleInterval, func(t time.Time) tea.Msg { return SampleMsg{ID: id} })
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(10 * time.Microsecond).String()
+}
+
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
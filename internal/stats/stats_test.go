@@ -0,0 +1,82 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"charm.land/lipgloss/v2"
+)
+
+func TestStats_View(t *testing.T) {
+	s := New()
+	if s.View(80, lipgloss.NewStyle()) != "" {
+		t.Fatal("expected no view when hidden")
+	}
+
+	start := time.Now()
+	if s.Toggle(start) == nil {
+		t.Fatal("expected sampling to start when shown")
+	}
+	s.RecordLogs("ns/pod/busy", 300, 1)
+	s.RecordLogs("ns/pod/quiet", 20, 0)
+	s.RecordLogs("ns/pod/busy", 300, 0)
+	s.RecordBufferSize(42)
+	s.RecordBatch(620, 3*time.Millisecond)
+	s.RecordBatchInterval(100 * time.Millisecond)
+	s.RecordRender(2 * time.Millisecond)
+	if s.Update(SampleMsg{ID: s.sampleID}, start.Add(2*time.Second)) == nil {
+		t.Fatal("expected sampling to continue while shown")
+	}
+
+	lines := strings.Split(s.View(80, lipgloss.NewStyle()), "\n")
+	for _, line := range lines {
+		if lipgloss.Width(line) != 80 {
+			t.Errorf("expected line fit to width 80, got width %d: %q", lipgloss.Width(line), line)
+		}
+	}
+	view := strings.Join(lines, "\n")
+	for _, expected := range []string{
+		"310.0 lines/s across 2 containers",
+		"buffered logs: 42",
+		"last batch: 620 logs in 3ms (max 3ms), every 100ms",
+		"render: 2ms",
+		"300.0 lines/s  chan 0  ns/pod/busy",
+		"10.0 lines/s  chan 0  ns/pod/quiet",
+	} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("expected view to contain %q, got\n%s", expected, view)
+		}
+	}
+	if strings.Index(view, "ns/pod/busy") > strings.Index(view, "ns/pod/quiet") {
+		t.Errorf("expected busiest container first, got\n%s", view)
+	}
+
+	// containers without new logs drop to zero until removed
+	s.Update(SampleMsg{ID: s.sampleID}, start.Add(3*time.Second))
+	if view := s.View(80, lipgloss.NewStyle()); !strings.Contains(view, "0.0 lines/s across 2 containers") {
+		t.Errorf("expected rates to drop to zero, got\n%s", view)
+	}
+	s.RemoveContainer("ns/pod/quiet")
+	if view := s.View(80, lipgloss.NewStyle()); strings.Contains(view, "ns/pod/quiet") {
+		t.Errorf("expected removed container not to be shown, got\n%s", view)
+	}
+}
+
+func TestStats_StaleSamplingStops(t *testing.T) {
+	s := New()
+	now := time.Now()
+	s.Toggle(now)
+	staleID := s.sampleID
+	s.Toggle(now)
+	if s.Update(SampleMsg{ID: staleID}, now) != nil {
+		t.Error("expected sampling to stop when hidden")
+	}
+	s.Toggle(now)
+	if s.Update(SampleMsg{ID: staleID}, now) != nil {
+		t.Error("expected sampling loop from before hiding to stop")
+	}
+	if s.Update(SampleMsg{ID: s.sampleID}, now) == nil {
+		t.Error("expected current sampling loop to continue")
+	}
+}
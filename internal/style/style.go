@@ -28,6 +28,9 @@ type Theme struct {
 	// nil or empty disables container coloring.
 	ContainerColors []lipgloss.Style
 
+	// pinned highlight rule styles, assigned to rules in order and reused once exhausted
+	HighlightColors []lipgloss.Style
+
 	// JSON syntax colorization
 	JSONKey    lipgloss.Style
 	JSONString lipgloss.Style
@@ -35,6 +38,12 @@ type Theme struct {
 	JSONBool   lipgloss.Style
 	JSONNull   lipgloss.Style
 
+	// log level badges
+	LevelDebug lipgloss.Style
+	LevelInfo  lipgloss.Style
+	LevelWarn  lipgloss.Style
+	LevelError lipgloss.Style
+
 	// kl-specific styles
 	TopBar              lipgloss.Style
 	TopBarAccent        lipgloss.Style // e.g. [PAUSED]
@@ -63,6 +72,15 @@ func (t Theme) ContainerColorStyle(name string) lipgloss.Style {
 	return t.ContainerColors[hashValue%int64(len(t.ContainerColors))]
 }
 
+// HighlightStyle returns the style of the highlight rule at index i. Returns an empty style if the theme has no
+// highlight colors.
+func (t Theme) HighlightStyle(i int) lipgloss.Style {
+	if len(t.HighlightColors) == 0 {
+		return lipgloss.NewStyle()
+	}
+	return t.HighlightColors[i%len(t.HighlightColors)]
+}
+
 // DefaultTheme returns the default theme using only the most accessible ANSI colors and reverse video.
 // Maximum compatibility across terminal themes (light, dark, Solarized, etc.).
 // See https://blog.xoria.org/terminal-colors/ and https://jvns.ca/blog/2024/10/01/terminal-colours/
@@ -89,12 +107,26 @@ func DefaultTheme() Theme {
 		MatchFocusedIfSelected: lipgloss.NewStyle().Reverse(true).Foreground(lipgloss.Yellow),
 		MatchUnfocused:         lipgloss.NewStyle().Reverse(true).Foreground(lipgloss.Cyan),
 
+		// yellow and cyan are already used for filter matches
+		HighlightColors: []lipgloss.Style{
+			lipgloss.NewStyle().Reverse(true).Foreground(lipgloss.Magenta),
+			lipgloss.NewStyle().Reverse(true).Foreground(lipgloss.Green),
+			lipgloss.NewStyle().Reverse(true).Foreground(lipgloss.Red),
+			lipgloss.NewStyle().Reverse(true).Foreground(lipgloss.BrightBlue),
+			lipgloss.NewStyle().Reverse(true).Foreground(lipgloss.BrightMagenta),
+		},
+
 		JSONKey:    lipgloss.NewStyle().Foreground(lipgloss.Green),
 		JSONString: lipgloss.NewStyle(),
 		JSONNumber: lipgloss.NewStyle().Foreground(lipgloss.Yellow),
 		JSONBool:   lipgloss.NewStyle().Foreground(lipgloss.Magenta),
 		JSONNull:   lipgloss.NewStyle().Foreground(lipgloss.Red),
 
+		LevelDebug: lipgloss.NewStyle().Faint(true),
+		LevelInfo:  lipgloss.NewStyle().Foreground(lipgloss.Cyan),
+		LevelWarn:  lipgloss.NewStyle().Foreground(lipgloss.Yellow),
+		LevelError: lipgloss.NewStyle().Foreground(lipgloss.Red).Reverse(true),
+
 		TopBar:              lipgloss.NewStyle().Foreground(lipgloss.BrightCyan).Reverse(true),
 		TopBarAccent:        lipgloss.NewStyle().Foreground(lipgloss.BrightRed),
 		FilterPrefixFocused: lipgloss.NewStyle().Foreground(lipgloss.Cyan),
@@ -134,12 +166,25 @@ func ClassicTheme() Theme {
 		MatchFocusedIfSelected: lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#ffffff")),
 		MatchUnfocused:         lipgloss.NewStyle().Foreground(lipgloss.Color("#141414")).Background(lipgloss.Color("#cbcbcb")),
 
+		HighlightColors: []lipgloss.Style{
+			lipgloss.NewStyle().Background(lipgloss.Color("#B48EAD")).Foreground(lipgloss.Color("#000000")),
+			lipgloss.NewStyle().Background(lipgloss.Color("#A3BE8C")).Foreground(lipgloss.Color("#000000")),
+			lipgloss.NewStyle().Background(lipgloss.Color("#D08770")).Foreground(lipgloss.Color("#000000")),
+			lipgloss.NewStyle().Background(lipgloss.Color("#81A1C1")).Foreground(lipgloss.Color("#000000")),
+			lipgloss.NewStyle().Background(lipgloss.Color("#EBCB8B")).Foreground(lipgloss.Color("#000000")),
+		},
+
 		JSONKey:    lipgloss.NewStyle().Foreground(lipgloss.Color("#88C0D0")),
 		JSONString: lipgloss.NewStyle().Foreground(lipgloss.Color("#A3BE8C")),
 		JSONNumber: lipgloss.NewStyle().Foreground(lipgloss.Color("#B48EAD")),
 		JSONBool:   lipgloss.NewStyle().Foreground(lipgloss.Color("#D08770")),
 		JSONNull:   lipgloss.NewStyle().Foreground(lipgloss.Color("#BF616A")),
 
+		LevelDebug: lipgloss.NewStyle().Foreground(lipgloss.Color("#7B8394")),
+		LevelInfo:  lipgloss.NewStyle().Foreground(lipgloss.Color("#88C0D0")),
+		LevelWarn:  lipgloss.NewStyle().Background(lipgloss.Color("#EBCB8B")).Foreground(lipgloss.Color("#000000")),
+		LevelError: lipgloss.NewStyle().Background(lipgloss.Color("#BF616A")).Foreground(lipgloss.Color("#000000")),
+
 		TopBar:              lipgloss.NewStyle().Background(lilac).Foreground(lipgloss.Color("#000000")),
 		TopBarAccent:        lipgloss.NewStyle().Foreground(lipgloss.Color("#000000")).Background(lipgloss.Color("#ffffff")),
 		FilterPrefixFocused: lipgloss.NewStyle().Background(lipgloss.Color("6")).Foreground(lipgloss.Color("#000000")),
@@ -166,12 +211,24 @@ func NoColorTheme() Theme {
 		MatchFocusedIfSelected: lipgloss.NewStyle().Reverse(true),
 		MatchUnfocused:         lipgloss.NewStyle().Reverse(true),
 
+		// reverse video is already used for filter matches
+		HighlightColors: []lipgloss.Style{
+			lipgloss.NewStyle().Underline(true),
+			lipgloss.NewStyle().Bold(true),
+			lipgloss.NewStyle().Italic(true),
+		},
+
 		JSONKey:    noStyle,
 		JSONString: noStyle,
 		JSONNumber: noStyle,
 		JSONBool:   noStyle,
 		JSONNull:   noStyle,
 
+		LevelDebug: noStyle,
+		LevelInfo:  noStyle,
+		LevelWarn:  noStyle,
+		LevelError: noStyle,
+
 		TopBar:              noStyle,
 		TopBarAccent:        noStyle,
 		FilterPrefixFocused: noStyle,
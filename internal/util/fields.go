@@ -0,0 +1,83 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// ExtractFields returns the values of fields in JSON or logfmt content, with dots separating the keys of nested JSON
+// objects, e.g. "http.status". Strings are unquoted and other JSON values are compact JSON. Missing fields have
+// empty values. Returns false if the content isn't JSON or logfmt or has none of the fields.
+func ExtractFields(input string, fields []string) ([]string, bool) {
+	if strings.HasPrefix(strings.TrimSpace(input), "{") {
+		return extractJSONFields(input, fields)
+	}
+	return extractLogfmtFields(input, fields)
+}
+
+func extractJSONFields(input string, fields []string) ([]string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(input)))
+	// keep numbers as written
+	decoder.UseNumber()
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, false
+	}
+
+	values := make([]string, len(fields))
+	found := false
+	for i, field := range fields {
+		value, ok := lookupJSONField(obj, field)
+		if !ok {
+			continue
+		}
+		found = true
+		if s, isString := value.(string); isString {
+			values[i] = s
+		} else if b, err := json.Marshal(value); err == nil {
+			values[i] = string(b)
+		}
+	}
+	return values, found
+}
+
+// lookupJSONField returns the value at a dot-separated path of keys. A key containing dots is matched as is first
+func lookupJSONField(obj map[string]interface{}, field string) (interface{}, bool) {
+	if value, ok := obj[field]; ok {
+		return value, true
+	}
+	key, rest, nested := strings.Cut(field, ".")
+	if !nested {
+		return nil, false
+	}
+	child, ok := obj[key].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	return lookupJSONField(child, rest)
+}
+
+func extractLogfmtFields(input string, fields []string) ([]string, bool) {
+	pairs, ok := parseLogfmt(input)
+	if !ok {
+		return nil, false
+	}
+	values := make([]string, len(fields))
+	found := false
+	for i, field := range fields {
+		for _, pair := range pairs {
+			if pair.key != field {
+				continue
+			}
+			found = true
+			values[i] = pair.value
+			if unquoted, err := strconv.Unquote(pair.value); err == nil {
+				values[i] = unquoted
+			}
+			break
+		}
+	}
+	return values, found
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractFields(t *testing.T) {
+	fields := []string{"level", "msg", "http.status", "duration_ms"}
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		ok       bool
+	}{
+		{
+			name:     "json",
+			input:    `{"level":"info","msg":"done","http":{"status":200},"duration_ms":3.50}`,
+			expected: []string{"info", "done", "200", "3.50"},
+			ok:       true,
+		},
+		{
+			name:     "json dotted key",
+			input:    `{"http.status":404}`,
+			expected: []string{"", "", "404", ""},
+			ok:       true,
+		},
+		{
+			name:     "json object value",
+			input:    `{"msg":{"b":1,"a":[true,null]}}`,
+			expected: []string{"", `{"a":[true,null],"b":1}`, "", ""},
+			ok:       true,
+		},
+		{
+			name:     "logfmt",
+			input:    `level=warn msg="slow request" duration_ms=1200`,
+			expected: []string{"warn", "slow request", "", "1200"},
+			ok:       true,
+		},
+		{name: "json without fields", input: `{"other":1}`},
+		{name: "invalid json", input: `{"level":`},
+		{name: "plain text", input: "level info"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ExtractFields(tt.input, fields)
+			if ok != tt.ok {
+				t.Fatalf("expected ok=%v, got %v", tt.ok, ok)
+			}
+			if ok && strings.Join(got, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -36,8 +36,12 @@ func PrettyPrintJSON(input string, colorize func(string) string) []string {
 		pretty = colorize(pretty)
 	}
 
-	lines := strings.Split(pretty, "\n")
+	return splitEscapedLines(strings.Split(pretty, "\n"))
+}
 
+// splitEscapedLines splits lines at escaped newlines and replaces escaped tabs with spaces, so multi-line string
+// values read as multiple lines
+func splitEscapedLines(lines []string) []string {
 	var result []string
 	for i := range lines {
 		if strings.Contains(lines[i], "\\n") || strings.Contains(lines[i], "\\t") {
@@ -190,6 +194,16 @@ func ColorizeJSON(input string, colors JSONColorStyles) string {
 	return buf.String()
 }
 
+// JSONColorizer returns a function that applies ColorizeJSON with colors, or nil if colors is nil.
+func JSONColorizer(colors *JSONColorStyles) func(string) string {
+	if colors == nil {
+		return nil
+	}
+	return func(s string) string {
+		return ColorizeJSON(s, *colors)
+	}
+}
+
 // findStringEnd returns the index just past the closing quote of a JSON string
 // starting at position start (which must point to the opening '"').
 func findStringEnd(s string, start int) int {
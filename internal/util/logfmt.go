@@ -0,0 +1,159 @@
+package util
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+)
+
+// minLogfmtPairs is the fewest key=value pairs for content to be treated as logfmt, so text that happens to
+// contain a single '=' isn't
+const minLogfmtPairs = 2
+
+// logfmtPair is a key and its value as written, including any quotes, starting at start in the input
+type logfmtPair struct {
+	start      int
+	key, value string
+}
+
+// parseLogfmt splits logfmt like `level=info msg="request done" dur=3ms` into its key=value pairs. Returns false if
+// the input isn't made up of only key=value pairs separated by spaces
+func parseLogfmt(input string) ([]logfmtPair, bool) {
+	var pairs []logfmtPair
+	i := 0
+	for i < len(input) {
+		if input[i] == ' ' {
+			i++
+			continue
+		}
+
+		keyStart := i
+		for i < len(input) && input[i] != '=' && input[i] != ' ' && input[i] != '"' {
+			i++
+		}
+		if i == keyStart || i >= len(input) || input[i] != '=' {
+			return nil, false
+		}
+		key := input[keyStart:i]
+		i++
+
+		valueStart := i
+		if i < len(input) && input[i] == '"' {
+			end, ok := findQuoteEnd(input, i)
+			if !ok {
+				return nil, false
+			}
+			i = end
+		} else {
+			for i < len(input) && input[i] != ' ' {
+				if input[i] == '"' {
+					return nil, false
+				}
+				i++
+			}
+		}
+		if i < len(input) && input[i] != ' ' {
+			return nil, false
+		}
+		pairs = append(pairs, logfmtPair{start: keyStart, key: key, value: input[valueStart:i]})
+	}
+	if len(pairs) < minLogfmtPairs {
+		return nil, false
+	}
+	return pairs, true
+}
+
+// findQuoteEnd returns the index just past the closing quote of a quoted value starting at position start, or
+// false if the quote is never closed
+func findQuoteEnd(s string, start int) (int, bool) {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// renderLogfmtPair renders a pair as key=value, styling the key and the value by its type
+func renderLogfmtPair(pair logfmtPair, colors JSONColorStyles) string {
+	return colors.Key.Render(pair.key) + "=" + logfmtValueStyle(pair.value, colors).Render(pair.value)
+}
+
+func logfmtValueStyle(value string, colors JSONColorStyles) lipgloss.Style {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		return colors.String
+	case value == "true" || value == "false":
+		return colors.Bool
+	case value == "null" || value == "nil":
+		return colors.Null
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return colors.Number
+	}
+	if _, err := time.ParseDuration(value); err == nil {
+		return colors.Number
+	}
+	return colors.String
+}
+
+// ColorizeLogfmt applies ANSI color codes to the keys and values of logfmt, styling values like JSON values of the
+// same type. Returns the input unchanged if it's not logfmt.
+func ColorizeLogfmt(input string, colors JSONColorStyles) string {
+	pairs, ok := parseLogfmt(input)
+	if !ok {
+		return input
+	}
+	var buf strings.Builder
+	buf.Grow(len(input) * 2) // rough estimate with ANSI codes
+	end := 0
+	for _, pair := range pairs {
+		// keep the spacing between pairs as is
+		buf.WriteString(input[end:pair.start])
+		buf.WriteString(renderLogfmtPair(pair, colors))
+		end = pair.start + len(pair.key) + 1 + len(pair.value)
+	}
+	buf.WriteString(input[end:])
+	return buf.String()
+}
+
+// PrettyPrintLogfmt puts each key=value pair of logfmt on its own line, colorized with colors if non-nil. Escaped
+// newlines in quoted values are split across lines like in pretty-printed JSON. Returns the input as-is if it's
+// not logfmt.
+func PrettyPrintLogfmt(input string, colors *JSONColorStyles) []string {
+	pairs, ok := parseLogfmt(input)
+	if !ok {
+		return []string{input}
+	}
+	lines := make([]string, len(pairs))
+	for i, pair := range pairs {
+		if colors != nil {
+			lines[i] = renderLogfmtPair(pair, *colors)
+		} else {
+			lines[i] = pair.key + "=" + pair.value
+		}
+	}
+	return splitEscapedLines(lines)
+}
+
+// ColorizeStructured colorizes JSON or logfmt content. Returns other content unchanged.
+func ColorizeStructured(input string, colors JSONColorStyles) string {
+	if trimmed := strings.TrimLeft(input, " \t\r\n"); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return ColorizeJSON(input, colors)
+	}
+	return ColorizeLogfmt(input, colors)
+}
+
+// PrettyPrintStructured pretty-prints JSON or logfmt content, colorized with colors if non-nil. Returns other
+// content as-is.
+func PrettyPrintStructured(input string, colors *JSONColorStyles) []string {
+	if lines := PrettyPrintJSON(input, JSONColorizer(colors)); len(lines) > 1 {
+		return lines
+	}
+	return PrettyPrintLogfmt(input, colors)
+}
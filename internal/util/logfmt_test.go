@@ -0,0 +1,98 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColorizeLogfmt_NonLogfmt(t *testing.T) {
+	colors := testColors()
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"plain text", "just plain text"},
+		{"empty string", ""},
+		{"single pair", "retries=3"},
+		{"text with pairs", "request done status=200 dur=3ms"},
+		{"unterminated quote", `level=info msg="request done`},
+		{"quote in unquoted value", `level=info msg=a"b`},
+		{"text after quoted value", `level=info msg="a"b`},
+		{"empty key", "level=info =3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ColorizeLogfmt(tt.input, colors); got != tt.input {
+				t.Errorf("expected input unchanged, got %q", got)
+			}
+		})
+	}
+}
+
+func TestColorizeLogfmt_AppliesColors(t *testing.T) {
+	colors := testColors()
+	input := `level=info  msg="request \"done\"" dur=3ms status=200 cached=false err=nil user=`
+	got := ColorizeLogfmt(input, colors)
+
+	// with no-color styles, output should match input, including the spacing between pairs
+	if plain := ColorizeLogfmt(input, JSONColorStyles{}); plain != input {
+		t.Errorf("expected content to match input without colors, got %q", plain)
+	}
+	for _, expected := range []string{
+		colors.Key.Render("level") + "=" + colors.String.Render("info"),
+		colors.String.Render(`"request \"done\""`),
+		colors.Number.Render("3ms"),
+		colors.Number.Render("200"),
+		colors.Bool.Render("false"),
+		colors.Null.Render("nil"),
+	} {
+		if !strings.Contains(got, expected) {
+			t.Errorf("expected %q in %q", expected, got)
+		}
+	}
+}
+
+func TestPrettyPrintLogfmt(t *testing.T) {
+	t.Run("one pair per line", func(t *testing.T) {
+		got := PrettyPrintLogfmt(`level=info msg="request done" dur=3ms`, nil)
+		expected := []string{"level=info", `msg="request done"`, "dur=3ms"}
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("escaped newlines split lines", func(t *testing.T) {
+		got := PrettyPrintLogfmt(`level=error stack="a\nb"`, nil)
+		expected := []string{"level=error", `stack="a`, `b"`}
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("expected %q, got %q", expected, got)
+		}
+	})
+
+	t.Run("not logfmt", func(t *testing.T) {
+		got := PrettyPrintLogfmt("just plain text", nil)
+		if len(got) != 1 || got[0] != "just plain text" {
+			t.Errorf("expected unchanged plain text, got %q", got)
+		}
+	})
+
+	t.Run("colorized", func(t *testing.T) {
+		colors := testColors()
+		got := PrettyPrintLogfmt("level=info dur=3ms", &colors)
+		if len(got) != 2 || got[1] != colors.Key.Render("dur")+"="+colors.Number.Render("3ms") {
+			t.Errorf("expected colorized pairs, got %q", got)
+		}
+	})
+}
+
+func TestPrettyPrintStructured(t *testing.T) {
+	if got := PrettyPrintStructured(`{"a":1}`, nil); len(got) != 3 {
+		t.Errorf("expected JSON to be pretty-printed, got %q", got)
+	}
+	if got := PrettyPrintStructured("a=1 b=2", nil); len(got) != 2 {
+		t.Errorf("expected logfmt to be pretty-printed, got %q", got)
+	}
+	if got := PrettyPrintStructured("plain text", nil); len(got) != 1 {
+		t.Errorf("expected plain text as is, got %q", got)
+	}
+}